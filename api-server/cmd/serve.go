@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"os/signal"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -15,17 +18,37 @@ import (
 	"gopkg.in/yaml.v3"
 
 	"github.com/bentoml/yatai/api-server/config"
+	"github.com/bentoml/yatai/api-server/controllers/controllersv1"
 	"github.com/bentoml/yatai/api-server/routes"
 	"github.com/bentoml/yatai/api-server/services"
 	"github.com/bentoml/yatai/common/command"
+	"github.com/bentoml/yatai/common/metrics"
 	"github.com/bentoml/yatai/common/sync/errsgroup"
+	"github.com/bentoml/yatai/common/tlsreloader"
 )
 
-func addCron(ctx context.Context) {
+const tlsReloadInterval = 10 * time.Second
+
+// runCron blocks until ctx is done and the running jobs returned.
+func runCron(ctx context.Context) {
 	c := cron.New()
 	logger := logrus.New().WithField("cron", "sync env")
 
-	err := c.AddFunc("@every 1m", func() {
+	// the jobs hold the read lock while running, so taking the write lock waits for them on shutdown
+	var running sync.RWMutex
+	job := func(f func()) func() {
+		return func() {
+			running.RLock()
+			defer running.RUnlock()
+			if ctx.Err() != nil {
+				return
+			}
+			f()
+		}
+	}
+
+	// slow reconciliation, deployment statuses are synced by the status watcher on kube events
+	err := c.AddFunc(fmt.Sprintf("@every %s", services.DeploymentStatusReconcileInterval), job(func() {
 		ctx, cancel := context.WithTimeout(ctx, time.Minute*5)
 		defer cancel()
 		logger.Info("listing unsynced deployments")
@@ -50,8 +73,15 @@ func addCron(ctx context.Context) {
 		for _, deployment := range deployments {
 			deployment := deployment
 			eg.Go(func() error {
+				start := time.Now()
 				_, err := services.DeploymentService.SyncStatus(ctx, deployment)
-				return err
+				metrics.ObserveDeploymentSync(metrics.DeploymentSyncSourceCron, start, err)
+				if err != nil {
+					return err
+				}
+				// the revisions which are too new are left to the status watcher
+				_, err = services.DeploymentAutoRollbackService.Check(ctx, deployment.ID)
+				return errors.Wrap(err, "auto rollback")
 			})
 		}
 
@@ -61,13 +91,71 @@ func addCron(ctx context.Context) {
 		if err != nil {
 			logger.Errorf("sync deployments: %s", err.Error())
 		}
-	})
+	}))
+
+	if err != nil {
+		logger.Errorf("cron add func failed: %s", err.Error())
+	}
+
+	err = c.AddFunc("@every 1m", job(func() {
+		ctx, cancel := context.WithTimeout(ctx, time.Minute*5)
+		defer cancel()
+		err := services.DeploymentFollowService.SyncAll(ctx)
+		if err != nil {
+			logger.Errorf("auto deploy following deployments: %s", err.Error())
+		}
+	}))
+
+	if err != nil {
+		logger.Errorf("cron add func failed: %s", err.Error())
+	}
+
+	err = c.AddFunc("@every 1m", job(func() {
+		ctx, cancel := context.WithTimeout(ctx, time.Minute*5)
+		defer cancel()
+		err := services.CanaryRolloutService.ProgressAll(ctx)
+		if err != nil {
+			logger.Errorf("progress canary rollouts: %s", err.Error())
+		}
+	}))
+
+	if err != nil {
+		logger.Errorf("cron add func failed: %s", err.Error())
+	}
+
+	err = c.AddFunc("@every 1h", job(func() {
+		ctx, cancel := context.WithTimeout(ctx, time.Minute*30)
+		defer cancel()
+		logger.Info("pruning repositories by retention policies")
+		err := services.RetentionService.PruneAll(ctx)
+		if err != nil {
+			logger.Errorf("prune repositories: %s", err.Error())
+		}
+		logger.Info("pruned repositories by retention policies")
+	}))
+
+	if err != nil {
+		logger.Errorf("cron add func failed: %s", err.Error())
+	}
+
+	err = c.AddFunc("@every 1h", job(func() {
+		ctx, cancel := context.WithTimeout(ctx, time.Minute*5)
+		defer cancel()
+		err := services.ApiTokenUsageService.PruneBefore(ctx, time.Now().Add(-services.ApiTokenUsageRetention))
+		if err != nil {
+			logger.Errorf("prune api token usages: %s", err.Error())
+		}
+	}))
 
 	if err != nil {
 		logger.Errorf("cron add func failed: %s", err.Error())
 	}
 
 	c.Start()
+	<-ctx.Done()
+	c.Stop()
+	running.Lock()
+	running.Unlock() // nolint: staticcheck
 }
 
 type ServeOption struct {
@@ -118,6 +206,11 @@ func (opt *ServeOption) Run(ctx context.Context, args []string) error {
 		return errors.Wrap(err, "migrate up db")
 	}
 
+	err = services.ApiTokenService.HashLegacyTokens(ctx)
+	if err != nil {
+		return errors.Wrap(err, "hash legacy api tokens")
+	}
+
 	if !config.YataiConfig.IsSaaS {
 		err = initSelfHost(ctx)
 		if err != nil {
@@ -125,7 +218,24 @@ func (opt *ServeOption) Run(ctx context.Context, args []string) error {
 		}
 	}
 
-	addCron(ctx)
+	// background jobs run on the leader replica only, every replica serves the api
+	backgroundCtx, stopBackgroundJobs := context.WithCancel(ctx)
+	defer stopBackgroundJobs()
+	backgroundJobsStopped := services.LeaderElectionService.Run(backgroundCtx, func(ctx context.Context) {
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			services.DeploymentStatusWatcherService.Run(ctx)
+		}()
+		runCron(ctx)
+		wg.Wait()
+	})
+
+	// unlike the background jobs, every replica flushes the api token usages it recorded
+	go services.ApiTokenUsageService.Run(backgroundCtx)
+	// the auto deploy triggers are queued by the api handlers of every replica as well
+	go services.DeploymentFollowService.Run(backgroundCtx)
 
 	// nolint: contextcheck
 	router, err := routes.NewRouter()
@@ -138,14 +248,98 @@ func (opt *ServeOption) Run(ctx context.Context, args []string) error {
 		readHeaderTimeout = time.Duration(config.YataiConfig.Server.ReadHeaderTimeout) * time.Second
 	}
 
-	logrus.Infof("listening on 0.0.0.0:%d", config.YataiConfig.Server.Port)
+	scheme := "http"
+	if config.YataiConfig.Server.EnableHTTPS {
+		scheme = "https"
+	}
+	logrus.Infof("listening on %s://0.0.0.0:%d", scheme, config.YataiConfig.Server.Port)
 
 	srv := &http.Server{
 		Addr:              fmt.Sprintf(":%d", config.YataiConfig.Server.Port),
 		Handler:           router,
 		ReadHeaderTimeout: readHeaderTimeout,
 	}
-	return srv.ListenAndServe()
+
+	if config.YataiConfig.Server.EnableHTTPS {
+		var minVersion uint16
+		minVersion, err = tlsreloader.ParseVersion(config.YataiConfig.Server.TLS.MinVersion)
+		if err != nil {
+			return err
+		}
+		var reloader *tlsreloader.Reloader
+		reloader, err = tlsreloader.New(config.YataiConfig.Server.TLS.CertFile, config.YataiConfig.Server.TLS.KeyFile, config.YataiConfig.Server.TLS.ClientCAFile)
+		if err != nil {
+			return errors.Wrap(err, "load tls certificate")
+		}
+		go reloader.Run(backgroundCtx, tlsReloadInterval)
+		srv.TLSConfig = reloader.TLSConfig(minVersion)
+	}
+
+	signalCtx, stopSignal := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer stopSignal()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		if srv.TLSConfig != nil {
+			// the certificate is served by the tls config
+			serveErr <- srv.ListenAndServeTLS("", "")
+			return
+		}
+		serveErr <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err = <-serveErr:
+		return err
+	case <-signalCtx.Done():
+	}
+	// a second signal kills the process right away
+	stopSignal()
+
+	shutdownTimeout := 25 * time.Second
+	if config.YataiConfig.Server.ShutdownTimeout > 0 {
+		shutdownTimeout = time.Duration(config.YataiConfig.Server.ShutdownTimeout) * time.Second
+	}
+	logrus.Infof("shutting down, draining for up to %s", shutdownTimeout)
+	// nolint: contextcheck
+	return shutdown(srv, shutdownTimeout, stopBackgroundJobs, backgroundJobsStopped)
+}
+
+// shutdown stops accepting new connections, closes the websockets so the terminal records
+// get flushed, then stops the background jobs, all within the drain timeout.
+func shutdown(srv *http.Server, timeout time.Duration, stopBackgroundJobs context.CancelFunc, backgroundJobsStopped <-chan struct{}) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		// the http server does not wait for the hijacked websocket connections
+		if err := controllersv1.CloseWebsocketConnections(ctx); err != nil {
+			logrus.Errorf("close websocket connections: %s", err.Error())
+		}
+	}()
+	err := srv.Shutdown(ctx)
+	if err != nil {
+		logrus.Errorf("shutdown http server: %s", err.Error())
+	}
+	wg.Wait()
+
+	// the requests are drained, so the last api token usages can be written
+	if err := services.ApiTokenUsageService.Flush(ctx); err != nil {
+		logrus.Error(err.Error())
+	}
+
+	stopBackgroundJobs()
+	select {
+	case <-backgroundJobsStopped:
+	case <-ctx.Done():
+		logrus.Error("timed out waiting for the background jobs to stop")
+	}
+
+	logrus.Info("shut down")
+	return nil
 }
 
 func getServeCmd() *cobra.Command {
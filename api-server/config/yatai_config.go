@@ -3,19 +3,34 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/pkg/errors"
 
+	"github.com/bentoml/yatai-schemas/modelschemas"
 	"github.com/bentoml/yatai/common/consts"
 )
 
+type YataiServerTLSConfigYaml struct {
+	CertFile   string `yaml:"cert_file"`
+	KeyFile    string `yaml:"key_file"`
+	MinVersion string `yaml:"min_version"`
+	// the client certificates are verified against it when they are given
+	ClientCAFile string `yaml:"client_ca_file"`
+	// machine clients such as the yatai components must present a verified client certificate
+	RequireClientCert bool `yaml:"require_client_cert"`
+}
+
 type YataiServerConfigYaml struct {
-	EnableHTTPS          bool   `yaml:"enable_https"`
-	Port                 uint   `yaml:"port"`
-	SessionSecretKey     string `yaml:"session_secret_key"`
-	MigrationDir         string `yaml:"migration_dir"`
-	ReadHeaderTimeout    int    `yaml:"read_header_timeout"`
-	TransmissionStrategy string `yaml:"transmission_strategy"`
+	EnableHTTPS          bool                     `yaml:"enable_https"`
+	TLS                  YataiServerTLSConfigYaml `yaml:"tls"`
+	Port                 uint                     `yaml:"port"`
+	SessionSecretKey     string                   `yaml:"session_secret_key"`
+	MigrationDir         string                   `yaml:"migration_dir"`
+	ReadHeaderTimeout    int                      `yaml:"read_header_timeout"`
+	ShutdownTimeout      int                      `yaml:"shutdown_timeout"`
+	TrustedProxies       []string                 `yaml:"trusted_proxies"`
+	TransmissionStrategy string                   `yaml:"transmission_strategy"`
 }
 
 type YataiPostgresqlConfigYaml struct {
@@ -49,15 +64,69 @@ type YataiDockerImageBuilderConfigYaml struct {
 	Privileged bool `yaml:"privileged"`
 }
 
+// YataiGroupMappingYaml grants the members of an external group a role in an organization
+type YataiGroupMappingYaml struct {
+	Group        string                  `yaml:"group"`
+	Organization string                  `yaml:"organization"`
+	Role         modelschemas.MemberRole `yaml:"role"`
+}
+
+type YataiOIDCConfigYaml struct {
+	Issuer       string `yaml:"issuer"`
+	ClientId     string `yaml:"client_id"`
+	ClientSecret string `yaml:"client_secret"`
+	// the callback url registered in the idp, e.g. https://yatai.example.com/oauth/oidc/callback
+	RedirectURL string   `yaml:"redirect_url"`
+	Scopes      []string `yaml:"scopes"`
+	// the id token claims which are mapped to the yatai user
+	UsernameClaim string `yaml:"username_claim"`
+	EmailClaim    string `yaml:"email_claim"`
+	GroupsClaim   string `yaml:"groups_claim"`
+	// the local users are linked to the idp accounts with the same username on their first sso login
+	LinkExistingUsers bool `yaml:"link_existing_users"`
+	// the memberships of the mapped organizations are managed by the idp groups
+	GroupMappings []YataiGroupMappingYaml `yaml:"group_mappings"`
+}
+
+type YataiLDAPConfigYaml struct {
+	// e.g. ldaps://ldap.example.com:636
+	URL                string `yaml:"url"`
+	StartTLS           bool   `yaml:"start_tls"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify"`
+	// the service account which searches the users and groups, anonymous when empty
+	BindDN       string `yaml:"bind_dn"`
+	BindPassword string `yaml:"bind_password"`
+	BaseDN       string `yaml:"base_dn"`
+	// every %s is replaced by the escaped login name, e.g. (|(uid=%s)(mail=%s))
+	UserFilter         string `yaml:"user_filter"`
+	UsernameAttribute  string `yaml:"username_attribute"`
+	EmailAttribute     string `yaml:"email_attribute"`
+	FirstNameAttribute string `yaml:"first_name_attribute"`
+	LastNameAttribute  string `yaml:"last_name_attribute"`
+	// the groups of the user are searched under GroupBaseDN when it is set,
+	// every %s of GroupFilter is replaced by the escaped user dn, e.g. (member=%s)
+	GroupBaseDN        string `yaml:"group_base_dn"`
+	GroupFilter        string `yaml:"group_filter"`
+	GroupNameAttribute string `yaml:"group_name_attribute"`
+	// the local users are linked to the directory entries with the same username on their first ldap login
+	LinkExistingUsers bool `yaml:"link_existing_users"`
+	// the memberships of the mapped organizations are synced with the ldap groups on each login
+	GroupMappings []YataiGroupMappingYaml `yaml:"group_mappings"`
+}
+
 type YataiConfigYaml struct {
-	IsSaaS              bool                      `yaml:"is_saas"`
-	SaasDomainSuffix    string                    `yaml:"saas_domain_suffix"`
-	InCluster           bool                      `yaml:"in_cluster"`
-	Server              YataiServerConfigYaml     `yaml:"server"`
-	Postgresql          YataiPostgresqlConfigYaml `yaml:"postgresql"`
-	S3                  *YataiS3ConfigYaml        `yaml:"s3,omitempty"`
-	NewsURL             string                    `yaml:"news_url"`
-	InitializationToken string                    `yaml:"initialization_token"`
+	IsSaaS           bool                      `yaml:"is_saas"`
+	SaasDomainSuffix string                    `yaml:"saas_domain_suffix"`
+	InCluster        bool                      `yaml:"in_cluster"`
+	Server           YataiServerConfigYaml     `yaml:"server"`
+	Postgresql       YataiPostgresqlConfigYaml `yaml:"postgresql"`
+	S3               *YataiS3ConfigYaml        `yaml:"s3,omitempty"`
+	OIDC             *YataiOIDCConfigYaml      `yaml:"oidc,omitempty"`
+	LDAP             *YataiLDAPConfigYaml      `yaml:"ldap,omitempty"`
+	// the users can only login by ldap or sso when the local password login is disabled
+	DisableLocalLogin   bool   `yaml:"disable_local_login"`
+	NewsURL             string `yaml:"news_url"`
+	InitializationToken string `yaml:"initialization_token"`
 }
 
 var YataiConfig = &YataiConfigYaml{}
@@ -122,6 +191,39 @@ func PopulateYataiConfig() error {
 		YataiConfig.Server.ReadHeaderTimeout = readHeaderTimeout_
 	}
 
+	tlsCertFile, ok := os.LookupEnv(consts.EnvTLSCertFile)
+	if ok {
+		YataiConfig.Server.TLS.CertFile = tlsCertFile
+	}
+	tlsKeyFile, ok := os.LookupEnv(consts.EnvTLSKeyFile)
+	if ok {
+		YataiConfig.Server.TLS.KeyFile = tlsKeyFile
+	}
+	tlsClientCAFile, ok := os.LookupEnv(consts.EnvTLSClientCAFile)
+	if ok {
+		YataiConfig.Server.TLS.ClientCAFile = tlsClientCAFile
+	}
+	if YataiConfig.Server.EnableHTTPS && (YataiConfig.Server.TLS.CertFile == "" || YataiConfig.Server.TLS.KeyFile == "") {
+		return errors.New("server.tls.cert_file and server.tls.key_file are required when server.enable_https is true")
+	}
+	if YataiConfig.Server.TLS.RequireClientCert && YataiConfig.Server.TLS.ClientCAFile == "" {
+		return errors.New("server.tls.client_ca_file is required when server.tls.require_client_cert is true")
+	}
+
+	shutdownTimeout, ok := os.LookupEnv(consts.EnvShutdownTimeout)
+	if ok {
+		shutdownTimeout_, err := strconv.Atoi(shutdownTimeout)
+		if err != nil {
+			return errors.Wrapf(err, "convert %s from env to int", consts.EnvShutdownTimeout)
+		}
+		YataiConfig.Server.ShutdownTimeout = shutdownTimeout_
+	}
+
+	trustedProxies, ok := os.LookupEnv(consts.EnvTrustedProxies)
+	if ok {
+		YataiConfig.Server.TrustedProxies = strings.Split(trustedProxies, ",")
+	}
+
 	transmissionStrategy, ok := os.LookupEnv(consts.EnvTransmissionStrategy)
 	if ok {
 		YataiConfig.Server.TransmissionStrategy = transmissionStrategy
@@ -170,5 +272,98 @@ func PopulateYataiConfig() error {
 		makesureS3IsNotNil()
 		YataiConfig.S3.BucketName = s3BucketName
 	}
+
+	makesureOIDCIsNotNil := func() {
+		if YataiConfig.OIDC == nil {
+			YataiConfig.OIDC = &YataiOIDCConfigYaml{}
+		}
+	}
+	oidcIssuer, ok := os.LookupEnv(consts.EnvOIDCIssuer)
+	if ok {
+		makesureOIDCIsNotNil()
+		YataiConfig.OIDC.Issuer = oidcIssuer
+	}
+	oidcClientId, ok := os.LookupEnv(consts.EnvOIDCClientId)
+	if ok {
+		makesureOIDCIsNotNil()
+		YataiConfig.OIDC.ClientId = oidcClientId
+	}
+	oidcClientSecret, ok := os.LookupEnv(consts.EnvOIDCClientSecret)
+	if ok {
+		makesureOIDCIsNotNil()
+		YataiConfig.OIDC.ClientSecret = oidcClientSecret
+	}
+	if YataiConfig.OIDC != nil {
+		if YataiConfig.OIDC.Issuer == "" || YataiConfig.OIDC.ClientId == "" || YataiConfig.OIDC.RedirectURL == "" {
+			return errors.New("oidc.issuer, oidc.client_id and oidc.redirect_url are required when oidc is configured")
+		}
+		if len(YataiConfig.OIDC.Scopes) == 0 {
+			YataiConfig.OIDC.Scopes = []string{"openid", "profile", "email"}
+		}
+		if YataiConfig.OIDC.UsernameClaim == "" {
+			YataiConfig.OIDC.UsernameClaim = "preferred_username"
+		}
+		if YataiConfig.OIDC.EmailClaim == "" {
+			YataiConfig.OIDC.EmailClaim = "email"
+		}
+		if YataiConfig.OIDC.GroupsClaim == "" {
+			YataiConfig.OIDC.GroupsClaim = "groups"
+		}
+		err := validateGroupMappings("oidc", YataiConfig.OIDC.GroupMappings)
+		if err != nil {
+			return err
+		}
+	}
+
+	ldapBindPassword, ok := os.LookupEnv(consts.EnvLDAPBindPassword)
+	if ok && YataiConfig.LDAP != nil {
+		YataiConfig.LDAP.BindPassword = ldapBindPassword
+	}
+	if YataiConfig.LDAP != nil {
+		if YataiConfig.LDAP.URL == "" || YataiConfig.LDAP.BaseDN == "" {
+			return errors.New("ldap.url and ldap.base_dn are required when ldap is configured")
+		}
+		if YataiConfig.LDAP.UserFilter == "" {
+			YataiConfig.LDAP.UserFilter = "(uid=%s)"
+		}
+		if YataiConfig.LDAP.UsernameAttribute == "" {
+			YataiConfig.LDAP.UsernameAttribute = "uid"
+		}
+		if YataiConfig.LDAP.EmailAttribute == "" {
+			YataiConfig.LDAP.EmailAttribute = "mail"
+		}
+		if YataiConfig.LDAP.FirstNameAttribute == "" {
+			YataiConfig.LDAP.FirstNameAttribute = "givenName"
+		}
+		if YataiConfig.LDAP.LastNameAttribute == "" {
+			YataiConfig.LDAP.LastNameAttribute = "sn"
+		}
+		if YataiConfig.LDAP.GroupFilter == "" {
+			YataiConfig.LDAP.GroupFilter = "(member=%s)"
+		}
+		if YataiConfig.LDAP.GroupNameAttribute == "" {
+			YataiConfig.LDAP.GroupNameAttribute = "cn"
+		}
+		err := validateGroupMappings("ldap", YataiConfig.LDAP.GroupMappings)
+		if err != nil {
+			return err
+		}
+	}
+
+	disableLocalLogin, ok := os.LookupEnv(consts.EnvDisableLocalLogin)
+	if ok {
+		YataiConfig.DisableLocalLogin = disableLocalLogin == "true"
+	}
+	return nil
+}
+
+func validateGroupMappings(section string, mappings []YataiGroupMappingYaml) error {
+	for _, mapping := range mappings {
+		switch mapping.Role {
+		case modelschemas.MemberRoleGuest, modelschemas.MemberRoleDeveloper, modelschemas.MemberRoleAdmin:
+		default:
+			return errors.Errorf("invalid role %s of the %s group mapping %s", mapping.Role, section, mapping.Group)
+		}
+	}
 	return nil
 }
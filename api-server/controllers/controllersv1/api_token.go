@@ -45,9 +45,13 @@ func (s *GetApiTokenSchema) GetApiToken(ctx context.Context) (*models.ApiToken,
 type CreateApiTokenSchema struct {
 	schemasv1.CreateApiTokenSchema
 	GetOrganizationSchema
+	// limits the token to the selected resources, all the resources of the organization by default
+	ResourceSelectors *models.ApiTokenResourceSelectors `json:"resource_selectors"`
+	// limits the client ips which can use the token, any ip by default
+	AllowedCidrs *models.ApiTokenAllowedCidrs `json:"allowed_cidrs"`
 }
 
-func (c *apiTokenController) Create(ctx *gin.Context, schema *CreateApiTokenSchema) (*schemasv1.ApiTokenFullSchema, error) {
+func (c *apiTokenController) Create(ctx *gin.Context, schema *CreateApiTokenSchema) (*transformersv1.ApiTokenWithPrefixFullSchema, error) {
 	user, err := services.GetCurrentUser(ctx)
 	if err != nil {
 		return nil, err
@@ -58,12 +62,14 @@ func (c *apiTokenController) Create(ctx *gin.Context, schema *CreateApiTokenSche
 	}
 
 	apiToken, err := services.ApiTokenService.Create(ctx, services.CreateApiTokenOption{
-		UserId:         user.ID,
-		OrganizationId: org.ID,
-		Name:           schema.Name,
-		Description:    schema.Description,
-		Scopes:         schema.Scopes,
-		ExpiredAt:      schema.ExpiredAt,
+		UserId:            user.ID,
+		OrganizationId:    org.ID,
+		Name:              schema.Name,
+		Description:       schema.Description,
+		Scopes:            schema.Scopes,
+		ResourceSelectors: schema.ResourceSelectors,
+		AllowedCidrs:      schema.AllowedCidrs,
+		ExpiredAt:         schema.ExpiredAt,
 	})
 	if err != nil {
 		return nil, errors.Wrap(err, "create apiToken")
@@ -74,9 +80,13 @@ func (c *apiTokenController) Create(ctx *gin.Context, schema *CreateApiTokenSche
 type UpdateApiTokenSchema struct {
 	schemasv1.UpdateApiTokenSchema
 	GetApiTokenSchema
+	// an empty list removes the limit
+	ResourceSelectors *models.ApiTokenResourceSelectors `json:"resource_selectors"`
+	// an empty list removes the limit
+	AllowedCidrs *models.ApiTokenAllowedCidrs `json:"allowed_cidrs"`
 }
 
-func (c *apiTokenController) Update(ctx *gin.Context, schema *UpdateApiTokenSchema) (*schemasv1.ApiTokenSchema, error) {
+func (c *apiTokenController) Update(ctx *gin.Context, schema *UpdateApiTokenSchema) (*transformersv1.ApiTokenWithPrefixSchema, error) {
 	apiToken, err := schema.GetApiToken(ctx)
 	if err != nil {
 		return nil, err
@@ -85,30 +95,62 @@ func (c *apiTokenController) Update(ctx *gin.Context, schema *UpdateApiTokenSche
 	if schema.Scopes != nil {
 		scopes = &schema.Scopes
 	}
+	var resourceSelectors **models.ApiTokenResourceSelectors
+	if schema.ResourceSelectors != nil {
+		resourceSelectors = &schema.ResourceSelectors
+	}
+	var allowedCidrs **models.ApiTokenAllowedCidrs
+	if schema.AllowedCidrs != nil {
+		allowedCidrs = &schema.AllowedCidrs
+	}
 	var expiredAt **time.Time
 	if schema.ExpiredAt != nil {
 		expiredAt = &schema.ExpiredAt
 	}
 	apiToken, err = services.ApiTokenService.Update(ctx, apiToken, services.UpdateApiTokenOption{
-		Description: schema.Description,
-		Scopes:      scopes,
-		ExpiredAt:   expiredAt,
+		Description:       schema.Description,
+		Scopes:            scopes,
+		ResourceSelectors: resourceSelectors,
+		AllowedCidrs:      allowedCidrs,
+		ExpiredAt:         expiredAt,
 	})
 	if err != nil {
 		return nil, errors.Wrap(err, "update apiToken")
 	}
-	return transformersv1.ToApiTokenSchema(ctx, apiToken)
+	return transformersv1.ToApiTokenWithPrefixSchema(ctx, apiToken)
+}
+
+type RotateApiTokenSchema struct {
+	GetApiTokenSchema
+	// the previous secret keeps working for this long, 24 hours by default
+	OverlapSeconds *uint `json:"overlap_seconds"`
 }
 
-func (c *apiTokenController) Get(ctx *gin.Context, schema *GetApiTokenSchema) (*schemasv1.ApiTokenSchema, error) {
+func (c *apiTokenController) Rotate(ctx *gin.Context, schema *RotateApiTokenSchema) (*transformersv1.ApiTokenWithPrefixFullSchema, error) {
 	apiToken, err := schema.GetApiToken(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return transformersv1.ToApiTokenSchema(ctx, apiToken)
+	overlap := services.DefaultApiTokenRotationOverlap
+	if schema.OverlapSeconds != nil {
+		overlap = time.Duration(*schema.OverlapSeconds) * time.Second
+	}
+	apiToken, err = services.ApiTokenService.Rotate(ctx, apiToken, overlap)
+	if err != nil {
+		return nil, errors.Wrap(err, "rotate apiToken")
+	}
+	return transformersv1.ToApiTokenFullSchema(ctx, apiToken)
 }
 
-func (c *apiTokenController) Delete(ctx *gin.Context, schema *GetApiTokenSchema) (*schemasv1.ApiTokenSchema, error) {
+func (c *apiTokenController) Get(ctx *gin.Context, schema *GetApiTokenSchema) (*transformersv1.ApiTokenWithPrefixSchema, error) {
+	apiToken, err := schema.GetApiToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return transformersv1.ToApiTokenWithPrefixSchema(ctx, apiToken)
+}
+
+func (c *apiTokenController) Delete(ctx *gin.Context, schema *GetApiTokenSchema) (*transformersv1.ApiTokenWithPrefixSchema, error) {
 	apiToken, err := schema.GetApiToken(ctx)
 	if err != nil {
 		return nil, err
@@ -117,7 +159,40 @@ func (c *apiTokenController) Delete(ctx *gin.Context, schema *GetApiTokenSchema)
 	if err != nil {
 		return nil, err
 	}
-	return transformersv1.ToApiTokenSchema(ctx, apiToken)
+	return transformersv1.ToApiTokenWithPrefixSchema(ctx, apiToken)
+}
+
+type ListApiTokenUsageSchema struct {
+	schemasv1.ListQuerySchema
+	GetApiTokenSchema
+}
+
+func (c *apiTokenController) ListUsages(ctx *gin.Context, schema *ListApiTokenUsageSchema) (*transformersv1.ApiTokenUsageListSchema, error) {
+	apiToken, err := schema.GetApiToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	apiTokenUsages, total, err := services.ApiTokenUsageService.List(ctx, services.ListApiTokenUsageOption{
+		ApiTokenId: utils.UintPtr(apiToken.ID),
+		BaseListOption: services.BaseListOption{
+			Start: utils.UintPtr(schema.Start),
+			Count: utils.UintPtr(schema.Count),
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "list apiTokenUsages")
+	}
+
+	apiTokenUsageSchemas, err := transformersv1.ToApiTokenUsageSchemas(ctx, apiTokenUsages)
+	return &transformersv1.ApiTokenUsageListSchema{
+		BaseListSchema: schemasv1.BaseListSchema{
+			Total: total,
+			Start: schema.Start,
+			Count: schema.Count,
+		},
+		Items: apiTokenUsageSchemas,
+	}, err
 }
 
 type ListApiTokenSchema struct {
@@ -125,7 +200,7 @@ type ListApiTokenSchema struct {
 	GetOrganizationSchema
 }
 
-func (c *apiTokenController) List(ctx *gin.Context, schema *ListApiTokenSchema) (*schemasv1.ApiTokenListSchema, error) {
+func (c *apiTokenController) List(ctx *gin.Context, schema *ListApiTokenSchema) (*transformersv1.ApiTokenWithPrefixListSchema, error) {
 	user, err := services.GetCurrentUser(ctx)
 	if err != nil {
 		return nil, err
@@ -148,8 +223,8 @@ func (c *apiTokenController) List(ctx *gin.Context, schema *ListApiTokenSchema)
 		return nil, errors.Wrap(err, "list apiTokens")
 	}
 
-	apiTokenSchemas, err := transformersv1.ToApiTokenSchemas(ctx, apiTokens)
-	return &schemasv1.ApiTokenListSchema{
+	apiTokenSchemas, err := transformersv1.ToApiTokenWithPrefixSchemas(ctx, apiTokens)
+	return &transformersv1.ApiTokenWithPrefixListSchema{
 		BaseListSchema: schemasv1.BaseListSchema{
 			Total: total,
 			Start: schema.Start,
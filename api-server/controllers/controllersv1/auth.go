@@ -2,15 +2,15 @@ package controllersv1
 
 import (
 	"net/http"
-	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/pkg/errors"
 
 	"github.com/bentoml/yatai-schemas/schemasv1"
-	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/api-server/config"
 	"github.com/bentoml/yatai/api-server/services"
 	"github.com/bentoml/yatai/api-server/transformers/transformersv1"
+	"github.com/bentoml/yatai/common/consts"
 	"github.com/bentoml/yatai/common/scookie"
 	"github.com/bentoml/yatai/common/utils"
 )
@@ -22,6 +22,9 @@ type authController struct {
 var AuthController = authController{}
 
 func (*authController) Register(ctx *gin.Context, schema *schemasv1.RegisterUserSchema) (*schemasv1.UserSchema, error) {
+	if config.YataiConfig.DisableLocalLogin {
+		return nil, errors.New("the local password login is disabled, please login with ldap or sso")
+	}
 	user, err := services.UserService.Create(ctx, services.CreateUserOption{
 		Name:      schema.Name,
 		FirstName: schema.FirstName,
@@ -32,41 +35,77 @@ func (*authController) Register(ctx *gin.Context, schema *schemasv1.RegisterUser
 	if err != nil {
 		return nil, errors.Wrap(err, "create user")
 	}
-	err = scookie.SetUsernameToCookie(ctx, user.Name)
+	err = services.UserSessionService.Login(ctx, user)
 	if err != nil {
 		return nil, errors.Wrap(err, "set login cookie")
 	}
 	return transformersv1.ToUserSchema(ctx, user)
 }
 
-func (*authController) Login(ctx *gin.Context, schema *schemasv1.LoginUserSchema) (*schemasv1.UserSchema, error) {
-	isEmail := strings.Contains(schema.NameOrEmail, "@")
-	var err error
-	var user *models.User
-	if isEmail {
-		user, err = services.UserService.GetByEmail(ctx, schema.NameOrEmail)
-	} else {
-		user, err = services.UserService.GetByName(ctx, schema.NameOrEmail)
+func (*authController) Login(ctx *gin.Context, schema *schemasv1.LoginUserSchema) (*transformersv1.LoginResultSchema, error) {
+	user, err := services.AuthService.Login(ctx, schema.NameOrEmail, schema.Password)
+	if err != nil {
+		return nil, err
+	}
+	// the session is only started after the two-factor code is verified by LoginTwoFactor
+	if user.TotpEnabled {
+		err = scookie.SetTwoFactorPendingUserIdToCookie(ctx, user.ID)
+		if err != nil {
+			return nil, errors.Wrap(err, "set two-factor pending cookie")
+		}
+		return &transformersv1.LoginResultSchema{
+			TwoFactorRequired: true,
+		}, nil
+	}
+	err = services.UserSessionService.Login(ctx, user)
+	if err != nil {
+		return nil, errors.Wrap(err, "set login cookie")
 	}
+	redirectLogin(ctx)
+	userSchema, err := transformersv1.ToUserSchema(ctx, user)
 	if err != nil {
-		return nil, errors.New("invalid username or password")
+		return nil, err
+	}
+	return &transformersv1.LoginResultSchema{
+		UserSchema: *userSchema,
+	}, nil
+}
+
+type LoginTwoFactorSchema struct {
+	Code string `json:"code"`
+}
+
+func (*authController) LoginTwoFactor(ctx *gin.Context, schema *LoginTwoFactorSchema) (*schemasv1.UserSchema, error) {
+	userId := scookie.GetTwoFactorPendingUserIdFromCookie(ctx)
+	if userId == 0 {
+		return nil, errors.New("the two-factor login is expired, please login again")
 	}
-	if user.Email == nil || *user.Email == "" {
-		return nil, errors.Errorf("user %s email is empty, it looks like yatai did not complete the setup process", user.Name)
+	user, err := services.UserService.Get(ctx, userId)
+	if err != nil {
+		return nil, errors.Wrap(err, "get user")
 	}
-	if err = services.UserService.CheckPassword(ctx, user, schema.Password); err != nil {
+	err = services.TwoFactorService.Verify(ctx, user, schema.Code)
+	if err != nil {
 		return nil, err
 	}
-	err = scookie.SetUsernameToCookie(ctx, user.Name)
+	err = scookie.DeleteTwoFactorPendingUserIdFromCookie(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "delete two-factor pending cookie")
+	}
+	err = services.UserSessionService.Login(ctx, user)
 	if err != nil {
 		return nil, errors.Wrap(err, "set login cookie")
 	}
+	redirectLogin(ctx)
+	return transformersv1.ToUserSchema(ctx, user)
+}
+
+func redirectLogin(ctx *gin.Context) {
 	redirectUri := ctx.Query("redirect")
 	if redirectUri == "" {
 		redirectUri = "/"
 	}
 	ctx.Redirect(http.StatusSeeOther, redirectUri)
-	return transformersv1.ToUserSchema(ctx, user)
 }
 
 func (*authController) GetCurrentUser(ctx *gin.Context) (*schemasv1.UserSchema, error) {
@@ -88,5 +127,128 @@ func (*authController) ResetPassword(ctx *gin.Context, schema *schemasv1.ResetPa
 		return nil, err
 	}
 
+	// all sessions are expired by the password change, the current client gets a new one
+	if currentUser.ApiToken == nil {
+		err = services.UserSessionService.Login(ctx, user)
+		if err != nil {
+			return nil, errors.Wrap(err, "renew login session")
+		}
+	}
+
 	return transformersv1.ToUserSchema(ctx, user)
 }
+
+func (*authController) ListSessions(ctx *gin.Context) ([]*transformersv1.UserSessionSchema, error) {
+	currentUser, err := services.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sessions, err := services.UserSessionService.List(ctx, services.ListUserSessionOption{
+		UserId: utils.UintPtr(currentUser.ID),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "list user sessions")
+	}
+	return transformersv1.ToUserSessionSchemas(ctx, sessions)
+}
+
+type RevokeSessionSchema struct {
+	SessionUid string `path:"sessionUid"`
+}
+
+func (*authController) RevokeSession(ctx *gin.Context, schema *RevokeSessionSchema) (*transformersv1.UserSessionSchema, error) {
+	currentUser, err := services.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	session, err := services.UserSessionService.GetByUid(ctx, schema.SessionUid)
+	if err != nil {
+		return nil, errors.Wrapf(err, "get user session %s", schema.SessionUid)
+	}
+	if session.UserId != currentUser.ID {
+		return nil, consts.ErrNotFound
+	}
+	session, err = services.UserSessionService.Delete(ctx, session)
+	if err != nil {
+		return nil, errors.Wrap(err, "delete user session")
+	}
+	return transformersv1.ToUserSessionSchema(ctx, session)
+}
+
+func (*authController) GetTwoFactor(ctx *gin.Context) (*transformersv1.TwoFactorSchema, error) {
+	currentUser, err := services.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return transformersv1.ToTwoFactorSchema(ctx, currentUser)
+}
+
+func (*authController) EnrollTwoFactor(ctx *gin.Context) (*transformersv1.TwoFactorEnrollmentSchema, error) {
+	currentUser, err := services.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	enrollment, err := services.TwoFactorService.Enroll(ctx, currentUser)
+	if err != nil {
+		return nil, err
+	}
+	return &transformersv1.TwoFactorEnrollmentSchema{
+		Secret:          enrollment.Secret,
+		ProvisioningUri: enrollment.ProvisioningUri,
+	}, nil
+}
+
+type TwoFactorCodeSchema struct {
+	Code string `json:"code"`
+}
+
+func (*authController) EnableTwoFactor(ctx *gin.Context, schema *TwoFactorCodeSchema) (*transformersv1.TwoFactorRecoveryCodesSchema, error) {
+	currentUser, err := services.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	recoveryCodes, err := services.TwoFactorService.Enable(ctx, currentUser, schema.Code)
+	if err != nil {
+		return nil, err
+	}
+	return &transformersv1.TwoFactorRecoveryCodesSchema{
+		RecoveryCodes: recoveryCodes,
+	}, nil
+}
+
+func (*authController) DisableTwoFactor(ctx *gin.Context, schema *TwoFactorCodeSchema) (*transformersv1.TwoFactorSchema, error) {
+	currentUser, err := services.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	org, err := services.GetCurrentOrganization(ctx)
+	if err != nil {
+		return nil, err
+	}
+	required, err := services.TwoFactorService.IsRequired(ctx, currentUser, org)
+	if err != nil {
+		return nil, err
+	}
+	if required {
+		return nil, errors.Errorf("the two-factor authentication is required by the organization %s", org.Name)
+	}
+	err = services.TwoFactorService.Disable(ctx, currentUser, schema.Code)
+	if err != nil {
+		return nil, err
+	}
+	return transformersv1.ToTwoFactorSchema(ctx, currentUser)
+}
+
+func (*authController) RegenerateTwoFactorRecoveryCodes(ctx *gin.Context, schema *TwoFactorCodeSchema) (*transformersv1.TwoFactorRecoveryCodesSchema, error) {
+	currentUser, err := services.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	recoveryCodes, err := services.TwoFactorService.RegenerateRecoveryCodes(ctx, currentUser, schema.Code)
+	if err != nil {
+		return nil, err
+	}
+	return &transformersv1.TwoFactorRecoveryCodesSchema{
+		RecoveryCodes: recoveryCodes,
+	}, nil
+}
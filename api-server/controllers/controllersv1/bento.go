@@ -12,6 +12,7 @@ import (
 	"github.com/huandu/xstrings"
 	"github.com/minio/minio-go/v7"
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 
 	"github.com/bentoml/yatai-schemas/modelschemas"
 	"github.com/bentoml/yatai-schemas/schemasv1"
@@ -37,7 +38,7 @@ func (s *GetBentoSchema) GetBento(ctx context.Context) (*models.Bento, error) {
 	if err != nil {
 		return nil, errors.Wrapf(err, "get bentoRepository %s", s.BentoRepositoryName)
 	}
-	bento, err := services.BentoService.GetByVersion(ctx, bentoRepository.ID, s.Version)
+	bento, err := services.BentoService.GetByVersionOrAlias(ctx, bentoRepository.ID, s.Version)
 	if err != nil {
 		return nil, errors.Wrapf(err, "get bentoRepository %s bento %s", bentoRepository.Name, s.Version)
 	}
@@ -507,6 +508,9 @@ func (c *bentoController) FinishUpload(ctx *gin.Context, schema *FinishUploadBen
 		if _, err = services.EventService.Create(ctx, createEventOpt); err != nil {
 			return nil, errors.Wrap(err, "create event")
 		}
+		if err = services.DeploymentFollowService.TriggerByBento(ctx, bento); err != nil {
+			logrus.Errorf("trigger auto deploy by bento %s: %s", bento.Version, err.Error())
+		}
 	}
 	return transformersv1.ToBentoSchema(ctx, bento)
 }
@@ -534,7 +538,7 @@ func (c *bentoController) ListImageBuilderPods(ctx *gin.Context, schema *GetBent
 	return transformersv1.ToKubePodSchemas(ctx, majorCluster.ID, pods)
 }
 
-func (c *bentoController) Get(ctx *gin.Context, schema *GetBentoSchema) (*schemasv1.BentoFullSchema, error) {
+func (c *bentoController) Get(ctx *gin.Context, schema *GetBentoSchema) (*transformersv1.BentoFullWithAliasesSchema, error) {
 	bento, err := schema.GetBento(ctx)
 	if err != nil {
 		return nil, err
@@ -542,7 +546,22 @@ func (c *bentoController) Get(ctx *gin.Context, schema *GetBentoSchema) (*schema
 	if err = c.canView(ctx, bento); err != nil {
 		return nil, err
 	}
-	return transformersv1.ToBentoFullSchema(ctx, bento)
+	return transformersv1.ToBentoFullWithAliasesSchema(ctx, bento)
+}
+
+func (c *bentoController) Delete(ctx *gin.Context, schema *GetBentoSchema) (*schemasv1.BentoSchema, error) {
+	bento, err := schema.GetBento(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canOperate(ctx, bento); err != nil {
+		return nil, err
+	}
+	bento, err = services.BentoService.Delete(ctx, bento)
+	if err != nil {
+		return nil, errors.Wrap(err, "delete bento")
+	}
+	return transformersv1.ToBentoSchema(ctx, bento)
 }
 
 type ListBentoDeploymentSchema struct {
@@ -729,6 +748,11 @@ func (c *bentoController) ListAll(ctx *gin.Context, schema *ListAllBentoSchema)
 		return nil, err
 	}
 
+	currentUser, err := services.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	listOpt := services.ListBentoOption{
 		BaseListOption: services.BaseListOption{
 			Start:  utils.UintPtr(schema.Start),
@@ -736,6 +760,7 @@ func (c *bentoController) ListAll(ctx *gin.Context, schema *ListAllBentoSchema)
 			Search: schema.Search,
 		},
 		OrganizationId: utils.UintPtr(organization.ID),
+		VisitorId:      utils.UintPtr(currentUser.ID),
 	}
 
 	err = c.buildListOpt(ctx, &listOpt, schema.Q)
@@ -824,7 +849,7 @@ func (c *bentoController) UpdateBentoImageBuildStatus(ctx *gin.Context, schema *
 
 	now := time.Now()
 	nowPtr := &now
-	_, err = services.BentoService.Update(ctx, bento, services.UpdateBentoOption{
+	bento, err = services.BentoService.Update(ctx, bento, services.UpdateBentoOption{
 		ImageBuildStatus:          &schema.ImageBuildStatus,
 		ImageBuildStatusUpdatedAt: &nowPtr,
 	})
@@ -832,5 +857,11 @@ func (c *bentoController) UpdateBentoImageBuildStatus(ctx *gin.Context, schema *
 		return errors.Wrap(err, "update bento")
 	}
 
+	if schema.ImageBuildStatus == modelschemas.ImageBuildStatusSuccess {
+		if err = services.DeploymentFollowService.TriggerByBento(ctx, bento); err != nil {
+			logrus.Errorf("trigger auto deploy by bento %s: %s", bento.Version, err.Error())
+		}
+	}
+
 	return nil
 }
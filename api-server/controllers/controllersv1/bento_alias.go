@@ -0,0 +1,112 @@
+package controllersv1
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/api-server/services"
+	"github.com/bentoml/yatai/api-server/transformers/transformersv1"
+)
+
+type bentoAliasController struct {
+	baseController
+}
+
+var BentoAliasController = bentoAliasController{}
+
+type GetBentoAliasSchema struct {
+	GetBentoRepositorySchema
+	AliasName string `path:"aliasName"`
+}
+
+func (s *GetBentoAliasSchema) GetBentoAlias(ctx context.Context) (*models.BentoAlias, error) {
+	bentoRepository, err := s.GetBentoRepository(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "get bentoRepository %s", s.BentoRepositoryName)
+	}
+	bentoAlias, err := services.BentoAliasService.GetByName(ctx, bentoRepository.ID, s.AliasName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "get bentoRepository %s alias %s", bentoRepository.Name, s.AliasName)
+	}
+	return bentoAlias, nil
+}
+
+func (c *bentoAliasController) List(ctx *gin.Context, schema *GetBentoRepositorySchema) ([]*transformersv1.BentoAliasSchema, error) {
+	bentoRepository, err := schema.GetBentoRepository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = BentoRepositoryController.canView(ctx, bentoRepository); err != nil {
+		return nil, err
+	}
+	bentoAliases, err := services.BentoAliasService.List(ctx, services.ListBentoAliasOption{
+		BentoRepositoryId: &bentoRepository.ID,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "list bento aliases")
+	}
+	return transformersv1.ToBentoAliasSchemas(ctx, bentoAliases)
+}
+
+type SetBentoAliasSchema struct {
+	GetBentoAliasSchema
+	Version string `json:"version"`
+}
+
+func (c *bentoAliasController) Set(ctx *gin.Context, schema *SetBentoAliasSchema) (*transformersv1.BentoAliasSchema, error) {
+	user, err := services.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	bentoRepository, err := schema.GetBentoRepository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = BentoRepositoryController.canUpdate(ctx, bentoRepository); err != nil {
+		return nil, err
+	}
+	bento, err := services.BentoService.GetByVersionOrAlias(ctx, bentoRepository.ID, schema.Version)
+	if err != nil {
+		return nil, errors.Wrapf(err, "get bentoRepository %s bento %s", bentoRepository.Name, schema.Version)
+	}
+	bentoAlias, err := services.BentoAliasService.Set(ctx, services.SetBentoAliasOption{
+		CreatorId:         user.ID,
+		BentoRepositoryId: bentoRepository.ID,
+		BentoId:           bento.ID,
+		Name:              schema.AliasName,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "set bento alias")
+	}
+	if err = services.DeploymentFollowService.TriggerByBento(ctx, bento); err != nil {
+		logrus.Errorf("trigger auto deploy by bento %s: %s", bento.Version, err.Error())
+	}
+	return transformersv1.ToBentoAliasSchema(ctx, bentoAlias)
+}
+
+func (c *bentoAliasController) Delete(ctx *gin.Context, schema *GetBentoAliasSchema) (*transformersv1.BentoAliasSchema, error) {
+	bentoRepository, err := schema.GetBentoRepository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = BentoRepositoryController.canUpdate(ctx, bentoRepository); err != nil {
+		return nil, err
+	}
+	bentoAlias, err := schema.GetBentoAlias(ctx)
+	if err != nil {
+		return nil, err
+	}
+	bentoAliasSchema, err := transformersv1.ToBentoAliasSchema(ctx, bentoAlias)
+	if err != nil {
+		return nil, err
+	}
+	_, err = services.BentoAliasService.Delete(ctx, bentoAlias)
+	if err != nil {
+		return nil, errors.Wrap(err, "delete bento alias")
+	}
+	return bentoAliasSchema, nil
+}
@@ -41,35 +41,36 @@ func (s *GetBentoRepositorySchema) GetBentoRepository(ctx context.Context) (*mod
 }
 
 func (c *bentoRepositoryController) canView(ctx context.Context, bentoRepository *models.BentoRepository) error {
-	organization, err := services.OrganizationService.GetAssociatedOrganization(ctx, bentoRepository)
+	user, err := services.GetCurrentUser(ctx)
 	if err != nil {
-		return errors.Wrap(err, "get associated organization")
+		return err
 	}
-	return OrganizationController.canView(ctx, organization)
+	return services.MemberService.CanView(ctx, &services.BentoRepositoryMemberService, user, bentoRepository.ID, bentoRepository)
 }
 
 func (c *bentoRepositoryController) canUpdate(ctx context.Context, bentoRepository *models.BentoRepository) error {
-	organization, err := services.OrganizationService.GetAssociatedOrganization(ctx, bentoRepository)
+	user, err := services.GetCurrentUser(ctx)
 	if err != nil {
-		return errors.Wrap(err, "get associated organization")
+		return err
 	}
-	return OrganizationController.canUpdate(ctx, organization)
+	return services.MemberService.CanUpdate(ctx, &services.BentoRepositoryMemberService, user, bentoRepository.ID, bentoRepository)
 }
 
 func (c *bentoRepositoryController) canOperate(ctx context.Context, bentoRepository *models.BentoRepository) error {
-	organization, err := services.OrganizationService.GetAssociatedOrganization(ctx, bentoRepository)
+	user, err := services.GetCurrentUser(ctx)
 	if err != nil {
-		return errors.Wrap(err, "get associated organization")
+		return err
 	}
-	return OrganizationController.canOperate(ctx, organization)
+	return services.MemberService.CanOperate(ctx, &services.BentoRepositoryMemberService, user, bentoRepository.ID, bentoRepository)
 }
 
 type CreateBentoRepositorySchema struct {
 	schemasv1.CreateBentoRepositorySchema
 	GetOrganizationSchema
+	Visibility models.RepositoryVisibility `json:"visibility" enum:"internal,private"`
 }
 
-func (c *bentoRepositoryController) Create(ctx *gin.Context, schema *CreateBentoRepositorySchema) (*schemasv1.BentoRepositorySchema, error) {
+func (c *bentoRepositoryController) Create(ctx *gin.Context, schema *CreateBentoRepositorySchema) (*transformersv1.BentoRepositoryWithVisibilitySchema, error) {
 	user, err := services.GetCurrentUser(ctx)
 	if err != nil {
 		return nil, err
@@ -87,19 +88,21 @@ func (c *bentoRepositoryController) Create(ctx *gin.Context, schema *CreateBento
 		OrganizationId: organization.ID,
 		Name:           schema.Name,
 		Labels:         schema.Labels,
+		Visibility:     schema.Visibility,
 	})
 	if err != nil {
 		return nil, errors.Wrap(err, "create bentoRepository")
 	}
-	return transformersv1.ToBentoRepositorySchema(ctx, bentoRepository)
+	return transformersv1.ToBentoRepositoryWithVisibilitySchema(ctx, bentoRepository)
 }
 
 type UpdateBentoRepositorySchema struct {
 	schemasv1.UpdateBentoRepositorySchema
 	GetBentoRepositorySchema
+	Visibility *models.RepositoryVisibility `json:"visibility"`
 }
 
-func (c *bentoRepositoryController) Update(ctx *gin.Context, schema *UpdateBentoRepositorySchema) (*schemasv1.BentoRepositorySchema, error) {
+func (c *bentoRepositoryController) Update(ctx *gin.Context, schema *UpdateBentoRepositorySchema) (*transformersv1.BentoRepositoryWithVisibilitySchema, error) {
 	bentoRepository, err := schema.GetBentoRepository(ctx)
 	if err != nil {
 		return nil, err
@@ -110,14 +113,30 @@ func (c *bentoRepositoryController) Update(ctx *gin.Context, schema *UpdateBento
 	bentoRepository, err = services.BentoRepositoryService.Update(ctx, bentoRepository, services.UpdateBentoRepositoryOption{
 		Description: schema.Description,
 		Labels:      schema.Labels,
+		Visibility:  schema.Visibility,
 	})
 	if err != nil {
 		return nil, errors.Wrap(err, "update bentoRepository")
 	}
+	return transformersv1.ToBentoRepositoryWithVisibilitySchema(ctx, bentoRepository)
+}
+
+func (c *bentoRepositoryController) Delete(ctx *gin.Context, schema *GetBentoRepositorySchema) (*schemasv1.BentoRepositorySchema, error) {
+	bentoRepository, err := schema.GetBentoRepository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canOperate(ctx, bentoRepository); err != nil {
+		return nil, err
+	}
+	bentoRepository, err = services.BentoRepositoryService.Delete(ctx, bentoRepository)
+	if err != nil {
+		return nil, errors.Wrap(err, "delete bentoRepository")
+	}
 	return transformersv1.ToBentoRepositorySchema(ctx, bentoRepository)
 }
 
-func (c *bentoRepositoryController) Get(ctx *gin.Context, schema *GetBentoRepositorySchema) (*schemasv1.BentoRepositorySchema, error) {
+func (c *bentoRepositoryController) GetRetentionPolicy(ctx *gin.Context, schema *GetBentoRepositorySchema) (*transformersv1.RetentionPolicySchema, error) {
 	bentoRepository, err := schema.GetBentoRepository(ctx)
 	if err != nil {
 		return nil, err
@@ -125,7 +144,59 @@ func (c *bentoRepositoryController) Get(ctx *gin.Context, schema *GetBentoReposi
 	if err = c.canView(ctx, bentoRepository); err != nil {
 		return nil, err
 	}
-	return transformersv1.ToBentoRepositorySchema(ctx, bentoRepository)
+	return transformersv1.ToRetentionPolicySchema(bentoRepository.RetentionPolicy), nil
+}
+
+type UpdateBentoRepositoryRetentionPolicySchema struct {
+	models.RetentionPolicy
+	GetBentoRepositorySchema
+}
+
+func (c *bentoRepositoryController) UpdateRetentionPolicy(ctx *gin.Context, schema *UpdateBentoRepositoryRetentionPolicySchema) (*transformersv1.RetentionPolicySchema, error) {
+	bentoRepository, err := schema.GetBentoRepository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canOperate(ctx, bentoRepository); err != nil {
+		return nil, err
+	}
+	retentionPolicy := &schema.RetentionPolicy
+	if retentionPolicy.IsEmpty() {
+		retentionPolicy = nil
+	}
+	bentoRepository, err = services.BentoRepositoryService.Update(ctx, bentoRepository, services.UpdateBentoRepositoryOption{
+		RetentionPolicy: &retentionPolicy,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "update bentoRepository retention policy")
+	}
+	return transformersv1.ToRetentionPolicySchema(bentoRepository.RetentionPolicy), nil
+}
+
+func (c *bentoRepositoryController) DryRunRetentionPolicy(ctx *gin.Context, schema *GetBentoRepositorySchema) ([]*schemasv1.BentoSchema, error) {
+	bentoRepository, err := schema.GetBentoRepository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canView(ctx, bentoRepository); err != nil {
+		return nil, err
+	}
+	bentos, err := services.RetentionService.ListPrunableBentos(ctx, bentoRepository)
+	if err != nil {
+		return nil, errors.Wrap(err, "list prunable bentos")
+	}
+	return transformersv1.ToBentoSchemas(ctx, bentos)
+}
+
+func (c *bentoRepositoryController) Get(ctx *gin.Context, schema *GetBentoRepositorySchema) (*transformersv1.BentoRepositoryWithVisibilitySchema, error) {
+	bentoRepository, err := schema.GetBentoRepository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canView(ctx, bentoRepository); err != nil {
+		return nil, err
+	}
+	return transformersv1.ToBentoRepositoryWithVisibilitySchema(ctx, bentoRepository)
 }
 
 type ListBentoRepositoryDeploymentSchema struct {
@@ -205,6 +276,11 @@ func (c *bentoRepositoryController) List(ctx *gin.Context, schema *ListBentoRepo
 		return nil, err
 	}
 
+	currentUser, err := services.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	listOpt := services.ListBentoRepositoryOption{
 		BaseListOption: services.BaseListOption{
 			Start:  utils.UintPtr(schema.Start),
@@ -212,6 +288,7 @@ func (c *bentoRepositoryController) List(ctx *gin.Context, schema *ListBentoRepo
 			Search: schema.Search,
 		},
 		OrganizationId: utils.UintPtr(organization.ID),
+		VisitorId:      utils.UintPtr(currentUser.ID),
 	}
 
 	queryMap := schema.Q.ToMap()
@@ -0,0 +1,107 @@
+package controllersv1
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+
+	"github.com/bentoml/yatai-schemas/schemasv1"
+	"github.com/bentoml/yatai/api-server/services"
+	"github.com/bentoml/yatai/api-server/transformers/transformersv1"
+	"github.com/bentoml/yatai/common/utils"
+)
+
+type bentoRepositoryMemberController struct {
+	bentoRepositoryController
+}
+
+var BentoRepositoryMemberController = bentoRepositoryMemberController{}
+
+type CreateBentoRepositoryMembersSchema struct {
+	schemasv1.CreateMembersSchema
+	GetBentoRepositorySchema
+}
+
+func (c *bentoRepositoryMemberController) Create(ctx *gin.Context, schema *CreateBentoRepositoryMembersSchema) ([]*transformersv1.RepositoryMemberSchema, error) {
+	currentUser, err := services.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "get current user")
+	}
+	bentoRepository, err := schema.GetBentoRepository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canOperate(ctx, bentoRepository); err != nil {
+		return nil, err
+	}
+	users, err := services.UserService.ListByNames(ctx, schema.Usernames)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*transformersv1.RepositoryMemberSchema, 0, len(users))
+	for _, u := range users {
+		member, err := services.BentoRepositoryMemberService.Create(ctx, currentUser.ID, services.CreateRepositoryMemberOption{
+			CreatorId:    currentUser.ID,
+			UserId:       u.ID,
+			RepositoryId: bentoRepository.ID,
+			Role:         schema.Role,
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "create bentoRepository member")
+		}
+		s, err := transformersv1.ToRepositoryMemberSchema(ctx, member)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, s)
+	}
+	return res, nil
+}
+
+func (c *bentoRepositoryMemberController) List(ctx *gin.Context, schema *GetBentoRepositorySchema) ([]*transformersv1.RepositoryMemberSchema, error) {
+	bentoRepository, err := schema.GetBentoRepository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canView(ctx, bentoRepository); err != nil {
+		return nil, err
+	}
+	members, err := services.BentoRepositoryMemberService.List(ctx, services.ListRepositoryMemberOption{
+		RepositoryId: utils.UintPtr(bentoRepository.ID),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "list bentoRepository members")
+	}
+	return transformersv1.ToRepositoryMemberSchemas(ctx, members)
+}
+
+type DeleteBentoRepositoryMemberSchema struct {
+	schemasv1.DeleteMemberSchema
+	GetBentoRepositorySchema
+}
+
+func (c *bentoRepositoryMemberController) Delete(ctx *gin.Context, schema *DeleteBentoRepositoryMemberSchema) (*transformersv1.RepositoryMemberSchema, error) {
+	currentUser, err := services.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "get current user")
+	}
+	bentoRepository, err := schema.GetBentoRepository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canOperate(ctx, bentoRepository); err != nil {
+		return nil, err
+	}
+	user, err := services.UserService.GetByName(ctx, schema.Username)
+	if err != nil {
+		return nil, err
+	}
+	member, err := services.BentoRepositoryMemberService.GetBy(ctx, user.ID, bentoRepository.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "get member")
+	}
+	member, err = services.BentoRepositoryMemberService.Delete(ctx, member, currentUser.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "delete bentoRepository member")
+	}
+	return transformersv1.ToRepositoryMemberSchema(ctx, member)
+}
@@ -0,0 +1,118 @@
+package controllersv1
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+
+	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/api-server/services"
+	"github.com/bentoml/yatai/api-server/transformers/transformersv1"
+)
+
+type canaryRolloutController struct {
+	baseController
+}
+
+var CanaryRolloutController = canaryRolloutController{}
+
+func (c *canaryRolloutController) getActive(ctx context.Context, schema *GetDeploymentSchema, canAccess func(context.Context, *models.Deployment) error) (*models.DeploymentRevision, *models.DeploymentCanaryRollout, error) {
+	deployment, err := schema.GetDeployment(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = canAccess(ctx, deployment); err != nil {
+		return nil, nil, err
+	}
+	return services.CanaryRolloutService.GetActive(ctx, deployment)
+}
+
+func (c *canaryRolloutController) Get(ctx *gin.Context, schema *GetDeploymentSchema) (*transformersv1.DeploymentCanaryRolloutSchema, error) {
+	deploymentRevision, rollout, err := c.getActive(ctx, schema, DeploymentController.canView)
+	if err != nil {
+		return nil, err
+	}
+	return transformersv1.ToDeploymentCanaryRolloutSchema(deploymentRevision, rollout), nil
+}
+
+type UpdateCanaryRolloutSchema struct {
+	GetDeploymentSchema
+	Steps               *[]uint `json:"steps"`
+	StepIntervalSeconds *uint   `json:"step_interval_seconds"`
+}
+
+func (c *canaryRolloutController) Update(ctx *gin.Context, schema *UpdateCanaryRolloutSchema) (*transformersv1.DeploymentCanaryRolloutSchema, error) {
+	deploymentRevision, _, err := c.getActive(ctx, &schema.GetDeploymentSchema, DeploymentController.canUpdate)
+	if err != nil {
+		return nil, err
+	}
+	if err = DeploymentController.canViewRevisionBentos(ctx, deploymentRevision); err != nil {
+		return nil, err
+	}
+	rollout, err := services.CanaryRolloutService.Update(ctx, deploymentRevision, services.UpdateCanaryRolloutOption{
+		Steps:               schema.Steps,
+		StepIntervalSeconds: schema.StepIntervalSeconds,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "update canary rollout")
+	}
+	return transformersv1.ToDeploymentCanaryRolloutSchema(deploymentRevision, rollout), nil
+}
+
+func (c *canaryRolloutController) Pause(ctx *gin.Context, schema *GetDeploymentSchema) (*transformersv1.DeploymentCanaryRolloutSchema, error) {
+	deploymentRevision, _, err := c.getActive(ctx, schema, DeploymentController.canUpdate)
+	if err != nil {
+		return nil, err
+	}
+	rollout, err := services.CanaryRolloutService.Pause(ctx, deploymentRevision)
+	if err != nil {
+		return nil, errors.Wrap(err, "pause canary rollout")
+	}
+	return transformersv1.ToDeploymentCanaryRolloutSchema(deploymentRevision, rollout), nil
+}
+
+func (c *canaryRolloutController) Resume(ctx *gin.Context, schema *GetDeploymentSchema) (*transformersv1.DeploymentCanaryRolloutSchema, error) {
+	deploymentRevision, _, err := c.getActive(ctx, schema, DeploymentController.canUpdate)
+	if err != nil {
+		return nil, err
+	}
+	if err = DeploymentController.canViewRevisionBentos(ctx, deploymentRevision); err != nil {
+		return nil, err
+	}
+	rollout, err := services.CanaryRolloutService.Resume(ctx, deploymentRevision)
+	if err != nil {
+		return nil, errors.Wrap(err, "resume canary rollout")
+	}
+	return transformersv1.ToDeploymentCanaryRolloutSchema(deploymentRevision, rollout), nil
+}
+
+func (c *canaryRolloutController) Promote(ctx *gin.Context, schema *GetDeploymentSchema) (*transformersv1.DeploymentCanaryRolloutSchema, error) {
+	deploymentRevision, _, err := c.getActive(ctx, schema, DeploymentController.canUpdate)
+	if err != nil {
+		return nil, err
+	}
+	if err = DeploymentController.canViewRevisionBentos(ctx, deploymentRevision); err != nil {
+		return nil, err
+	}
+	_, err = services.CanaryRolloutService.Promote(ctx, deploymentRevision)
+	if err != nil {
+		return nil, errors.Wrap(err, "promote canary")
+	}
+	return transformersv1.ToDeploymentCanaryRolloutSchema(deploymentRevision, deploymentRevision.Info.CanaryRollout), nil
+}
+
+func (c *canaryRolloutController) Abort(ctx *gin.Context, schema *GetDeploymentSchema) (*transformersv1.DeploymentCanaryRolloutSchema, error) {
+	deploymentRevision, _, err := c.getActive(ctx, schema, DeploymentController.canUpdate)
+	if err != nil {
+		return nil, err
+	}
+	if err = DeploymentController.canViewRevisionBentos(ctx, deploymentRevision); err != nil {
+		return nil, err
+	}
+	_, err = services.CanaryRolloutService.Abort(ctx, deploymentRevision)
+	if err != nil {
+		return nil, errors.Wrap(err, "abort canary")
+	}
+	return transformersv1.ToDeploymentCanaryRolloutSchema(deploymentRevision, deploymentRevision.Info.CanaryRollout), nil
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/bentoml/yatai/api-server/models"
 	"github.com/bentoml/yatai/api-server/services"
 	"github.com/bentoml/yatai/api-server/transformers/transformersv1"
+	"github.com/bentoml/yatai/common/metrics"
 	"github.com/bentoml/yatai/common/utils"
 )
 
@@ -46,28 +47,28 @@ func (s *GetClusterSchema) GetCluster(ctx context.Context) (*models.Cluster, err
 	return cluster, nil
 }
 
-func (c *clusterController) canView(ctx context.Context, cluster *models.Cluster) error {
+func (c *clusterController) canView(ctx context.Context, cluster *models.Cluster, targets ...models.IResource) error {
 	user, err := services.GetCurrentUser(ctx)
 	if err != nil {
 		return err
 	}
-	return services.MemberService.CanView(ctx, &services.ClusterMemberService, user, cluster.ID)
+	return services.MemberService.CanView(ctx, &services.ClusterMemberService, user, cluster.ID, targets...)
 }
 
-func (c *clusterController) canUpdate(ctx context.Context, cluster *models.Cluster) error {
+func (c *clusterController) canUpdate(ctx context.Context, cluster *models.Cluster, targets ...models.IResource) error {
 	user, err := services.GetCurrentUser(ctx)
 	if err != nil {
 		return err
 	}
-	return services.MemberService.CanUpdate(ctx, &services.ClusterMemberService, user, cluster.ID)
+	return services.MemberService.CanUpdate(ctx, &services.ClusterMemberService, user, cluster.ID, targets...)
 }
 
-func (c *clusterController) canOperate(ctx context.Context, cluster *models.Cluster) error {
+func (c *clusterController) canOperate(ctx context.Context, cluster *models.Cluster, targets ...models.IResource) error {
 	user, err := services.GetCurrentUser(ctx)
 	if err != nil {
 		return err
 	}
-	return services.MemberService.CanOperate(ctx, &services.ClusterMemberService, user, cluster.ID)
+	return services.MemberService.CanOperate(ctx, &services.ClusterMemberService, user, cluster.ID, targets...)
 }
 
 type CreateClusterSchema struct {
@@ -193,6 +194,8 @@ func (c *clusterController) WsPods(ctx *gin.Context, schema *GetClusterSchema) (
 		return
 	}
 	defer conn.Close()
+	wsCtx, done := trackWebsocketConnection(ctx, conn, metrics.WebsocketTypeSubscription)
+	defer done()
 
 	defer func() {
 		writeWsError(conn, err)
@@ -224,7 +227,7 @@ func (c *clusterController) WsPods(ctx *gin.Context, schema *GetClusterSchema) (
 		return
 	}
 
-	pollingCtx, cancel := context.WithCancel(ctx)
+	pollingCtx, cancel := context.WithCancel(wsCtx)
 	defer cancel()
 
 	go func() {
@@ -16,6 +16,7 @@ import (
 	"go.uber.org/atomic"
 	apiv1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
 	v1 "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
 
@@ -25,6 +26,7 @@ import (
 	"github.com/bentoml/yatai/api-server/models"
 	"github.com/bentoml/yatai/api-server/services"
 	"github.com/bentoml/yatai/api-server/transformers/transformersv1"
+	"github.com/bentoml/yatai/common/metrics"
 	"github.com/bentoml/yatai/common/sync/errsgroup"
 	"github.com/bentoml/yatai/common/utils"
 )
@@ -58,7 +60,7 @@ func (c *deploymentController) canView(ctx context.Context, deployment *models.D
 	if err != nil {
 		return errors.Wrap(err, "get associated cluster")
 	}
-	return ClusterController.canView(ctx, cluster)
+	return ClusterController.canView(ctx, cluster, deployment)
 }
 
 func (c *deploymentController) canUpdate(ctx context.Context, deployment *models.Deployment) error {
@@ -66,7 +68,7 @@ func (c *deploymentController) canUpdate(ctx context.Context, deployment *models
 	if err != nil {
 		return errors.Wrap(err, "get associated cluster")
 	}
-	return ClusterController.canUpdate(ctx, cluster)
+	return ClusterController.canUpdate(ctx, cluster, deployment)
 }
 
 func (c *deploymentController) canOperate(ctx context.Context, deployment *models.Deployment) error {
@@ -74,7 +76,7 @@ func (c *deploymentController) canOperate(ctx context.Context, deployment *model
 	if err != nil {
 		return errors.Wrap(err, "get associated cluster")
 	}
-	return ClusterController.canOperate(ctx, cluster)
+	return ClusterController.canOperate(ctx, cluster, deployment)
 }
 
 type CreateDeploymentSchema struct {
@@ -82,6 +84,30 @@ type CreateDeploymentSchema struct {
 	GetClusterSchema
 }
 
+// canViewRevisionBentos checks the bento repositories of the revision which is deployed again by a rollback or canary step
+func (c *deploymentController) canViewRevisionBentos(ctx context.Context, deploymentRevision *models.DeploymentRevision) error {
+	deploymentTargets, _, err := services.DeploymentTargetService.List(ctx, services.ListDeploymentTargetOption{
+		DeploymentRevisionId: utils.UintPtr(deploymentRevision.ID),
+	})
+	if err != nil {
+		return errors.Wrap(err, "list deployment targets")
+	}
+	for _, deploymentTarget := range deploymentTargets {
+		bento, err := services.BentoService.GetAssociatedBento(ctx, deploymentTarget)
+		if err != nil {
+			return errors.Wrap(err, "get deployment target associated bento")
+		}
+		bentoRepository, err := services.BentoRepositoryService.GetAssociatedBentoRepository(ctx, bento)
+		if err != nil {
+			return errors.Wrap(err, "get bento associated bento repository")
+		}
+		if err = BentoRepositoryController.canView(ctx, bentoRepository); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (c *deploymentController) Create(ctx *gin.Context, schema *CreateDeploymentSchema) (*schemasv1.DeploymentSchema, error) {
 	user, err := services.GetCurrentUser(ctx)
 	if err != nil {
@@ -157,7 +183,7 @@ func (c *deploymentController) Create(ctx *gin.Context, schema *CreateDeployment
 		}
 	}()
 
-	deploymentSchema, err := c.doUpdate(ctx_, schema.UpdateDeploymentSchema, org, deployment)
+	deploymentSchema, _, err := c.doUpdate(ctx_, schema.UpdateDeploymentSchema, org, deployment, false)
 
 	return deploymentSchema, err
 }
@@ -165,6 +191,7 @@ func (c *deploymentController) Create(ctx *gin.Context, schema *CreateDeployment
 type UpdateDeploymentSchema struct {
 	schemasv1.UpdateDeploymentSchema
 	GetDeploymentSchema
+	DryRun bool `json:"dry_run"`
 }
 
 func (c *deploymentController) SyncStatus(ctx *gin.Context, schema *UpdateDeploymentSchema) (*schemasv1.DeploymentSchema, error) {
@@ -186,7 +213,7 @@ func (c *deploymentController) SyncStatus(ctx *gin.Context, schema *UpdateDeploy
 	return transformersv1.ToDeploymentSchema(ctx, deployment)
 }
 
-func (c *deploymentController) Update(ctx *gin.Context, schema *UpdateDeploymentSchema) (*schemasv1.DeploymentSchema, error) {
+func (c *deploymentController) Update(ctx *gin.Context, schema *UpdateDeploymentSchema) (*transformersv1.DeploymentWithDryRunSchema, error) {
 	deployment, err := schema.GetDeployment(ctx)
 	if err != nil {
 		return nil, err
@@ -204,7 +231,14 @@ func (c *deploymentController) Update(ctx *gin.Context, schema *UpdateDeployment
 	if err != nil {
 		return nil, err
 	}
-	defer func() { df(err) }()
+	defer func() {
+		if schema.DryRun && err == nil {
+			// a dry run never persists anything
+			df(errors.New("deployment dry run"))
+			return
+		}
+		df(err)
+	}()
 
 	deployment, err = services.DeploymentService.Update(ctx_, deployment, services.UpdateDeploymentOption{
 		Description: schema.Description,
@@ -214,14 +248,26 @@ func (c *deploymentController) Update(ctx *gin.Context, schema *UpdateDeployment
 		return nil, err
 	}
 
-	deploymentSchema, err := c.doUpdate(ctx_, schema.UpdateDeploymentSchema, org, deployment)
-	return deploymentSchema, err
+	deploymentSchema, dryRunResults, err := c.doUpdate(ctx_, schema.UpdateDeploymentSchema, org, deployment, schema.DryRun)
+	if err != nil {
+		return nil, err
+	}
+	res := &transformersv1.DeploymentWithDryRunSchema{
+		DeploymentSchema: *deploymentSchema,
+	}
+	if schema.DryRun {
+		res.DryRun, err = transformersv1.ToDeploymentDryRunSchema(ctx_, dryRunResults)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
 }
 
-func (c *deploymentController) doUpdate(ctx context.Context, schema schemasv1.UpdateDeploymentSchema, org *models.Organization, deployment *models.Deployment) (*schemasv1.DeploymentSchema, error) {
+func (c *deploymentController) doUpdate(ctx context.Context, schema schemasv1.UpdateDeploymentSchema, org *models.Organization, deployment *models.Deployment, dryRun bool) (*schemasv1.DeploymentSchema, []*services.KubeBentoDeploymentDryRunResult, error) {
 	user, err := services.GetCurrentUser(ctx)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	bentoRepositoryNames := make([]string, 0, len(schema.Targets))
 	bentoRepositoryNamesSeen := make(map[string]struct{}, len(schema.Targets))
@@ -246,10 +292,14 @@ func (c *deploymentController) doUpdate(ctx context.Context, schema schemasv1.Up
 		Names:          &bentoRepositoryNames,
 	})
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	bentoRepositoriesMapping := make(map[string]*models.BentoRepository, len(bentoRepositories))
 	for _, bentoRepository := range bentoRepositories {
+		// the private bento repositories can only be deployed by their members
+		if err = BentoRepositoryController.canView(ctx, bentoRepository); err != nil {
+			return nil, nil, err
+		}
 		bentoRepositoriesMapping[bentoRepository.Name] = bentoRepository
 	}
 
@@ -261,11 +311,25 @@ func (c *deploymentController) doUpdate(ctx context.Context, schema schemasv1.Up
 			Versions:          &versions,
 		})
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		for _, bento := range bentos {
 			bentosMapping[fmt.Sprintf("%s:%s", bentoRepository.Name, bento.Version)] = bento
 		}
+		for _, version := range versions {
+			key := fmt.Sprintf("%s:%s", bentoRepository.Name, version)
+			if _, ok := bentosMapping[key]; ok {
+				continue
+			}
+			bento, err := services.BentoService.GetByVersionOrAlias(ctx, bentoRepository.ID, version)
+			if err != nil {
+				if utils.IsNotFound(err) {
+					continue
+				}
+				return nil, nil, err
+			}
+			bentosMapping[key] = bento
+		}
 	}
 
 	status_ := modelschemas.DeploymentRevisionStatusActive
@@ -274,7 +338,7 @@ func (c *deploymentController) doUpdate(ctx context.Context, schema schemasv1.Up
 		Status:       &status_,
 	})
 	if err != nil {
-		return nil, errors.Wrap(err, "list deployment revisions")
+		return nil, nil, errors.Wrap(err, "list deployment revisions")
 	}
 
 	if schema.DoNotDeploy {
@@ -283,13 +347,13 @@ func (c *deploymentController) doUpdate(ctx context.Context, schema schemasv1.Up
 				DeploymentRevisionId: utils.UintPtr(deploymentRevision.ID),
 			})
 			if err != nil {
-				return nil, errors.Wrap(err, "list deployment targets")
+				return nil, nil, errors.Wrap(err, "list deployment targets")
 			}
 			for _, deploymentTarget := range deploymentTargets {
 				for _, createDeploymentTargetSchema := range schema.Targets {
 					bento := bentosMapping[fmt.Sprintf("%s:%s", createDeploymentTargetSchema.BentoRepository, createDeploymentTargetSchema.Bento)]
 					if bento == nil {
-						return nil, errors.Errorf("can't find bento: %s:%s", createDeploymentTargetSchema.BentoRepository, createDeploymentTargetSchema.Bento)
+						return nil, nil, errors.Errorf("can't find bento: %s:%s", createDeploymentTargetSchema.BentoRepository, createDeploymentTargetSchema.Bento)
 					}
 					if deploymentTarget.BentoId != bento.ID {
 						continue
@@ -315,9 +379,10 @@ func (c *deploymentController) doUpdate(ctx context.Context, schema schemasv1.Up
 						Config: &config,
 					})
 					if err != nil {
-						return nil, errors.Wrap(err, "update deployment target")
+						return nil, nil, errors.Wrap(err, "update deployment target")
 					}
-					return transformersv1.ToDeploymentSchema(ctx, deployment)
+					deploymentSchema, err := transformersv1.ToDeploymentSchema(ctx, deployment)
+					return deploymentSchema, nil, err
 				}
 			}
 		}
@@ -336,14 +401,14 @@ func (c *deploymentController) doUpdate(ctx context.Context, schema schemasv1.Up
 		Status:       modelschemas.DeploymentRevisionStatusActive,
 	})
 	if err != nil {
-		return nil, errors.Wrap(err, "create deployment revision")
+		return nil, nil, errors.Wrap(err, "create deployment revision")
 	}
 
 	deploymentTargets := make([]*models.DeploymentTarget, 0, len(schema.Targets))
 	for _, createDeploymentTargetSchema := range schema.Targets {
 		bento := bentosMapping[fmt.Sprintf("%s:%s", createDeploymentTargetSchema.BentoRepository, createDeploymentTargetSchema.Bento)]
 		if bento == nil {
-			return nil, errors.Errorf("can't find bento: %s:%s", createDeploymentTargetSchema.BentoRepository, createDeploymentTargetSchema.Bento)
+			return nil, nil, errors.Errorf("can't find bento: %s:%s", createDeploymentTargetSchema.BentoRepository, createDeploymentTargetSchema.Bento)
 		}
 
 		deploymentTarget, err := services.DeploymentTargetService.Create(ctx, services.CreateDeploymentTargetOption{
@@ -356,15 +421,21 @@ func (c *deploymentController) doUpdate(ctx context.Context, schema schemasv1.Up
 			Config:               createDeploymentTargetSchema.Config,
 		})
 		if err != nil {
-			return nil, errors.Wrap(err, "create deployment target")
+			return nil, nil, errors.Wrap(err, "create deployment target")
 		}
 		deploymentTargets = append(deploymentTargets, deploymentTarget)
 	}
 
-	if !schema.DoNotDeploy {
+	var dryRunResults []*services.KubeBentoDeploymentDryRunResult
+	if dryRun {
+		dryRunResults, err = services.DeploymentRevisionService.DryRun(ctx, deploymentRevision, deploymentTargets)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "dry run deployment revision")
+		}
+	} else if !schema.DoNotDeploy {
 		err = services.DeploymentRevisionService.Deploy(ctx, deploymentRevision, deploymentTargets, false)
 		if err != nil {
-			return nil, errors.Wrap(err, "deploy deployment revision")
+			return nil, nil, errors.Wrap(err, "deploy deployment revision")
 		}
 	} else {
 		for _, oldDeploymentRevision := range deploymentRevisions {
@@ -375,12 +446,13 @@ func (c *deploymentController) doUpdate(ctx context.Context, schema schemasv1.Up
 				Status: modelschemas.DeploymentRevisionStatusPtr(modelschemas.DeploymentRevisionStatusInactive),
 			})
 			if err != nil {
-				return nil, errors.Wrap(err, "update deployment revision")
+				return nil, nil, errors.Wrap(err, "update deployment revision")
 			}
 		}
 	}
 
-	return transformersv1.ToDeploymentSchema(ctx, deployment)
+	deploymentSchema, err := transformersv1.ToDeploymentSchema(ctx, deployment)
+	return deploymentSchema, dryRunResults, err
 }
 
 func (c *deploymentController) Get(ctx *gin.Context, schema *GetDeploymentSchema) (*schemasv1.DeploymentSchema, error) {
@@ -424,6 +496,130 @@ func (c *deploymentController) Delete(ctx *gin.Context, schema *GetDeploymentSch
 	return transformersv1.ToDeploymentSchema(ctx, deployment)
 }
 
+func (c *deploymentController) GetFollowPolicy(ctx *gin.Context, schema *GetDeploymentSchema) (*transformersv1.DeploymentFollowPolicySchema, error) {
+	deployment, err := schema.GetDeployment(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canView(ctx, deployment); err != nil {
+		return nil, err
+	}
+	return transformersv1.ToDeploymentFollowPolicySchema(deployment), nil
+}
+
+type UpdateDeploymentFollowPolicySchema struct {
+	models.DeploymentFollowPolicy
+	GetDeploymentSchema
+}
+
+func (c *deploymentController) UpdateFollowPolicy(ctx *gin.Context, schema *UpdateDeploymentFollowPolicySchema) (*transformersv1.DeploymentFollowPolicySchema, error) {
+	deployment, err := schema.GetDeployment(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canUpdate(ctx, deployment); err != nil {
+		return nil, err
+	}
+	if schema.Alias != "" {
+		errs := validation.IsDNS1123Label(schema.Alias)
+		if len(errs) > 0 {
+			return nil, errors.New(strings.Join(errs, ";"))
+		}
+	}
+	followPolicy := &schema.DeploymentFollowPolicy
+	deployment, err = services.DeploymentService.Update(ctx, deployment, services.UpdateDeploymentOption{
+		FollowPolicy: &followPolicy,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "update deployment follow policy")
+	}
+	services.DeploymentFollowService.TriggerByDeployment(deployment)
+	return transformersv1.ToDeploymentFollowPolicySchema(deployment), nil
+}
+
+func (c *deploymentController) DeleteFollowPolicy(ctx *gin.Context, schema *GetDeploymentSchema) (*transformersv1.DeploymentFollowPolicySchema, error) {
+	deployment, err := schema.GetDeployment(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canUpdate(ctx, deployment); err != nil {
+		return nil, err
+	}
+	var followPolicy *models.DeploymentFollowPolicy
+	deployment, err = services.DeploymentService.Update(ctx, deployment, services.UpdateDeploymentOption{
+		FollowPolicy: &followPolicy,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "delete deployment follow policy")
+	}
+	return transformersv1.ToDeploymentFollowPolicySchema(deployment), nil
+}
+
+func (c *deploymentController) GetRollbackPolicy(ctx *gin.Context, schema *GetDeploymentSchema) (*transformersv1.DeploymentRollbackPolicySchema, error) {
+	deployment, err := schema.GetDeployment(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canView(ctx, deployment); err != nil {
+		return nil, err
+	}
+	return transformersv1.ToDeploymentRollbackPolicySchema(deployment), nil
+}
+
+type UpdateDeploymentRollbackPolicySchema struct {
+	models.DeploymentRollbackPolicy
+	GetDeploymentSchema
+}
+
+func (c *deploymentController) UpdateRollbackPolicy(ctx *gin.Context, schema *UpdateDeploymentRollbackPolicySchema) (*transformersv1.DeploymentRollbackPolicySchema, error) {
+	deployment, err := schema.GetDeployment(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canUpdate(ctx, deployment); err != nil {
+		return nil, err
+	}
+	rollbackPolicy := &schema.DeploymentRollbackPolicy
+	deployment, err = services.DeploymentService.Update(ctx, deployment, services.UpdateDeploymentOption{
+		RollbackPolicy: &rollbackPolicy,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "update deployment rollback policy")
+	}
+	return transformersv1.ToDeploymentRollbackPolicySchema(deployment), nil
+}
+
+type ListDeploymentTimelineSchema struct {
+	schemasv1.ListQuerySchema
+	GetDeploymentSchema
+}
+
+func (c *deploymentController) ListTimeline(ctx *gin.Context, schema *ListDeploymentTimelineSchema) (*transformersv1.DeploymentTimelineSchema, error) {
+	deployment, err := schema.GetDeployment(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canView(ctx, deployment); err != nil {
+		return nil, err
+	}
+	items, total, err := services.DeploymentStatusHistoryService.ListTimeline(ctx, deployment, services.BaseListOption{
+		Start: utils.UintPtr(schema.Start),
+		Count: utils.UintPtr(schema.Count),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "list deployment timeline")
+	}
+	itemSchemas, err := transformersv1.ToDeploymentTimelineItemSchemas(ctx, items)
+	return &transformersv1.DeploymentTimelineSchema{
+		BaseListSchema: schemasv1.BaseListSchema{
+			Total: total,
+			Start: schema.Start,
+			Count: schema.Count,
+		},
+		Items: itemSchemas,
+	}, err
+}
+
 type ListClusterDeploymentSchema struct {
 	schemasv1.ListQuerySchema
 	GetClusterSchema
@@ -719,6 +915,8 @@ func (c *deploymentController) WsPods(ctx *gin.Context, schema *GetDeploymentSch
 		return err
 	}
 	defer conn.Close()
+	wsCtx, done := trackWebsocketConnection(ctx, conn, metrics.WebsocketTypeSubscription)
+	defer done()
 
 	defer func() {
 		writeWsError(conn, err)
@@ -792,7 +990,7 @@ func (c *deploymentController) WsPods(ctx *gin.Context, schema *GetDeploymentSch
 	}
 	connW.IsNew = false
 
-	pollingCtx, cancel := context.WithCancel(ctx)
+	pollingCtx, cancel := context.WithCancel(wsCtx)
 	defer cancel()
 
 	go func() {
@@ -1,10 +1,13 @@
 package controllersv1
 
 import (
+	"context"
+
 	"github.com/gin-gonic/gin"
 	"github.com/pkg/errors"
 
 	"github.com/bentoml/yatai-schemas/schemasv1"
+	"github.com/bentoml/yatai/api-server/models"
 	"github.com/bentoml/yatai/api-server/services"
 	"github.com/bentoml/yatai/api-server/transformers/transformersv1"
 	"github.com/bentoml/yatai/common/utils"
@@ -59,8 +62,32 @@ type GetDeploymentRevisionSchema struct {
 	RevisionUid string `path:"revisionUid"`
 }
 
-func (c *deploymentRevisionController) Get(ctx *gin.Context, schema *GetDeploymentRevisionSchema) (*schemasv1.DeploymentRevisionSchema, error) {
-	deployment, err := schema.GetDeployment(ctx)
+func (s *GetDeploymentRevisionSchema) GetDeploymentRevision(ctx context.Context) (*models.DeploymentRevision, error) {
+	deployment, err := s.GetDeployment(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	deploymentRevision, err := services.DeploymentRevisionService.GetByUid(ctx, s.RevisionUid)
+	if err != nil {
+		return nil, errors.Wrap(err, "get deploymentRevision")
+	}
+
+	if deploymentRevision.DeploymentId != deployment.ID {
+		return nil, errors.New("deploymentRevision not found")
+	}
+	deploymentRevision.AssociatedDeploymentCache = deployment
+
+	return deploymentRevision, nil
+}
+
+func (c *deploymentRevisionController) Get(ctx *gin.Context, schema *GetDeploymentRevisionSchema) (*transformersv1.DeploymentRevisionWithInfoSchema, error) {
+	deploymentRevision, err := schema.GetDeploymentRevision(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	deployment, err := services.DeploymentService.GetAssociatedDeployment(ctx, deploymentRevision)
 	if err != nil {
 		return nil, err
 	}
@@ -69,14 +96,76 @@ func (c *deploymentRevisionController) Get(ctx *gin.Context, schema *GetDeployme
 		return nil, err
 	}
 
-	deploymentRevision, err := services.DeploymentRevisionService.GetByUid(ctx, schema.RevisionUid)
+	return transformersv1.ToDeploymentRevisionWithInfoSchema(ctx, deploymentRevision)
+}
+
+func (c *deploymentRevisionController) Rollback(ctx *gin.Context, schema *GetDeploymentRevisionSchema) (*transformersv1.DeploymentRevisionWithInfoSchema, error) {
+	deploymentRevision, err := schema.GetDeploymentRevision(ctx)
 	if err != nil {
-		return nil, errors.Wrap(err, "get deploymentRevision")
+		return nil, err
 	}
 
-	if deploymentRevision.DeploymentId != deployment.ID {
-		return nil, errors.New("deploymentRevision not found")
+	deployment, err := services.DeploymentService.GetAssociatedDeployment(ctx, deploymentRevision)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = DeploymentController.canUpdate(ctx, deployment); err != nil {
+		return nil, err
+	}
+	if err = DeploymentController.canViewRevisionBentos(ctx, deploymentRevision); err != nil {
+		return nil, err
+	}
+
+	newDeploymentRevision, err := services.DeploymentRevisionService.Rollback(ctx, deploymentRevision)
+	if err != nil {
+		return nil, errors.Wrap(err, "rollback deploymentRevision")
+	}
+
+	return transformersv1.ToDeploymentRevisionWithInfoSchema(ctx, newDeploymentRevision)
+}
+
+type DiffDeploymentRevisionSchema struct {
+	GetDeploymentRevisionSchema
+	BaseRevisionUid *string `query:"base_revision_uid"`
+}
+
+func (c *deploymentRevisionController) Diff(ctx *gin.Context, schema *DiffDeploymentRevisionSchema) (*transformersv1.DeploymentRevisionDiffSchema, error) {
+	deploymentRevision, err := schema.GetDeploymentRevision(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	deployment, err := services.DeploymentService.GetAssociatedDeployment(ctx, deploymentRevision)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = DeploymentController.canView(ctx, deployment); err != nil {
+		return nil, err
+	}
+
+	var baseDeploymentRevision *models.DeploymentRevision
+	if schema.BaseRevisionUid != nil && *schema.BaseRevisionUid != "" {
+		baseDeploymentRevision, err = services.DeploymentRevisionService.GetByUid(ctx, *schema.BaseRevisionUid)
+		if err != nil {
+			return nil, errors.Wrap(err, "get base deploymentRevision")
+		}
+		if baseDeploymentRevision.DeploymentId != deployment.ID {
+			return nil, errors.New("base deploymentRevision not found")
+		}
+	} else {
+		baseDeploymentRevision, err = services.DeploymentRevisionService.GetPrevious(ctx, deploymentRevision)
+		if err != nil {
+			return nil, errors.Wrap(err, "get previous deploymentRevision")
+		}
+	}
+	baseDeploymentRevision.AssociatedDeploymentCache = deployment
+
+	items, err := services.DeploymentRevisionService.Diff(ctx, baseDeploymentRevision, deploymentRevision)
+	if err != nil {
+		return nil, errors.Wrap(err, "diff deploymentRevisions")
 	}
 
-	return transformersv1.ToDeploymentRevisionSchema(ctx, deploymentRevision)
+	return transformersv1.ToDeploymentRevisionDiffSchema(baseDeploymentRevision, deploymentRevision, items), nil
 }
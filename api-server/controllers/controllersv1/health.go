@@ -0,0 +1,121 @@
+package controllersv1
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"github.com/bentoml/yatai-schemas/schemasv1"
+	"github.com/bentoml/yatai/api-server/services"
+	"github.com/bentoml/yatai/common/utils"
+)
+
+const healthCheckTimeout = 5 * time.Second
+
+type healthController struct {
+	baseController
+}
+
+var HealthController = healthController{}
+
+type HealthCheckSchema struct {
+	Name     string `json:"name"`
+	Healthy  bool   `json:"healthy"`
+	Optional bool   `json:"optional,omitempty"`
+}
+
+type ReadinessSchema struct {
+	Ready  bool                 `json:"ready"`
+	Checks []*HealthCheckSchema `json:"checks"`
+}
+
+func (c *healthController) check(ctx context.Context, name string, optional bool, check func(ctx context.Context) error) *HealthCheckSchema {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+	res := &HealthCheckSchema{
+		Name:     name,
+		Healthy:  true,
+		Optional: optional,
+	}
+	// the errors may tell hosts and bucket names, they are only logged
+	if err := check(ctx); err != nil {
+		res.Healthy = false
+		logrus.Warnf("health check %s failed: %s", name, err.Error())
+	}
+	return res
+}
+
+// Healthz only tells the process is up, it is meant for liveness probes.
+func (c *healthController) Healthz(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, gin.H{
+		"healthy": true,
+	})
+}
+
+// Readyz checks the dependencies of the api server. The api servers of the clusters of the
+// current organization are checked with ?clusters=true, which requires the login, they do
+// not affect the readiness.
+func (c *healthController) Readyz(ctx *gin.Context) {
+	res := &ReadinessSchema{
+		Ready: true,
+		Checks: []*HealthCheckSchema{
+			c.check(ctx, "postgresql", false, services.HealthService.CheckDB),
+			c.check(ctx, "migration", false, services.HealthService.CheckMigration),
+			c.check(ctx, "s3", false, services.HealthService.CheckS3),
+		},
+	}
+	if ctx.Query("clusters") == "true" {
+		clusterChecks, err := c.checkClusters(ctx)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, &schemasv1.MsgSchema{Message: err.Error()})
+			return
+		}
+		res.Checks = append(res.Checks, clusterChecks...)
+	}
+	for _, check := range res.Checks {
+		if !check.Healthy && !check.Optional {
+			res.Ready = false
+		}
+	}
+	status := http.StatusOK
+	if !res.Ready {
+		status = http.StatusServiceUnavailable
+	}
+	ctx.JSON(status, res)
+}
+
+// checkClusters checks the clusters in parallel, so a slow cluster does not hold up the others.
+func (c *healthController) checkClusters(ctx *gin.Context) ([]*HealthCheckSchema, error) {
+	org, err := services.GetCurrentOrganization(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = OrganizationController.canView(ctx, org); err != nil {
+		return nil, err
+	}
+	clusters, _, err := services.ClusterService.List(ctx, services.ListClusterOption{
+		OrganizationId: utils.UintPtr(org.ID),
+	})
+	if err != nil {
+		logrus.Warnf("health check clusters failed: %s", err.Error())
+		return []*HealthCheckSchema{{Name: "clusters", Optional: true}}, nil
+	}
+	res := make([]*HealthCheckSchema, len(clusters))
+	var wg sync.WaitGroup
+	for idx, cluster := range clusters {
+		idx, cluster := idx, cluster
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res[idx] = c.check(ctx, "cluster:"+cluster.Name, true, func(ctx context.Context) error {
+				return services.HealthService.CheckCluster(ctx, cluster)
+			})
+		}()
+	}
+	wg.Wait()
+	return res, nil
+}
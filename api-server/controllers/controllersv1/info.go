@@ -15,11 +15,13 @@ var InfoController = infoController{}
 type InfoSchema struct {
 	IsSaas           bool   `json:"is_saas"`
 	SaasDomainSuffix string `json:"saas_domain_suffix"`
+	IsOidcEnabled    bool   `json:"is_oidc_enabled"`
 }
 
 func (c *infoController) GetInfo(ctx *gin.Context) (*InfoSchema, error) {
 	return &InfoSchema{
 		IsSaas:           config.YataiConfig.IsSaaS,
 		SaasDomainSuffix: config.YataiConfig.SaasDomainSuffix,
+		IsOidcEnabled:    config.YataiConfig.OIDC != nil,
 	}, nil
 }
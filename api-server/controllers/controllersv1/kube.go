@@ -22,6 +22,7 @@ import (
 	"github.com/bentoml/yatai-common/consts"
 	"github.com/bentoml/yatai-schemas/schemasv1"
 	"github.com/bentoml/yatai/api-server/services"
+	"github.com/bentoml/yatai/common/metrics"
 )
 
 type kubeController struct {
@@ -40,6 +41,8 @@ func (c *kubeController) GetPodKubeEvents(ctx *gin.Context, schema *GetClusterSc
 		return err
 	}
 	defer conn.Close()
+	wsCtx, done := trackWebsocketConnection(ctx, conn, metrics.WebsocketTypeSubscription)
+	defer done()
 
 	defer func() {
 		writeWsError(conn, err)
@@ -60,7 +63,7 @@ func (c *kubeController) GetPodKubeEvents(ctx *gin.Context, schema *GetClusterSc
 
 	go func() {
 		select {
-		case <-ctx.Done():
+		case <-wsCtx.Done():
 			close(closeCh)
 		case <-toClose:
 			close(closeCh)
@@ -256,6 +259,8 @@ func (c *kubeController) GetDeploymentKubeEvents(ctx *gin.Context, schema *GetDe
 		return err
 	}
 	defer conn.Close()
+	wsCtx, done := trackWebsocketConnection(ctx, conn, metrics.WebsocketTypeSubscription)
+	defer done()
 
 	defer func() {
 		writeWsError(conn, err)
@@ -276,7 +281,7 @@ func (c *kubeController) GetDeploymentKubeEvents(ctx *gin.Context, schema *GetDe
 
 	go func() {
 		select {
-		case <-ctx.Done():
+		case <-wsCtx.Done():
 			close(closeCh)
 		case <-toClose:
 			close(closeCh)
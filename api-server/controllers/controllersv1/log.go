@@ -23,6 +23,7 @@ import (
 	commonconsts "github.com/bentoml/yatai-common/consts"
 	"github.com/bentoml/yatai-schemas/schemasv1"
 	"github.com/bentoml/yatai/api-server/services"
+	"github.com/bentoml/yatai/common/metrics"
 )
 
 type logMessageType string
@@ -305,6 +306,8 @@ func (c *logController) TailDeploymentPodLog(ctx *gin.Context, schema *GetDeploy
 		return err
 	}
 	defer conn.Close()
+	wsCtx, done := trackWebsocketConnection(ctx, conn, metrics.WebsocketTypeTail)
+	defer done()
 
 	defer func() {
 		writeWsError(conn, err)
@@ -355,7 +358,7 @@ func (c *logController) TailDeploymentPodLog(ctx *gin.Context, schema *GetDeploy
 
 	t := NewTail(conn, kubeNs, podNames, containerName, true, false)
 
-	err = t.Start(ctx, cliset)
+	err = t.Start(wsCtx, cliset)
 	return err
 }
 
@@ -369,6 +372,8 @@ func (c *logController) TailClusterPodLog(ctx *gin.Context, schema *GetClusterSc
 		return err
 	}
 	defer conn.Close()
+	wsCtx, done := trackWebsocketConnection(ctx, conn, metrics.WebsocketTypeTail)
+	defer done()
 
 	defer func() {
 		writeWsError(conn, err)
@@ -414,6 +419,6 @@ func (c *logController) TailClusterPodLog(ctx *gin.Context, schema *GetClusterSc
 
 	t := NewTail(conn, kubeNs, podNames, containerName, true, false)
 
-	err = t.Start(ctx, cliset)
+	err = t.Start(wsCtx, cliset)
 	return err
 }
@@ -35,7 +35,7 @@ func (s *GetModelSchema) GetModel(ctx context.Context) (*models.Model, error) {
 	if err != nil {
 		return nil, errors.Wrapf(err, "get modelRepository %s", s.ModelRepositoryName)
 	}
-	model, err := services.ModelService.GetByVersion(ctx, modelRepository.ID, s.Version)
+	model, err := services.ModelService.GetByVersionOrAlias(ctx, modelRepository.ID, s.Version)
 	if err != nil {
 		return nil, errors.Wrapf(err, "get modelRepository %s model %s", modelRepository.Name, s.Version)
 	}
@@ -397,6 +397,21 @@ func (c *modelController) Update(ctx *gin.Context, schema *UpdateModelSchema) (*
 	return transformersv1.ToModelSchema(ctx, model)
 }
 
+func (c *modelController) Delete(ctx *gin.Context, schema *GetModelSchema) (*schemasv1.ModelSchema, error) {
+	model, err := schema.GetModel(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canOperate(ctx, model); err != nil {
+		return nil, err
+	}
+	model, err = services.ModelService.Delete(ctx, model)
+	if err != nil {
+		return nil, errors.Wrap(err, "delete model")
+	}
+	return transformersv1.ToModelSchema(ctx, model)
+}
+
 func (c *modelController) StartUpload(ctx *gin.Context, schema *GetModelSchema) (*schemasv1.ModelSchema, error) {
 	model, err := schema.GetModel(ctx)
 	if err != nil {
@@ -500,7 +515,7 @@ func (c *modelController) ListImageBuilderPods(ctx *gin.Context, schema *GetMode
 	return transformersv1.ToKubePodSchemas(ctx, majorCluster.ID, pods)
 }
 
-func (c *modelController) Get(ctx *gin.Context, schema *GetModelSchema) (*schemasv1.ModelFullSchema, error) {
+func (c *modelController) Get(ctx *gin.Context, schema *GetModelSchema) (*transformersv1.ModelFullWithAliasesSchema, error) {
 	model, err := schema.GetModel(ctx)
 	if err != nil {
 		return nil, err
@@ -508,7 +523,7 @@ func (c *modelController) Get(ctx *gin.Context, schema *GetModelSchema) (*schema
 	if err = c.canView(ctx, model); err != nil {
 		return nil, err
 	}
-	return transformersv1.ToModelFullSchema(ctx, model)
+	return transformersv1.ToModelFullWithAliasesSchema(ctx, model)
 }
 
 type ListModelDeploymentSchema struct {
@@ -572,13 +587,23 @@ func (c *modelController) ListBento(ctx *gin.Context, schema *ListModelBentoSche
 	if err = c.canView(ctx, model); err != nil {
 		return nil, err
 	}
+	currentUser, err := services.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	modelRepository, err := services.ModelRepositoryService.GetAssociatedModelRepository(ctx, model)
+	if err != nil {
+		return nil, errors.Wrap(err, "get associated modelRepository")
+	}
 	bentos, total, err := services.BentoService.List(ctx, services.ListBentoOption{
 		BaseListOption: services.BaseListOption{
 			Start:  &schema.Start,
 			Count:  &schema.Count,
 			Search: schema.Search,
 		},
-		ModelIds: &[]uint{model.ID},
+		ModelIds:       &[]uint{model.ID},
+		OrganizationId: utils.UintPtr(modelRepository.OrganizationId),
+		VisitorId:      utils.UintPtr(currentUser.ID),
 	})
 	if err != nil {
 		return nil, err
@@ -649,6 +674,11 @@ func (c *modelController) ListAll(ctx *gin.Context, schema *ListAllModelSchema)
 		return nil, err
 	}
 
+	currentUser, err := services.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	listOpt := services.ListModelOption{
 		BaseListOption: services.BaseListOption{
 			Start:  utils.UintPtr(schema.Start),
@@ -656,6 +686,7 @@ func (c *modelController) ListAll(ctx *gin.Context, schema *ListAllModelSchema)
 			Search: schema.Search,
 		},
 		OrganizationId: utils.UintPtr(organization.ID),
+		VisitorId:      utils.UintPtr(currentUser.ID),
 	}
 
 	queryMap := schema.Q.ToMap()
@@ -0,0 +1,108 @@
+package controllersv1
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+
+	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/api-server/services"
+	"github.com/bentoml/yatai/api-server/transformers/transformersv1"
+)
+
+type modelAliasController struct {
+	baseController
+}
+
+var ModelAliasController = modelAliasController{}
+
+type GetModelAliasSchema struct {
+	GetModelRepositorySchema
+	AliasName string `path:"aliasName"`
+}
+
+func (s *GetModelAliasSchema) GetModelAlias(ctx context.Context) (*models.ModelAlias, error) {
+	modelRepository, err := s.GetModelRepository(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "get modelRepository %s", s.ModelRepositoryName)
+	}
+	modelAlias, err := services.ModelAliasService.GetByName(ctx, modelRepository.ID, s.AliasName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "get modelRepository %s alias %s", modelRepository.Name, s.AliasName)
+	}
+	return modelAlias, nil
+}
+
+func (c *modelAliasController) List(ctx *gin.Context, schema *GetModelRepositorySchema) ([]*transformersv1.ModelAliasSchema, error) {
+	modelRepository, err := schema.GetModelRepository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = ModelRepositoryController.canView(ctx, modelRepository); err != nil {
+		return nil, err
+	}
+	modelAliases, err := services.ModelAliasService.List(ctx, services.ListModelAliasOption{
+		ModelRepositoryId: &modelRepository.ID,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "list model aliases")
+	}
+	return transformersv1.ToModelAliasSchemas(ctx, modelAliases)
+}
+
+type SetModelAliasSchema struct {
+	GetModelAliasSchema
+	Version string `json:"version"`
+}
+
+func (c *modelAliasController) Set(ctx *gin.Context, schema *SetModelAliasSchema) (*transformersv1.ModelAliasSchema, error) {
+	user, err := services.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	modelRepository, err := schema.GetModelRepository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = ModelRepositoryController.canUpdate(ctx, modelRepository); err != nil {
+		return nil, err
+	}
+	model, err := services.ModelService.GetByVersionOrAlias(ctx, modelRepository.ID, schema.Version)
+	if err != nil {
+		return nil, errors.Wrapf(err, "get modelRepository %s model %s", modelRepository.Name, schema.Version)
+	}
+	modelAlias, err := services.ModelAliasService.Set(ctx, services.SetModelAliasOption{
+		CreatorId:         user.ID,
+		ModelRepositoryId: modelRepository.ID,
+		ModelId:           model.ID,
+		Name:              schema.AliasName,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "set model alias")
+	}
+	return transformersv1.ToModelAliasSchema(ctx, modelAlias)
+}
+
+func (c *modelAliasController) Delete(ctx *gin.Context, schema *GetModelAliasSchema) (*transformersv1.ModelAliasSchema, error) {
+	modelRepository, err := schema.GetModelRepository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = ModelRepositoryController.canUpdate(ctx, modelRepository); err != nil {
+		return nil, err
+	}
+	modelAlias, err := schema.GetModelAlias(ctx)
+	if err != nil {
+		return nil, err
+	}
+	modelAliasSchema, err := transformersv1.ToModelAliasSchema(ctx, modelAlias)
+	if err != nil {
+		return nil, err
+	}
+	_, err = services.ModelAliasService.Delete(ctx, modelAlias)
+	if err != nil {
+		return nil, errors.Wrap(err, "delete model alias")
+	}
+	return modelAliasSchema, nil
+}
@@ -40,35 +40,36 @@ func (s *GetModelRepositorySchema) GetModelRepository(ctx context.Context) (*mod
 }
 
 func (c *modelRepositoryController) canView(ctx context.Context, modelRepository *models.ModelRepository) error {
-	organization, err := services.OrganizationService.GetAssociatedOrganization(ctx, modelRepository)
+	user, err := services.GetCurrentUser(ctx)
 	if err != nil {
-		return errors.Wrap(err, "get associated organization")
+		return err
 	}
-	return OrganizationController.canView(ctx, organization)
+	return services.MemberService.CanView(ctx, &services.ModelRepositoryMemberService, user, modelRepository.ID, modelRepository)
 }
 
 func (c *modelRepositoryController) canUpdate(ctx context.Context, modelRepository *models.ModelRepository) error {
-	organization, err := services.OrganizationService.GetAssociatedOrganization(ctx, modelRepository)
+	user, err := services.GetCurrentUser(ctx)
 	if err != nil {
-		return errors.Wrap(err, "get associated organization")
+		return err
 	}
-	return OrganizationController.canUpdate(ctx, organization)
+	return services.MemberService.CanUpdate(ctx, &services.ModelRepositoryMemberService, user, modelRepository.ID, modelRepository)
 }
 
 func (c *modelRepositoryController) canOperate(ctx context.Context, modelRepository *models.ModelRepository) error {
-	organization, err := services.OrganizationService.GetAssociatedOrganization(ctx, modelRepository)
+	user, err := services.GetCurrentUser(ctx)
 	if err != nil {
-		return errors.Wrap(err, "get associated organization")
+		return err
 	}
-	return OrganizationController.canOperate(ctx, organization)
+	return services.MemberService.CanOperate(ctx, &services.ModelRepositoryMemberService, user, modelRepository.ID, modelRepository)
 }
 
 type CreateModelRepositorySchema struct {
 	schemasv1.CreateModelRepositorySchema
 	GetOrganizationSchema
+	Visibility models.RepositoryVisibility `json:"visibility" enum:"internal,private"`
 }
 
-func (c *modelRepositoryController) Create(ctx *gin.Context, schema *CreateModelRepositorySchema) (*schemasv1.ModelRepositorySchema, error) {
+func (c *modelRepositoryController) Create(ctx *gin.Context, schema *CreateModelRepositorySchema) (*transformersv1.ModelRepositoryWithVisibilitySchema, error) {
 	user, err := services.GetCurrentUser(ctx)
 	if err != nil {
 		return nil, err
@@ -85,19 +86,21 @@ func (c *modelRepositoryController) Create(ctx *gin.Context, schema *CreateModel
 		CreatorId:      user.ID,
 		Name:           schema.Name,
 		Labels:         schema.Labels,
+		Visibility:     schema.Visibility,
 	})
 	if err != nil {
 		return nil, errors.Wrap(err, "create modelRepository")
 	}
-	return transformersv1.ToModelRepositorySchema(ctx, modelRepository)
+	return transformersv1.ToModelRepositoryWithVisibilitySchema(ctx, modelRepository)
 }
 
 type UpdateModelRepositorySchema struct {
 	schemasv1.UpdateModelRepositorySchema
 	GetModelRepositorySchema
+	Visibility *models.RepositoryVisibility `json:"visibility"`
 }
 
-func (c *modelRepositoryController) Update(ctx *gin.Context, schema *UpdateModelRepositorySchema) (*schemasv1.ModelRepositorySchema, error) {
+func (c *modelRepositoryController) Update(ctx *gin.Context, schema *UpdateModelRepositorySchema) (*transformersv1.ModelRepositoryWithVisibilitySchema, error) {
 	modelRepository, err := schema.GetModelRepository(ctx)
 	if err != nil {
 		return nil, err
@@ -108,14 +111,30 @@ func (c *modelRepositoryController) Update(ctx *gin.Context, schema *UpdateModel
 	modelRepository, err = services.ModelRepositoryService.Update(ctx, modelRepository, services.UpdateModelRepositoryOption{
 		Description: schema.Description,
 		Labels:      schema.Labels,
+		Visibility:  schema.Visibility,
 	})
 	if err != nil {
 		return nil, errors.Wrap(err, "update modelRepository")
 	}
+	return transformersv1.ToModelRepositoryWithVisibilitySchema(ctx, modelRepository)
+}
+
+func (c *modelRepositoryController) Delete(ctx *gin.Context, schema *GetModelRepositorySchema) (*schemasv1.ModelRepositorySchema, error) {
+	modelRepository, err := schema.GetModelRepository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canOperate(ctx, modelRepository); err != nil {
+		return nil, err
+	}
+	modelRepository, err = services.ModelRepositoryService.Delete(ctx, modelRepository)
+	if err != nil {
+		return nil, errors.Wrap(err, "delete modelRepository")
+	}
 	return transformersv1.ToModelRepositorySchema(ctx, modelRepository)
 }
 
-func (c *modelRepositoryController) Get(ctx *gin.Context, schema *GetModelRepositorySchema) (*schemasv1.ModelRepositorySchema, error) {
+func (c *modelRepositoryController) GetRetentionPolicy(ctx *gin.Context, schema *GetModelRepositorySchema) (*transformersv1.RetentionPolicySchema, error) {
 	modelRepository, err := schema.GetModelRepository(ctx)
 	if err != nil {
 		return nil, err
@@ -123,7 +142,59 @@ func (c *modelRepositoryController) Get(ctx *gin.Context, schema *GetModelReposi
 	if err = c.canView(ctx, modelRepository); err != nil {
 		return nil, err
 	}
-	return transformersv1.ToModelRepositorySchema(ctx, modelRepository)
+	return transformersv1.ToRetentionPolicySchema(modelRepository.RetentionPolicy), nil
+}
+
+type UpdateModelRepositoryRetentionPolicySchema struct {
+	models.RetentionPolicy
+	GetModelRepositorySchema
+}
+
+func (c *modelRepositoryController) UpdateRetentionPolicy(ctx *gin.Context, schema *UpdateModelRepositoryRetentionPolicySchema) (*transformersv1.RetentionPolicySchema, error) {
+	modelRepository, err := schema.GetModelRepository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canOperate(ctx, modelRepository); err != nil {
+		return nil, err
+	}
+	retentionPolicy := &schema.RetentionPolicy
+	if retentionPolicy.IsEmpty() {
+		retentionPolicy = nil
+	}
+	modelRepository, err = services.ModelRepositoryService.Update(ctx, modelRepository, services.UpdateModelRepositoryOption{
+		RetentionPolicy: &retentionPolicy,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "update modelRepository retention policy")
+	}
+	return transformersv1.ToRetentionPolicySchema(modelRepository.RetentionPolicy), nil
+}
+
+func (c *modelRepositoryController) DryRunRetentionPolicy(ctx *gin.Context, schema *GetModelRepositorySchema) ([]*schemasv1.ModelSchema, error) {
+	modelRepository, err := schema.GetModelRepository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canView(ctx, modelRepository); err != nil {
+		return nil, err
+	}
+	models_, err := services.RetentionService.ListPrunableModels(ctx, modelRepository)
+	if err != nil {
+		return nil, errors.Wrap(err, "list prunable models")
+	}
+	return transformersv1.ToModelSchemas(ctx, models_)
+}
+
+func (c *modelRepositoryController) Get(ctx *gin.Context, schema *GetModelRepositorySchema) (*transformersv1.ModelRepositoryWithVisibilitySchema, error) {
+	modelRepository, err := schema.GetModelRepository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canView(ctx, modelRepository); err != nil {
+		return nil, err
+	}
+	return transformersv1.ToModelRepositoryWithVisibilitySchema(ctx, modelRepository)
 }
 
 type ListModelRepositorySchema struct {
@@ -141,6 +212,11 @@ func (c *modelRepositoryController) List(ctx *gin.Context, schema *ListModelRepo
 		return nil, err
 	}
 
+	currentUser, err := services.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	listOpt := services.ListModelRepositoryOption{
 		BaseListOption: services.BaseListOption{
 			Start:  utils.UintPtr(schema.Start),
@@ -148,6 +224,7 @@ func (c *modelRepositoryController) List(ctx *gin.Context, schema *ListModelRepo
 			Search: schema.Search,
 		},
 		OrganizationId: utils.UintPtr(organization.ID),
+		VisitorId:      utils.UintPtr(currentUser.ID),
 	}
 
 	queryMap := schema.Q.ToMap()
@@ -0,0 +1,107 @@
+package controllersv1
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+
+	"github.com/bentoml/yatai-schemas/schemasv1"
+	"github.com/bentoml/yatai/api-server/services"
+	"github.com/bentoml/yatai/api-server/transformers/transformersv1"
+	"github.com/bentoml/yatai/common/utils"
+)
+
+type modelRepositoryMemberController struct {
+	modelRepositoryController
+}
+
+var ModelRepositoryMemberController = modelRepositoryMemberController{}
+
+type CreateModelRepositoryMembersSchema struct {
+	schemasv1.CreateMembersSchema
+	GetModelRepositorySchema
+}
+
+func (c *modelRepositoryMemberController) Create(ctx *gin.Context, schema *CreateModelRepositoryMembersSchema) ([]*transformersv1.RepositoryMemberSchema, error) {
+	currentUser, err := services.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "get current user")
+	}
+	modelRepository, err := schema.GetModelRepository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canOperate(ctx, modelRepository); err != nil {
+		return nil, err
+	}
+	users, err := services.UserService.ListByNames(ctx, schema.Usernames)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*transformersv1.RepositoryMemberSchema, 0, len(users))
+	for _, u := range users {
+		member, err := services.ModelRepositoryMemberService.Create(ctx, currentUser.ID, services.CreateRepositoryMemberOption{
+			CreatorId:    currentUser.ID,
+			UserId:       u.ID,
+			RepositoryId: modelRepository.ID,
+			Role:         schema.Role,
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "create modelRepository member")
+		}
+		s, err := transformersv1.ToRepositoryMemberSchema(ctx, member)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, s)
+	}
+	return res, nil
+}
+
+func (c *modelRepositoryMemberController) List(ctx *gin.Context, schema *GetModelRepositorySchema) ([]*transformersv1.RepositoryMemberSchema, error) {
+	modelRepository, err := schema.GetModelRepository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canView(ctx, modelRepository); err != nil {
+		return nil, err
+	}
+	members, err := services.ModelRepositoryMemberService.List(ctx, services.ListRepositoryMemberOption{
+		RepositoryId: utils.UintPtr(modelRepository.ID),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "list modelRepository members")
+	}
+	return transformersv1.ToRepositoryMemberSchemas(ctx, members)
+}
+
+type DeleteModelRepositoryMemberSchema struct {
+	schemasv1.DeleteMemberSchema
+	GetModelRepositorySchema
+}
+
+func (c *modelRepositoryMemberController) Delete(ctx *gin.Context, schema *DeleteModelRepositoryMemberSchema) (*transformersv1.RepositoryMemberSchema, error) {
+	currentUser, err := services.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "get current user")
+	}
+	modelRepository, err := schema.GetModelRepository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canOperate(ctx, modelRepository); err != nil {
+		return nil, err
+	}
+	user, err := services.UserService.GetByName(ctx, schema.Username)
+	if err != nil {
+		return nil, err
+	}
+	member, err := services.ModelRepositoryMemberService.GetBy(ctx, user.ID, modelRepository.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "get member")
+	}
+	member, err = services.ModelRepositoryMemberService.Delete(ctx, member, currentUser.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "delete modelRepository member")
+	}
+	return transformersv1.ToRepositoryMemberSchema(ctx, member)
+}
@@ -32,28 +32,28 @@ func (s *GetOrganizationSchema) GetOrganization(ctx context.Context) (*models.Or
 	return services.GetCurrentOrganization(ctx)
 }
 
-func (c *organizationController) canView(ctx context.Context, organization *models.Organization) error {
+func (c *organizationController) canView(ctx context.Context, organization *models.Organization, targets ...models.IResource) error {
 	user, err := services.GetCurrentUser(ctx)
 	if err != nil {
 		return err
 	}
-	return services.MemberService.CanView(ctx, &services.OrganizationMemberService, user, organization.ID)
+	return services.MemberService.CanView(ctx, &services.OrganizationMemberService, user, organization.ID, targets...)
 }
 
-func (c *organizationController) canUpdate(ctx context.Context, organization *models.Organization) error {
+func (c *organizationController) canUpdate(ctx context.Context, organization *models.Organization, targets ...models.IResource) error {
 	user, err := services.GetCurrentUser(ctx)
 	if err != nil {
 		return err
 	}
-	return services.MemberService.CanUpdate(ctx, &services.OrganizationMemberService, user, organization.ID)
+	return services.MemberService.CanUpdate(ctx, &services.OrganizationMemberService, user, organization.ID, targets...)
 }
 
-func (c *organizationController) canOperate(ctx context.Context, organization *models.Organization) error {
+func (c *organizationController) canOperate(ctx context.Context, organization *models.Organization, targets ...models.IResource) error {
 	user, err := services.GetCurrentUser(ctx)
 	if err != nil {
 		return err
 	}
-	return services.MemberService.CanOperate(ctx, &services.OrganizationMemberService, user, organization.ID)
+	return services.MemberService.CanOperate(ctx, &services.OrganizationMemberService, user, organization.ID, targets...)
 }
 
 func (c *organizationController) Create(ctx *gin.Context, schema *schemasv1.CreateOrganizationSchema) (*schemasv1.OrganizationFullSchema, error) {
@@ -85,9 +85,10 @@ func (c *organizationController) Create(ctx *gin.Context, schema *schemasv1.Crea
 type UpdateOrganizationSchema struct {
 	schemasv1.UpdateOrganizationSchema
 	GetOrganizationSchema
+	TwoFactorRequiredRole *modelschemas.MemberRole `json:"two_factor_required_role"`
 }
 
-func (c *organizationController) Update(ctx *gin.Context, schema *UpdateOrganizationSchema) (*schemasv1.OrganizationFullSchema, error) {
+func (c *organizationController) Update(ctx *gin.Context, schema *UpdateOrganizationSchema) (*transformersv1.OrganizationFullWithTwoFactorSchema, error) {
 	organization, err := schema.GetOrganization(ctx)
 	if err != nil {
 		return nil, err
@@ -96,16 +97,17 @@ func (c *organizationController) Update(ctx *gin.Context, schema *UpdateOrganiza
 		return nil, err
 	}
 	organization, err = services.OrganizationService.Update(ctx, organization, services.UpdateOrganizationOption{
-		Description: schema.Description,
-		Config:      schema.Config,
+		Description:           schema.Description,
+		Config:                schema.Config,
+		TwoFactorRequiredRole: schema.TwoFactorRequiredRole,
 	})
 	if err != nil {
 		return nil, errors.Wrap(err, "update organization")
 	}
-	return transformersv1.ToOrganizationFullSchema(ctx, organization)
+	return transformersv1.ToOrganizationFullWithTwoFactorSchema(ctx, organization)
 }
 
-func (c *organizationController) Get(ctx *gin.Context, schema *GetOrganizationSchema) (*schemasv1.OrganizationFullSchema, error) {
+func (c *organizationController) Get(ctx *gin.Context, schema *GetOrganizationSchema) (*transformersv1.OrganizationFullWithTwoFactorSchema, error) {
 	organization, err := schema.GetOrganization(ctx)
 	if err != nil {
 		return nil, err
@@ -113,7 +115,7 @@ func (c *organizationController) Get(ctx *gin.Context, schema *GetOrganizationSc
 	if err = c.canView(ctx, organization); err != nil {
 		return nil, err
 	}
-	return transformersv1.ToOrganizationFullSchema(ctx, organization)
+	return transformersv1.ToOrganizationFullWithTwoFactorSchema(ctx, organization)
 }
 
 func (c *organizationController) GetMajorCluster(ctx *gin.Context, schema *GetOrganizationSchema) (*schemasv1.ClusterFullSchema, error) {
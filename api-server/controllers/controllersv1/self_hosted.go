@@ -8,7 +8,6 @@ import (
 	"github.com/bentoml/yatai/api-server/config"
 	"github.com/bentoml/yatai/api-server/services"
 	"github.com/bentoml/yatai/api-server/transformers/transformersv1"
-	"github.com/bentoml/yatai/common/scookie"
 	"github.com/bentoml/yatai/common/utils"
 )
 
@@ -85,7 +84,7 @@ func (*selfHostedController) Setup(ctx *gin.Context, schema *SetupSchema) (*sche
 		return nil, errors.Wrap(err, "update admin user")
 	}
 
-	err = scookie.SetUsernameToCookie(ctx, user.Name)
+	err = services.UserSessionService.Login(ctx, user)
 	if err != nil {
 		return nil, errors.Wrap(err, "set login cookie")
 	}
@@ -15,6 +15,7 @@ import (
 	"github.com/bentoml/yatai-schemas/schemasv1"
 	"github.com/bentoml/yatai/api-server/services"
 	"github.com/bentoml/yatai/api-server/transformers/transformersv1"
+	"github.com/bentoml/yatai/common/metrics"
 )
 
 type subscriptionController struct {
@@ -31,6 +32,8 @@ func (c *subscriptionController) SubscribeResource(ctx *gin.Context) error {
 		return err
 	}
 	defer conn.Close()
+	wsCtx, done := trackWebsocketConnection(ctx, conn, metrics.WebsocketTypeSubscription)
+	defer done()
 
 	currentUser, err := services.GetCurrentUser(ctx)
 	if err != nil {
@@ -41,7 +44,7 @@ func (c *subscriptionController) SubscribeResource(ctx *gin.Context) error {
 	resourceUidsMap := make(map[modelschemas.ResourceType][]string)
 	schemasCache := make(map[string]interface{})
 
-	pollingCtx, cancel := context.WithCancel(ctx)
+	pollingCtx, cancel := context.WithCancel(wsCtx)
 	defer cancel()
 
 	go func() {
@@ -84,7 +87,7 @@ func (c *subscriptionController) SubscribeResource(ctx *gin.Context) error {
 							writeWsError(conn, err)
 							continue
 						}
-						if err = services.MemberService.CanView(ctx, &services.OrganizationMemberService, currentUser, bentoRepository.OrganizationId); err != nil {
+						if err = services.MemberService.CanView(ctx, &services.BentoRepositoryMemberService, currentUser, bentoRepository.ID, bentoRepository); err != nil {
 							writeWsError(conn, err)
 							continue
 						}
@@ -102,7 +105,7 @@ func (c *subscriptionController) SubscribeResource(ctx *gin.Context) error {
 							writeWsError(conn, err)
 							continue
 						}
-						if err = services.MemberService.CanView(ctx, &services.OrganizationMemberService, currentUser, modelRepository.OrganizationId); err != nil {
+						if err = services.MemberService.CanView(ctx, &services.ModelRepositoryMemberService, currentUser, modelRepository.ID, modelRepository); err != nil {
 							writeWsError(conn, err)
 							continue
 						}
@@ -120,7 +123,7 @@ func (c *subscriptionController) SubscribeResource(ctx *gin.Context) error {
 							writeWsError(conn, err)
 							continue
 						}
-						if err = services.MemberService.CanView(ctx, &services.ClusterMemberService, currentUser, cluster.ID); err != nil {
+						if err = services.MemberService.CanView(ctx, &services.ClusterMemberService, currentUser, cluster.ID, deployment); err != nil {
 							writeWsError(conn, err)
 							continue
 						}
@@ -31,6 +31,7 @@ import (
 	"github.com/bentoml/yatai/api-server/models"
 	"github.com/bentoml/yatai/api-server/services"
 	"github.com/bentoml/yatai/common/consts"
+	"github.com/bentoml/yatai/common/metrics"
 	"github.com/bentoml/yatai/common/utils"
 )
 
@@ -312,6 +313,8 @@ func (c *terminalController) GetDeploymentPodTerminal(ctx *gin.Context, schema *
 		return err
 	}
 	defer conn.Close()
+	wsCtx, done := trackWebsocketConnection(ctx, conn, metrics.WebsocketTypeTerminal)
+	defer done()
 
 	defer func() {
 		writeWsError(conn, err)
@@ -382,13 +385,13 @@ func (c *terminalController) GetDeploymentPodTerminal(ctx *gin.Context, schema *
 		return err
 	}
 
-	t, err := NewWebTerminal(ctx, conn, kubeNs, podName, containerName, recorder)
+	t, err := NewWebTerminal(wsCtx, conn, kubeNs, podName, containerName, recorder)
 	if err != nil {
 		return err
 	}
 
 	if debug == "1" {
-		err = t.HandleDebug(ctx, cliset, restConfig, fork == "1")
+		err = t.HandleDebug(wsCtx, cliset, restConfig, fork == "1")
 		return err
 	}
 
@@ -406,6 +409,8 @@ func (c *terminalController) GetClusterPodTerminal(ctx *gin.Context, schema *Get
 		return err
 	}
 	defer conn.Close()
+	wsCtx, done := trackWebsocketConnection(ctx, conn, metrics.WebsocketTypeTerminal)
+	defer done()
 
 	defer func() {
 		writeWsError(conn, err)
@@ -467,13 +472,13 @@ func (c *terminalController) GetClusterPodTerminal(ctx *gin.Context, schema *Get
 		return err
 	}
 
-	t, err := NewWebTerminal(ctx, conn, kubeNs, podName, containerName, recorder)
+	t, err := NewWebTerminal(wsCtx, conn, kubeNs, podName, containerName, recorder)
 	if err != nil {
 		return err
 	}
 
 	if debug == "1" {
-		err = t.HandleDebug(ctx, cliset, restConfig, fork == "1")
+		err = t.HandleDebug(wsCtx, cliset, restConfig, fork == "1")
 		return err
 	}
 
@@ -118,3 +118,58 @@ func (c *userController) Create(ctx *gin.Context, schema *CreateOrganizationUser
 
 	return transformersv1.ToUserSchema(ctx, user)
 }
+
+// canOperate allows the super admins, or the admins of the current organization for its members
+func (c *userController) canOperate(ctx context.Context, user *models.User) error {
+	currentUser, err := services.GetCurrentUser(ctx)
+	if err != nil {
+		return err
+	}
+	if currentUser.IsSuperAdmin() {
+		return nil
+	}
+	org, err := services.GetCurrentOrganization(ctx)
+	if err != nil {
+		return errors.Wrap(err, "get current organization")
+	}
+	if err = OrganizationController.canOperate(ctx, org); err != nil {
+		return err
+	}
+	_, err = services.OrganizationMemberService.GetBy(ctx, user.ID, org.ID)
+	if err != nil {
+		return errors.Wrapf(err, "get organization member %s", user.Name)
+	}
+	return nil
+}
+
+func (c *userController) ListSessions(ctx *gin.Context, schema *GetUserSchema) ([]*transformersv1.UserSessionSchema, error) {
+	user, err := schema.GetUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canOperate(ctx, user); err != nil {
+		return nil, err
+	}
+	sessions, err := services.UserSessionService.List(ctx, services.ListUserSessionOption{
+		UserId: utils.UintPtr(user.ID),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "list user sessions")
+	}
+	return transformersv1.ToUserSessionSchemas(ctx, sessions)
+}
+
+func (c *userController) RevokeSessions(ctx *gin.Context, schema *GetUserSchema) (*schemasv1.UserSchema, error) {
+	user, err := schema.GetUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canOperate(ctx, user); err != nil {
+		return nil, err
+	}
+	err = services.UserSessionService.DeleteByUser(ctx, user.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "delete user sessions")
+	}
+	return transformersv1.ToUserSchema(ctx, user)
+}
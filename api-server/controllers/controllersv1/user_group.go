@@ -0,0 +1,318 @@
+package controllersv1
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+
+	"github.com/bentoml/yatai-schemas/modelschemas"
+	"github.com/bentoml/yatai-schemas/schemasv1"
+	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/api-server/services"
+	"github.com/bentoml/yatai/api-server/transformers/transformersv1"
+	"github.com/bentoml/yatai/common/consts"
+	"github.com/bentoml/yatai/common/utils"
+)
+
+type userGroupController struct {
+	organizationController
+}
+
+var UserGroupController = userGroupController{}
+
+type GetUserGroupSchema struct {
+	GetOrganizationSchema
+	UserGroupName string `path:"userGroupName"`
+}
+
+func (s *GetUserGroupSchema) GetUserGroup(ctx context.Context) (*models.Organization, *models.UserGroup, error) {
+	org, err := s.GetOrganization(ctx)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "get organization")
+	}
+	userGroup, err := services.UserGroupService.GetByName(ctx, org.ID, s.UserGroupName)
+	if err != nil {
+		return nil, nil, err
+	}
+	return org, userGroup, nil
+}
+
+type CreateUserGroupSchema struct {
+	GetOrganizationSchema
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+func (c *userGroupController) Create(ctx *gin.Context, schema *CreateUserGroupSchema) (*transformersv1.UserGroupSchema, error) {
+	user, err := services.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	org, err := schema.GetOrganization(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canOperate(ctx, org); err != nil {
+		return nil, err
+	}
+	userGroup, err := services.UserGroupService.Create(ctx, services.CreateUserGroupOption{
+		CreatorId:      user.ID,
+		OrganizationId: org.ID,
+		Name:           schema.Name,
+		Description:    schema.Description,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "create user group")
+	}
+	return transformersv1.ToUserGroupSchema(ctx, userGroup)
+}
+
+type UpdateUserGroupSchema struct {
+	GetUserGroupSchema
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+}
+
+func (c *userGroupController) Update(ctx *gin.Context, schema *UpdateUserGroupSchema) (*transformersv1.UserGroupSchema, error) {
+	org, userGroup, err := schema.GetUserGroup(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canOperate(ctx, org); err != nil {
+		return nil, err
+	}
+	userGroup, err = services.UserGroupService.Update(ctx, userGroup, services.UpdateUserGroupOption{
+		Name:        schema.Name,
+		Description: schema.Description,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "update user group")
+	}
+	return transformersv1.ToUserGroupSchema(ctx, userGroup)
+}
+
+func (c *userGroupController) Get(ctx *gin.Context, schema *GetUserGroupSchema) (*transformersv1.UserGroupSchema, error) {
+	org, userGroup, err := schema.GetUserGroup(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canView(ctx, org); err != nil {
+		return nil, err
+	}
+	return transformersv1.ToUserGroupSchema(ctx, userGroup)
+}
+
+type ListUserGroupSchema struct {
+	schemasv1.ListQuerySchema
+	GetOrganizationSchema
+}
+
+func (c *userGroupController) List(ctx *gin.Context, schema *ListUserGroupSchema) (*transformersv1.UserGroupListSchema, error) {
+	org, err := schema.GetOrganization(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canView(ctx, org); err != nil {
+		return nil, err
+	}
+	userGroups, total, err := services.UserGroupService.List(ctx, services.ListUserGroupOption{
+		OrganizationId: utils.UintPtr(org.ID),
+		BaseListOption: services.BaseListOption{
+			Start:  utils.UintPtr(schema.Start),
+			Count:  utils.UintPtr(schema.Count),
+			Search: schema.Search,
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "list user groups")
+	}
+	userGroupSchemas, err := transformersv1.ToUserGroupSchemas(ctx, userGroups)
+	return &transformersv1.UserGroupListSchema{
+		BaseListSchema: schemasv1.BaseListSchema{
+			Total: total,
+			Start: schema.Start,
+			Count: schema.Count,
+		},
+		Items: userGroupSchemas,
+	}, err
+}
+
+func (c *userGroupController) Delete(ctx *gin.Context, schema *GetUserGroupSchema) (*transformersv1.UserGroupSchema, error) {
+	org, userGroup, err := schema.GetUserGroup(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canOperate(ctx, org); err != nil {
+		return nil, err
+	}
+	userGroup, err = services.UserGroupService.Delete(ctx, userGroup)
+	if err != nil {
+		return nil, errors.Wrap(err, "delete user group")
+	}
+	return transformersv1.ToUserGroupSchema(ctx, userGroup)
+}
+
+func (c *userGroupController) ListUsers(ctx *gin.Context, schema *GetUserGroupSchema) ([]*schemasv1.UserSchema, error) {
+	org, userGroup, err := schema.GetUserGroup(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canView(ctx, org); err != nil {
+		return nil, err
+	}
+	users, err := services.UserGroupService.ListUsers(ctx, userGroup)
+	if err != nil {
+		return nil, errors.Wrap(err, "list user group users")
+	}
+	return transformersv1.ToUserSchemas(ctx, users)
+}
+
+type AddUserGroupUsersSchema struct {
+	GetUserGroupSchema
+	Usernames []string `json:"usernames"`
+}
+
+func (c *userGroupController) AddUsers(ctx *gin.Context, schema *AddUserGroupUsersSchema) ([]*schemasv1.UserSchema, error) {
+	currentUser, err := services.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	org, userGroup, err := schema.GetUserGroup(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canOperate(ctx, org); err != nil {
+		return nil, err
+	}
+	users, err := services.UserService.ListByNames(ctx, schema.Usernames)
+	if err != nil {
+		return nil, err
+	}
+	for _, u := range users {
+		err = services.UserGroupService.AddUser(ctx, userGroup, u.ID, currentUser.ID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "add user %s to user group", u.Name)
+		}
+	}
+	return transformersv1.ToUserSchemas(ctx, users)
+}
+
+type RemoveUserGroupUserSchema struct {
+	schemasv1.DeleteMemberSchema
+	GetUserGroupSchema
+}
+
+func (c *userGroupController) RemoveUser(ctx *gin.Context, schema *RemoveUserGroupUserSchema) (*schemasv1.UserSchema, error) {
+	org, userGroup, err := schema.GetUserGroup(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canOperate(ctx, org); err != nil {
+		return nil, err
+	}
+	user, err := services.UserService.GetByName(ctx, schema.Username)
+	if err != nil {
+		return nil, err
+	}
+	err = services.UserGroupService.RemoveUser(ctx, userGroup, user.ID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "remove user %s from user group", user.Name)
+	}
+	return transformersv1.ToUserSchema(ctx, user)
+}
+
+func (c *userGroupController) ListGrants(ctx *gin.Context, schema *GetUserGroupSchema) ([]*transformersv1.UserGroupGrantSchema, error) {
+	org, userGroup, err := schema.GetUserGroup(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canView(ctx, org); err != nil {
+		return nil, err
+	}
+	grants, err := services.UserGroupGrantService.List(ctx, services.ListUserGroupGrantOption{
+		UserGroupId: utils.UintPtr(userGroup.ID),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "list user group grants")
+	}
+	return transformersv1.ToUserGroupGrantSchemas(ctx, grants)
+}
+
+type CreateUserGroupGrantSchema struct {
+	GetUserGroupSchema
+	ResourceType modelschemas.ResourceType `json:"resource_type" enum:"organization,cluster,bento_repository,model_repository"`
+	// the name of the cluster or repository in the organization, ignored for the organization itself
+	ResourceName string                  `json:"resource_name"`
+	Role         modelschemas.MemberRole `json:"role" enum:"guest,developer,admin"`
+}
+
+// getResource returns the granted resource, it must belong to the organization of the group
+func (s *CreateUserGroupGrantSchema) getResource(ctx context.Context, org *models.Organization) (models.IResource, error) {
+	switch s.ResourceType {
+	case modelschemas.ResourceTypeOrganization:
+		return org, nil
+	case modelschemas.ResourceTypeCluster:
+		return services.ClusterService.GetByName(ctx, org.ID, s.ResourceName)
+	case modelschemas.ResourceTypeBentoRepository:
+		return services.BentoRepositoryService.GetByName(ctx, org.ID, s.ResourceName)
+	case modelschemas.ResourceTypeModelRepository:
+		return services.ModelRepositoryService.GetByName(ctx, org.ID, s.ResourceName)
+	}
+	return nil, errors.Errorf("cannot grant a role on the %s", s.ResourceType)
+}
+
+func (c *userGroupController) CreateGrant(ctx *gin.Context, schema *CreateUserGroupGrantSchema) (*transformersv1.UserGroupGrantSchema, error) {
+	currentUser, err := services.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	org, userGroup, err := schema.GetUserGroup(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canOperate(ctx, org); err != nil {
+		return nil, err
+	}
+	resource, err := schema.getResource(ctx, org)
+	if err != nil {
+		return nil, err
+	}
+	grant, err := services.UserGroupGrantService.Create(ctx, services.CreateUserGroupGrantOption{
+		CreatorId:   currentUser.ID,
+		UserGroupId: userGroup.ID,
+		Resource:    resource,
+		Role:        schema.Role,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "create user group grant")
+	}
+	return transformersv1.ToUserGroupGrantSchema(ctx, grant)
+}
+
+type DeleteUserGroupGrantSchema struct {
+	GetUserGroupSchema
+	GrantUid string `path:"grantUid"`
+}
+
+func (c *userGroupController) DeleteGrant(ctx *gin.Context, schema *DeleteUserGroupGrantSchema) (*transformersv1.UserGroupGrantSchema, error) {
+	org, userGroup, err := schema.GetUserGroup(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canOperate(ctx, org); err != nil {
+		return nil, err
+	}
+	grant, err := services.UserGroupGrantService.GetByUid(ctx, schema.GrantUid)
+	if err != nil {
+		return nil, errors.Wrapf(err, "get user group grant %s", schema.GrantUid)
+	}
+	if grant.UserGroupId != userGroup.ID {
+		return nil, consts.ErrNotFound
+	}
+	grant, err = services.UserGroupGrantService.Delete(ctx, grant)
+	if err != nil {
+		return nil, errors.Wrap(err, "delete user group grant")
+	}
+	return transformersv1.ToUserGroupGrantSchema(ctx, grant)
+}
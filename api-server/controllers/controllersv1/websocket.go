@@ -0,0 +1,72 @@
+package controllersv1
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/bentoml/yatai/common/metrics"
+)
+
+type websocketConnections struct {
+	mu      sync.Mutex
+	closing bool
+	cancels map[*websocket.Conn]context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// the http server does not track the hijacked connections, so the websockets are tracked here to be drained on shutdown
+var wsConnections = &websocketConnections{
+	cancels: make(map[*websocket.Conn]context.CancelFunc),
+}
+
+// trackWebsocketConnection returns the ctx of the connection which is cancelled on shutdown,
+// done must be called once the handler is finished with the connection.
+func trackWebsocketConnection(ctx context.Context, conn *websocket.Conn, type_ metrics.WebsocketType) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	untrack := metrics.TrackWebsocketConnection(type_)
+
+	wsConnections.mu.Lock()
+	defer wsConnections.mu.Unlock()
+	if wsConnections.closing {
+		cancel()
+		return ctx, untrack
+	}
+	wsConnections.cancels[conn] = cancel
+	wsConnections.wg.Add(1)
+	return ctx, func() {
+		wsConnections.mu.Lock()
+		delete(wsConnections.cancels, conn)
+		wsConnections.mu.Unlock()
+		cancel()
+		untrack()
+		wsConnections.wg.Done()
+	}
+}
+
+// CloseWebsocketConnections sends a going away close frame to every open websocket and cancels
+// their ctx, so the tails stop and the terminal records get saved, then waits for the handlers to return.
+func CloseWebsocketConnections(ctx context.Context) error {
+	wsConnections.mu.Lock()
+	wsConnections.closing = true
+	closeMessage := websocket.FormatCloseMessage(websocket.CloseGoingAway, "server is shutting down")
+	for conn, cancel := range wsConnections.cancels {
+		_ = conn.WriteControl(websocket.CloseMessage, closeMessage, time.Now().Add(time.Second))
+		cancel()
+	}
+	wsConnections.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		wsConnections.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -10,7 +10,7 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"github.com/bentoml/yatai/api-server/config"
-	"github.com/bentoml/yatai/common/scookie"
+	"github.com/bentoml/yatai/api-server/services"
 )
 
 var (
@@ -30,6 +30,8 @@ func Index(ctx *gin.Context) {
 }
 
 func Logout(ctx *gin.Context) {
-	_ = scookie.DeleteUsernameFromCookie(ctx)
+	if err := services.UserSessionService.Logout(ctx); err != nil {
+		logrus.Errorf("logout: %s", err.Error())
+	}
 	ctx.Redirect(http.StatusFound, "/login")
 }
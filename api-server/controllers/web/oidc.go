@@ -0,0 +1,125 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"github.com/bentoml/yatai/api-server/services"
+	"github.com/bentoml/yatai/common/scookie"
+	"github.com/bentoml/yatai/common/utils"
+)
+
+const (
+	oidcStateKey        = "oidc_state"
+	oidcNonceKey        = "oidc_nonce"
+	oidcCodeVerifierKey = "oidc_code_verifier"
+	oidcRedirectKey     = "oidc_redirect"
+)
+
+// only the paths of this site are allowed, so the login cannot be used as an open redirect
+func getLocalRedirect(redirect string) string {
+	if !strings.HasPrefix(redirect, "/") || strings.HasPrefix(redirect, "//") || strings.HasPrefix(redirect, "/\\") {
+		return "/"
+	}
+	return redirect
+}
+
+// OIDCLogin redirects to the idp, the state, nonce and pkce code verifier are kept in the session
+func OIDCLogin(ctx *gin.Context) {
+	if !services.OIDCService.IsEnabled() {
+		ctx.String(http.StatusNotFound, "oidc login is not configured")
+		return
+	}
+	state := utils.RandAlphanumString(32)
+	nonce := utils.RandAlphanumString(32)
+	codeVerifier := services.OIDCService.NewCodeVerifier()
+	authCodeURL, err := services.OIDCService.AuthCodeURL(ctx, state, nonce, codeVerifier)
+	if err != nil {
+		logrus.Errorf("oidc login: %s", err.Error())
+		ctx.String(http.StatusBadGateway, "the identity provider is not available")
+		return
+	}
+	session := sessions.Default(ctx)
+	session.Set(oidcStateKey, state)
+	session.Set(oidcNonceKey, nonce)
+	session.Set(oidcCodeVerifierKey, codeVerifier)
+	session.Set(oidcRedirectKey, getLocalRedirect(ctx.Query("redirect")))
+	err = session.Save()
+	if err != nil {
+		logrus.Errorf("oidc login: save session: %s", err.Error())
+		ctx.String(http.StatusInternalServerError, "save session failed")
+		return
+	}
+	ctx.Redirect(http.StatusFound, authCodeURL)
+}
+
+func OIDCCallback(ctx *gin.Context) {
+	if !services.OIDCService.IsEnabled() {
+		ctx.String(http.StatusNotFound, "oidc login is not configured")
+		return
+	}
+	session := sessions.Default(ctx)
+	state, _ := session.Get(oidcStateKey).(string)
+	nonce, _ := session.Get(oidcNonceKey).(string)
+	codeVerifier, _ := session.Get(oidcCodeVerifierKey).(string)
+	redirect, _ := session.Get(oidcRedirectKey).(string)
+	session.Delete(oidcStateKey)
+	session.Delete(oidcNonceKey)
+	session.Delete(oidcCodeVerifierKey)
+	session.Delete(oidcRedirectKey)
+
+	if idpErr := ctx.Query("error"); idpErr != "" {
+		_ = session.Save()
+		ctx.String(http.StatusForbidden, "sso login failed: %s %s", idpErr, ctx.Query("error_description"))
+		return
+	}
+	if state == "" || ctx.Query("state") != state {
+		_ = session.Save()
+		ctx.String(http.StatusBadRequest, "invalid sso login state, please login again")
+		return
+	}
+
+	claims, err := services.OIDCService.Exchange(ctx, ctx.Query("code"), codeVerifier, nonce)
+	if err != nil {
+		logrus.Errorf("oidc callback: %s", err.Error())
+		_ = session.Save()
+		ctx.String(http.StatusForbidden, "sso login failed")
+		return
+	}
+	user, err := services.OIDCService.Login(ctx, claims)
+	if err != nil {
+		logrus.Errorf("oidc callback: %s", err.Error())
+		_ = session.Save()
+		ctx.String(http.StatusForbidden, "sso login failed: %s", err.Error())
+		return
+	}
+	// the sso login has to be finished with the two-factor code as well, the login page asks for it
+	if user.TotpEnabled {
+		// the pending cookie is saved with the oidc keys deleted
+		err = scookie.SetTwoFactorPendingUserIdToCookie(ctx, user.ID)
+		if err != nil {
+			logrus.Errorf("oidc callback: set two-factor pending cookie: %s", err.Error())
+			ctx.String(http.StatusInternalServerError, "set login cookie failed")
+			return
+		}
+		ctx.Redirect(http.StatusFound, fmt.Sprintf("/login?%s", url.Values{
+			"two_factor": []string{"1"},
+			"redirect":   []string{getLocalRedirect(redirect)},
+		}.Encode()))
+		return
+	}
+	// Login saves the cookie session with the oidc keys deleted
+	err = services.UserSessionService.Login(ctx, user)
+	if err != nil {
+		logrus.Errorf("oidc callback: set login cookie: %s", err.Error())
+		ctx.String(http.StatusInternalServerError, "set login cookie failed")
+		return
+	}
+	ctx.Redirect(http.StatusFound, getLocalRedirect(redirect))
+}
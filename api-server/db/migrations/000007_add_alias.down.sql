@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "bento_alias";
+DROP TABLE IF EXISTS "model_alias";
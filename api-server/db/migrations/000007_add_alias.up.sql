@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS "bento_alias" (
+    id SERIAL PRIMARY KEY,
+    uid VARCHAR(32) UNIQUE NOT NULL DEFAULT generate_object_id(),
+    name VARCHAR(128) NOT NULL,
+    bento_repository_id INTEGER NOT NULL REFERENCES "bento_repository"("id") ON DELETE CASCADE,
+    bento_id INTEGER NOT NULL REFERENCES "bento"("id") ON DELETE CASCADE,
+    creator_id INTEGER NOT NULL REFERENCES "user"("id") ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE UNIQUE INDEX "uk_bentoAlias_bentoRepositoryId_name" ON "bento_alias" ("bento_repository_id", "name");
+
+CREATE TABLE IF NOT EXISTS "model_alias" (
+    id SERIAL PRIMARY KEY,
+    uid VARCHAR(32) UNIQUE NOT NULL DEFAULT generate_object_id(),
+    name VARCHAR(128) NOT NULL,
+    model_repository_id INTEGER NOT NULL REFERENCES "model_repository"("id") ON DELETE CASCADE,
+    model_id INTEGER NOT NULL REFERENCES "model"("id") ON DELETE CASCADE,
+    creator_id INTEGER NOT NULL REFERENCES "user"("id") ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE UNIQUE INDEX "uk_modelAlias_modelRepositoryId_name" ON "model_alias" ("model_repository_id", "name");
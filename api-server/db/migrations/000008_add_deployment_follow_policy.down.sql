@@ -0,0 +1,2 @@
+ALTER TABLE "deployment" DROP COLUMN IF EXISTS "follow_policy";
+ALTER TABLE "deployment" DROP COLUMN IF EXISTS "follow_deployed_at";
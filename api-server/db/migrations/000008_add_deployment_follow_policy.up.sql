@@ -0,0 +1,2 @@
+ALTER TABLE "deployment" ADD COLUMN IF NOT EXISTS "follow_policy" TEXT DEFAULT NULL;
+ALTER TABLE "deployment" ADD COLUMN IF NOT EXISTS "follow_deployed_at" TIMESTAMP WITH TIME ZONE DEFAULT NULL;
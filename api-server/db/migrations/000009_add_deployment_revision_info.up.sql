@@ -0,0 +1 @@
+ALTER TABLE "deployment_revision" ADD COLUMN IF NOT EXISTS "info" TEXT DEFAULT NULL;
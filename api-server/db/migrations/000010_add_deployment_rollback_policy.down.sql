@@ -0,0 +1 @@
+ALTER TABLE "deployment" DROP COLUMN IF EXISTS "rollback_policy";
@@ -0,0 +1 @@
+ALTER TABLE "deployment" ADD COLUMN IF NOT EXISTS "rollback_policy" TEXT DEFAULT NULL;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS "deployment_status_history" (
+    id SERIAL PRIMARY KEY,
+    uid VARCHAR(32) UNIQUE NOT NULL DEFAULT generate_object_id(),
+    deployment_id INTEGER NOT NULL REFERENCES "deployment"("id") ON DELETE CASCADE,
+    from_status deployment_status DEFAULT NULL,
+    status deployment_status NOT NULL,
+    reason TEXT DEFAULT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX "idx_deploymentStatusHistory_deploymentId_createdAt" ON "deployment_status_history" ("deployment_id", "created_at");
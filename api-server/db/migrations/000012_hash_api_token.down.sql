@@ -0,0 +1,8 @@
+-- the plaintext of the hashed tokens is gone, they can not be restored
+DELETE FROM "api_token" WHERE "token" IS NULL;
+DROP INDEX IF EXISTS "idx_apiToken_tokenPrefix";
+ALTER TABLE "api_token" DROP COLUMN IF EXISTS "previous_token_expired_at";
+ALTER TABLE "api_token" DROP COLUMN IF EXISTS "previous_token_hash";
+ALTER TABLE "api_token" DROP COLUMN IF EXISTS "token_hash";
+ALTER TABLE "api_token" DROP COLUMN IF EXISTS "token_prefix";
+ALTER TABLE "api_token" ALTER COLUMN "token" SET NOT NULL;
@@ -0,0 +1,7 @@
+ALTER TABLE "api_token" ALTER COLUMN "token" DROP NOT NULL;
+ALTER TABLE "api_token" ADD COLUMN IF NOT EXISTS "token_prefix" VARCHAR(32) DEFAULT NULL;
+ALTER TABLE "api_token" ADD COLUMN IF NOT EXISTS "token_hash" VARCHAR(256) DEFAULT NULL;
+ALTER TABLE "api_token" ADD COLUMN IF NOT EXISTS "previous_token_hash" VARCHAR(256) DEFAULT NULL;
+ALTER TABLE "api_token" ADD COLUMN IF NOT EXISTS "previous_token_expired_at" TIMESTAMP WITH TIME ZONE DEFAULT NULL;
+
+CREATE INDEX IF NOT EXISTS "idx_apiToken_tokenPrefix" ON "api_token" ("token_prefix");
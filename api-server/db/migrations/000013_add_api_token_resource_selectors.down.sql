@@ -0,0 +1 @@
+ALTER TABLE "api_token" DROP COLUMN IF EXISTS "resource_selectors";
@@ -0,0 +1 @@
+ALTER TABLE "api_token" ADD COLUMN IF NOT EXISTS "resource_selectors" TEXT DEFAULT NULL;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "api_token_usage";
+
+ALTER TABLE "api_token" DROP COLUMN IF EXISTS "allowed_cidrs";
@@ -0,0 +1,18 @@
+ALTER TABLE "api_token" ADD COLUMN IF NOT EXISTS "allowed_cidrs" TEXT DEFAULT NULL;
+
+CREATE TABLE IF NOT EXISTS "api_token_usage" (
+    id SERIAL PRIMARY KEY,
+    uid VARCHAR(32) UNIQUE NOT NULL DEFAULT generate_object_id(),
+    api_token_id INTEGER NOT NULL REFERENCES "api_token"("id") ON DELETE CASCADE,
+    minute TIMESTAMP WITH TIME ZONE NOT NULL,
+    client_ip VARCHAR(64) NOT NULL,
+    user_agent VARCHAR(512) NOT NULL,
+    operation_id VARCHAR(128) NOT NULL,
+    count INTEGER NOT NULL DEFAULT 1,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE UNIQUE INDEX "uk_apiTokenUsage_apiTokenId_minute_clientIp_userAgent_opId" ON "api_token_usage" ("api_token_id", "minute", "client_ip", "user_agent", "operation_id");
+CREATE INDEX "idx_apiTokenUsage_minute" ON "api_token_usage" ("minute");
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS "uk_user_oidcIssuer_oidcSubject";
+
+ALTER TABLE "user" DROP COLUMN IF EXISTS "oidc_subject";
+ALTER TABLE "user" DROP COLUMN IF EXISTS "oidc_issuer";
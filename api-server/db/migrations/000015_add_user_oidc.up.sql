@@ -0,0 +1,4 @@
+ALTER TABLE "user" ADD COLUMN IF NOT EXISTS "oidc_issuer" VARCHAR(256) DEFAULT NULL;
+ALTER TABLE "user" ADD COLUMN IF NOT EXISTS "oidc_subject" VARCHAR(256) DEFAULT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS "uk_user_oidcIssuer_oidcSubject" ON "user" ("oidc_issuer", "oidc_subject");
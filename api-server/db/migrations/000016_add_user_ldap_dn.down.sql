@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS "uk_user_ldapDn";
+
+ALTER TABLE "user" DROP COLUMN IF EXISTS "ldap_dn";
@@ -0,0 +1,3 @@
+ALTER TABLE "user" ADD COLUMN IF NOT EXISTS "ldap_dn" VARCHAR(512) DEFAULT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS "uk_user_ldapDn" ON "user" ("ldap_dn");
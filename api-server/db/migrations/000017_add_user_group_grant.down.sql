@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS "user_group_grant";
+
+DROP INDEX IF EXISTS "uk_userGroupUserRelation_userGroupId_userId";
+
+ALTER TABLE "user_group" DROP COLUMN IF EXISTS "description";
@@ -0,0 +1,19 @@
+ALTER TABLE "user_group" ADD COLUMN IF NOT EXISTS "description" TEXT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS "uk_userGroupUserRelation_userGroupId_userId" ON "user_group_user_relation" ("user_group_id", "user_id");
+
+CREATE TABLE IF NOT EXISTS "user_group_grant" (
+    id SERIAL PRIMARY KEY,
+    uid VARCHAR(32) UNIQUE NOT NULL DEFAULT generate_object_id(),
+    user_group_id INTEGER NOT NULL REFERENCES "user_group"("id") ON DELETE CASCADE,
+    resource_type resource_type NOT NULL,
+    resource_id INTEGER NOT NULL,
+    role member_role NOT NULL DEFAULT 'guest',
+    creator_id INTEGER NOT NULL REFERENCES "user"("id") ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE UNIQUE INDEX "uk_userGroupGrant_userGroupId_resourceType_resourceId" ON "user_group_grant" ("user_group_id", "resource_type", "resource_id");
+CREATE INDEX "idx_userGroupGrant_resourceType_resourceId" ON "user_group_grant" ("resource_type", "resource_id");
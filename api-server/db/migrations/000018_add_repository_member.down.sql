@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS "repository_member";
+
+ALTER TABLE "bento_repository" DROP COLUMN IF EXISTS "visibility";
+ALTER TABLE "model_repository" DROP COLUMN IF EXISTS "visibility";
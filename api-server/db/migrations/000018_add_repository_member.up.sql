@@ -0,0 +1,18 @@
+ALTER TABLE "bento_repository" ADD COLUMN IF NOT EXISTS "visibility" VARCHAR(32) NOT NULL DEFAULT 'internal';
+ALTER TABLE "model_repository" ADD COLUMN IF NOT EXISTS "visibility" VARCHAR(32) NOT NULL DEFAULT 'internal';
+
+CREATE TABLE IF NOT EXISTS "repository_member" (
+    id SERIAL PRIMARY KEY,
+    uid VARCHAR(32) UNIQUE NOT NULL DEFAULT generate_object_id(),
+    resource_type resource_type NOT NULL,
+    repository_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL REFERENCES "user"("id") ON DELETE CASCADE,
+    role member_role NOT NULL DEFAULT 'guest',
+    creator_id INTEGER NOT NULL REFERENCES "user"("id") ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE UNIQUE INDEX "uk_repositoryMember_resourceType_repositoryId_userId" ON "repository_member" ("resource_type", "repository_id", "user_id");
+CREATE INDEX "idx_repositoryMember_userId" ON "repository_member" ("user_id");
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS "user_session" (
+    id SERIAL PRIMARY KEY,
+    uid VARCHAR(32) UNIQUE NOT NULL DEFAULT generate_object_id(),
+    user_id INTEGER NOT NULL REFERENCES "user"("id") ON DELETE CASCADE,
+    secret_hash VARCHAR(128) NOT NULL,
+    client_ip VARCHAR(64) NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    last_seen_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX "idx_userSession_userId" ON "user_session" ("user_id");
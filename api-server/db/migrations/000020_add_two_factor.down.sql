@@ -0,0 +1,8 @@
+ALTER TABLE "organization" DROP COLUMN IF EXISTS "two_factor_required_role";
+
+ALTER TABLE "user" DROP COLUMN IF EXISTS "totp_last_failed_at";
+ALTER TABLE "user" DROP COLUMN IF EXISTS "totp_failed_attempts";
+ALTER TABLE "user" DROP COLUMN IF EXISTS "totp_last_used_step";
+ALTER TABLE "user" DROP COLUMN IF EXISTS "totp_recovery_codes";
+ALTER TABLE "user" DROP COLUMN IF EXISTS "totp_enabled";
+ALTER TABLE "user" DROP COLUMN IF EXISTS "totp_secret";
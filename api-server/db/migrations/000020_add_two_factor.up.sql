@@ -0,0 +1,8 @@
+ALTER TABLE "user" ADD COLUMN IF NOT EXISTS "totp_secret" VARCHAR(64) DEFAULT NULL;
+ALTER TABLE "user" ADD COLUMN IF NOT EXISTS "totp_enabled" BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE "user" ADD COLUMN IF NOT EXISTS "totp_recovery_codes" TEXT DEFAULT NULL;
+ALTER TABLE "user" ADD COLUMN IF NOT EXISTS "totp_last_used_step" BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE "user" ADD COLUMN IF NOT EXISTS "totp_failed_attempts" INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE "user" ADD COLUMN IF NOT EXISTS "totp_last_failed_at" TIMESTAMP WITH TIME ZONE DEFAULT NULL;
+
+ALTER TABLE "organization" ADD COLUMN IF NOT EXISTS "two_factor_required_role" VARCHAR(32) NOT NULL DEFAULT '';
@@ -1,20 +1,50 @@
 package models
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"net"
 	"time"
 
 	"github.com/bentoml/yatai-schemas/modelschemas"
 )
 
+// ApiTokenAllowedCidrs is empty for the tokens which can be used from any ip.
+type ApiTokenAllowedCidrs []string
+
+func (c *ApiTokenAllowedCidrs) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	return json.Unmarshal([]byte(value.(string)), c)
+}
+
+func (c *ApiTokenAllowedCidrs) Value() (driver.Value, error) {
+	if c == nil {
+		return nil, nil
+	}
+	return json.Marshal(c)
+}
+
 type ApiToken struct {
 	ResourceMixin
 	OrganizationAssociate
 	UserAssociate
-	Description string                       `json:"description"`
-	Token       string                       `json:"token"`
-	Scopes      *modelschemas.ApiTokenScopes `json:"scopes"`
-	ExpiredAt   *time.Time                   `json:"expired_at"`
-	LastUsedAt  *time.Time                   `json:"last_used_at"`
+	Description string `json:"description"`
+	// Token is the plaintext token of the rows created before the tokens were hashed
+	Token       *string `json:"-"`
+	TokenPrefix string  `json:"token_prefix"`
+	TokenHash   string  `json:"-"`
+	// the previous secret keeps working until PreviousTokenExpiredAt after a rotation
+	PreviousTokenHash      *string                      `json:"-"`
+	PreviousTokenExpiredAt *time.Time                   `json:"previous_token_expired_at"`
+	Scopes                 *modelschemas.ApiTokenScopes `json:"scopes"`
+	ResourceSelectors      *ApiTokenResourceSelectors   `json:"resource_selectors"`
+	AllowedCidrs           *ApiTokenAllowedCidrs        `json:"allowed_cidrs"`
+	ExpiredAt              *time.Time                   `json:"expired_at"`
+	LastUsedAt             *time.Time                   `json:"last_used_at"`
+	// RawToken is only set right after the token is created or rotated, it is never stored
+	RawToken string `json:"-" gorm:"-"`
 }
 
 func (a *ApiToken) GetResourceType() modelschemas.ResourceType {
@@ -27,3 +57,23 @@ func (a *ApiToken) IsExpired() bool {
 	}
 	return time.Now().After(*a.ExpiredAt)
 }
+
+func (a *ApiToken) IsClientIpAllowed(clientIp string) bool {
+	if a.AllowedCidrs == nil || len(*a.AllowedCidrs) == 0 {
+		return true
+	}
+	ip := net.ParseIP(clientIp)
+	if ip == nil {
+		return false
+	}
+	for _, cidr := range *a.AllowedCidrs {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			continue
+		}
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
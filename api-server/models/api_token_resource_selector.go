@@ -0,0 +1,35 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+
+	"github.com/bentoml/yatai-schemas/modelschemas"
+)
+
+// ApiTokenResourceSelector limits a token to the resources of ResourceType matching all of
+// Names, Labels and ClusterName, the ones left empty match everything.
+type ApiTokenResourceSelector struct {
+	ResourceType modelschemas.ResourceType `json:"resource_type"`
+	Names        []string                  `json:"names,omitempty"`
+	Labels       map[string]string         `json:"labels,omitempty"`
+	// ClusterName limits the deployments to a cluster
+	ClusterName *string `json:"cluster_name,omitempty"`
+}
+
+// ApiTokenResourceSelectors is empty for the tokens which are not limited to some resources.
+type ApiTokenResourceSelectors []*ApiTokenResourceSelector
+
+func (s *ApiTokenResourceSelectors) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	return json.Unmarshal([]byte(value.(string)), s)
+}
+
+func (s *ApiTokenResourceSelectors) Value() (driver.Value, error) {
+	if s == nil {
+		return nil, nil
+	}
+	return json.Marshal(s)
+}
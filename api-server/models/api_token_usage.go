@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+)
+
+// ApiTokenUsage counts the requests of an api token in a minute, by client ip, user agent and operation.
+type ApiTokenUsage struct {
+	BaseModel
+	ApiTokenId  uint      `json:"api_token_id"`
+	Minute      time.Time `json:"minute"`
+	ClientIp    string    `json:"client_ip"`
+	UserAgent   string    `json:"user_agent"`
+	OperationId string    `json:"operation_id"`
+	Count       uint      `json:"count"`
+}
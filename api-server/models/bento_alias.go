@@ -0,0 +1,8 @@
+package models
+
+type BentoAlias struct {
+	ResourceMixin
+	CreatorAssociate
+	BentoRepositoryAssociate
+	BentoAssociate
+}
@@ -6,9 +6,15 @@ type BentoRepository struct {
 	ResourceMixin
 	CreatorAssociate
 	OrganizationAssociate
-	Description string `json:"description"`
+	Description     string               `json:"description"`
+	RetentionPolicy *RetentionPolicy     `json:"retention_policy"`
+	Visibility      RepositoryVisibility `json:"visibility"`
 }
 
 func (b *BentoRepository) GetResourceType() modelschemas.ResourceType {
 	return modelschemas.ResourceTypeBentoRepository
 }
+
+func (b *BentoRepository) GetVisibility() RepositoryVisibility {
+	return b.Visibility
+}
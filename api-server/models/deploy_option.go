@@ -7,4 +7,6 @@ import (
 type DeployOption struct {
 	Force           bool
 	OwnerReferences []metav1.OwnerReference
+	// CanaryWeight overrides the weight canary rule of canary targets
+	CanaryWeight *uint
 }
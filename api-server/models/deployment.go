@@ -17,6 +17,11 @@ type Deployment struct {
 	StatusUpdatedAt *time.Time                    `json:"status_updated_at"`
 	KubeDeployToken string                        `json:"kube_deploy_token"`
 	KubeNamespace   string                        `json:"kube_namespace"`
+
+	FollowPolicy     *DeploymentFollowPolicy `json:"follow_policy"`
+	FollowDeployedAt *time.Time              `json:"follow_deployed_at"`
+
+	RollbackPolicy *DeploymentRollbackPolicy `json:"rollback_policy"`
 }
 
 func (d *Deployment) GetResourceType() modelschemas.ResourceType {
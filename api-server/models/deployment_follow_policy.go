@@ -0,0 +1,40 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+)
+
+const DefaultDeploymentFollowDebounceSeconds = 60
+
+// DeploymentFollowPolicy makes a deployment redeploy automatically when the
+// bento it follows changes. An empty Alias follows the latest uploaded bento
+// of each target's bento repository. A followed bento is only deployed after it
+// stayed the followed one for DebounceSeconds, so a burst of uploads or alias
+// moves results in a single deploy.
+type DeploymentFollowPolicy struct {
+	Alias           string `json:"alias,omitempty"`
+	DebounceSeconds *uint  `json:"debounce_seconds,omitempty"`
+}
+
+func (p *DeploymentFollowPolicy) GetDebounce() time.Duration {
+	if p == nil || p.DebounceSeconds == nil {
+		return DefaultDeploymentFollowDebounceSeconds * time.Second
+	}
+	return time.Duration(*p.DebounceSeconds) * time.Second
+}
+
+func (p *DeploymentFollowPolicy) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	return json.Unmarshal([]byte(value.(string)), p)
+}
+
+func (p *DeploymentFollowPolicy) Value() (driver.Value, error) {
+	if p == nil {
+		return nil, nil
+	}
+	return json.Marshal(p)
+}
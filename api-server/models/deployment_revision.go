@@ -1,6 +1,70 @@
 package models
 
-import "github.com/bentoml/yatai-schemas/modelschemas"
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+
+	"github.com/bentoml/yatai-schemas/modelschemas"
+)
+
+type DeploymentCanaryRolloutStatus string
+
+const (
+	DeploymentCanaryRolloutStatusProgressing DeploymentCanaryRolloutStatus = "progressing"
+	DeploymentCanaryRolloutStatusPaused      DeploymentCanaryRolloutStatus = "paused"
+	DeploymentCanaryRolloutStatusPromoted    DeploymentCanaryRolloutStatus = "promoted"
+	DeploymentCanaryRolloutStatusAborted     DeploymentCanaryRolloutStatus = "aborted"
+)
+
+func (s DeploymentCanaryRolloutStatus) Ptr() *DeploymentCanaryRolloutStatus {
+	return &s
+}
+
+const DefaultDeploymentCanaryRolloutStepIntervalSeconds = 300
+
+// DeploymentCanaryRollout is the progress of shifting traffic to the canary
+// target of a revision, Steps are the traffic weights applied one by one.
+type DeploymentCanaryRollout struct {
+	Status              DeploymentCanaryRolloutStatus `json:"status"`
+	CanaryTargetId      uint                          `json:"canary_target_id"`
+	Steps               []uint                        `json:"steps"`
+	StepIntervalSeconds uint                          `json:"step_interval_seconds"`
+	CurrentStep         int                           `json:"current_step"`
+	Weight              uint                          `json:"weight"`
+	StepStartedAt       time.Time                     `json:"step_started_at"`
+}
+
+// DeploymentRevisionInfo records how a revision came into being and how it ended.
+type DeploymentRevisionInfo struct {
+	RollbackFromRevisionUid string                   `json:"rollback_from_revision_uid,omitempty"`
+	CanaryRollout           *DeploymentCanaryRollout `json:"canary_rollout,omitempty"`
+	// ClearedFollowPolicy is the follow policy turned off by the rollback, so the rollback is not overridden by an auto deploy
+	ClearedFollowPolicy *DeploymentFollowPolicy `json:"cleared_follow_policy,omitempty"`
+
+	// AutoRollbackAttempts counts the consecutive automatic rollbacks leading to this revision
+	AutoRollbackAttempts uint                          `json:"auto_rollback_attempts,omitempty"`
+	FailedStatus         modelschemas.DeploymentStatus `json:"failed_status,omitempty"`
+	FailedAt             *time.Time                    `json:"failed_at,omitempty"`
+}
+
+func (i *DeploymentRevisionInfo) IsFailed() bool {
+	return i != nil && i.FailedStatus != ""
+}
+
+func (i *DeploymentRevisionInfo) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	return json.Unmarshal([]byte(value.(string)), i)
+}
+
+func (i *DeploymentRevisionInfo) Value() (driver.Value, error) {
+	if i == nil {
+		return nil, nil
+	}
+	return json.Marshal(i)
+}
 
 type DeploymentRevision struct {
 	BaseModel
@@ -8,6 +72,7 @@ type DeploymentRevision struct {
 	DeploymentAssociate
 
 	Status modelschemas.DeploymentRevisionStatus `json:"status"`
+	Info   *DeploymentRevisionInfo               `json:"info"`
 }
 
 func (s *DeploymentRevision) GetName() string {
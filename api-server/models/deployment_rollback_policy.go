@@ -0,0 +1,49 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+)
+
+const (
+	DefaultDeploymentRollbackGracePeriodSeconds = 600
+	DefaultDeploymentRollbackMaxAttempts        = 1
+)
+
+// DeploymentRollbackPolicy makes a deployment reactivate its previous revision
+// when a new revision fails within GracePeriodSeconds after it was deployed.
+// MaxAttempts caps the consecutive automatic rollbacks.
+type DeploymentRollbackPolicy struct {
+	Enabled            bool  `json:"enabled"`
+	GracePeriodSeconds *uint `json:"grace_period_seconds,omitempty"`
+	MaxAttempts        *uint `json:"max_attempts,omitempty"`
+}
+
+func (p *DeploymentRollbackPolicy) GetGracePeriod() time.Duration {
+	if p == nil || p.GracePeriodSeconds == nil {
+		return DefaultDeploymentRollbackGracePeriodSeconds * time.Second
+	}
+	return time.Duration(*p.GracePeriodSeconds) * time.Second
+}
+
+func (p *DeploymentRollbackPolicy) GetMaxAttempts() uint {
+	if p == nil || p.MaxAttempts == nil {
+		return DefaultDeploymentRollbackMaxAttempts
+	}
+	return *p.MaxAttempts
+}
+
+func (p *DeploymentRollbackPolicy) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	return json.Unmarshal([]byte(value.(string)), p)
+}
+
+func (p *DeploymentRollbackPolicy) Value() (driver.Value, error) {
+	if p == nil {
+		return nil, nil
+	}
+	return json.Marshal(p)
+}
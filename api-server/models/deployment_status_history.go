@@ -0,0 +1,47 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+
+	apiv1 "k8s.io/api/core/v1"
+
+	"github.com/bentoml/yatai-schemas/modelschemas"
+)
+
+type DeploymentStatusPodReason struct {
+	Name     string                           `json:"name"`
+	Phase    apiv1.PodPhase                   `json:"phase"`
+	Status   modelschemas.KubePodActualStatus `json:"status"`
+	Warnings []string                         `json:"warnings,omitempty"`
+}
+
+// DeploymentStatusReason is what the status was derived from: the pods
+// of the deployment, or of the image builder while the image is building.
+type DeploymentStatusReason struct {
+	Message string                       `json:"message,omitempty"`
+	Pods    []*DeploymentStatusPodReason `json:"pods,omitempty"`
+}
+
+func (r *DeploymentStatusReason) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	return json.Unmarshal([]byte(value.(string)), r)
+}
+
+func (r *DeploymentStatusReason) Value() (driver.Value, error) {
+	if r == nil {
+		return nil, nil
+	}
+	return json.Marshal(r)
+}
+
+type DeploymentStatusHistory struct {
+	BaseModel
+	DeploymentAssociate
+
+	FromStatus *modelschemas.DeploymentStatus `json:"from_status"`
+	Status     modelschemas.DeploymentStatus  `json:"status"`
+	Reason     *DeploymentStatusReason        `json:"reason"`
+}
@@ -0,0 +1,8 @@
+package models
+
+type ModelAlias struct {
+	ResourceMixin
+	CreatorAssociate
+	ModelRepositoryAssociate
+	ModelAssociate
+}
@@ -6,9 +6,15 @@ type ModelRepository struct {
 	ResourceMixin
 	CreatorAssociate
 	OrganizationAssociate
-	Description string `json:"description"`
+	Description     string               `json:"description"`
+	RetentionPolicy *RetentionPolicy     `json:"retention_policy"`
+	Visibility      RepositoryVisibility `json:"visibility"`
 }
 
 func (b *ModelRepository) GetResourceType() modelschemas.ResourceType {
 	return modelschemas.ResourceTypeModelRepository
 }
+
+func (b *ModelRepository) GetVisibility() RepositoryVisibility {
+	return b.Visibility
+}
@@ -8,6 +8,9 @@ type Organization struct {
 
 	Description string                                 `json:"description"`
 	Config      *modelschemas.OrganizationConfigSchema `json:"config"`
+	// TwoFactorRequiredRole is the lowest member role which must enable the two-factor authentication,
+	// it is empty when the two-factor authentication is optional
+	TwoFactorRequiredRole modelschemas.MemberRole `json:"two_factor_required_role"`
 }
 
 func (o *Organization) GetResourceType() modelschemas.ResourceType {
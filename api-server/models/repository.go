@@ -0,0 +1,21 @@
+package models
+
+type RepositoryVisibility string
+
+const (
+	// RepositoryVisibilityInternal repositories can be viewed by all members of the organization
+	RepositoryVisibilityInternal RepositoryVisibility = "internal"
+	// RepositoryVisibilityPrivate repositories can only be viewed by the repository members and the organization admins
+	RepositoryVisibilityPrivate RepositoryVisibility = "private"
+)
+
+func (v RepositoryVisibility) IsValid() bool {
+	return v == RepositoryVisibilityInternal || v == RepositoryVisibilityPrivate
+}
+
+// IRepository is a bento repository or a model repository
+type IRepository interface {
+	IResource
+	GetAssociatedOrganizationId() uint
+	GetVisibility() RepositoryVisibility
+}
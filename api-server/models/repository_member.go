@@ -0,0 +1,13 @@
+package models
+
+import "github.com/bentoml/yatai-schemas/modelschemas"
+
+type RepositoryMember struct {
+	BaseModel
+	CreatorAssociate
+	UserAssociate
+
+	ResourceType modelschemas.ResourceType `json:"resource_type"`
+	RepositoryId uint                      `json:"repository_id"`
+	Role         modelschemas.MemberRole   `json:"role"`
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+)
+
+// RetentionPolicy decides which versions of a repository can be pruned.
+// A version is retained if it matches any of the rules, labelled, aliased or
+// deployed versions are always retained by the retention service.
+type RetentionPolicy struct {
+	KeepLast       *uint `json:"keep_last,omitempty"`
+	KeepWithinDays *uint `json:"keep_within_days,omitempty"`
+}
+
+func (p *RetentionPolicy) IsEmpty() bool {
+	return p == nil || (p.KeepLast == nil && p.KeepWithinDays == nil)
+}
+
+// ShouldRetain reports whether the version at the given position, sorted by
+// creation time descending, should be kept.
+func (p *RetentionPolicy) ShouldRetain(idx int, createdAt time.Time, now time.Time) bool {
+	if p.IsEmpty() {
+		return true
+	}
+	if p.KeepLast != nil && idx < int(*p.KeepLast) {
+		return true
+	}
+	if p.KeepWithinDays != nil && now.Sub(createdAt) < time.Duration(*p.KeepWithinDays)*24*time.Hour {
+		return true
+	}
+	return false
+}
+
+func (p *RetentionPolicy) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	return json.Unmarshal([]byte(value.(string)), p)
+}
+
+func (p *RetentionPolicy) Value() (driver.Value, error) {
+	if p == nil {
+		return nil, nil
+	}
+	return json.Marshal(p)
+}
@@ -3,6 +3,7 @@ package models
 import (
 	"database/sql/driver"
 	"encoding/json"
+	"time"
 
 	"github.com/bentoml/yatai-schemas/modelschemas"
 )
@@ -16,6 +17,18 @@ type User struct {
 	Password        string                `json:"password"`
 	IsEmailVerified bool                  `json:"is_email_verified"`
 	Config          *UserConfig           `json:"config"`
+	// the idp account of the users who logged in by sso
+	OidcIssuer  *string `json:"-"`
+	OidcSubject *string `json:"-"`
+	// the directory entry of the users who logged in by ldap
+	LdapDn *string `json:"-"`
+	// the totp two-factor authentication, the secret is set while enrolling before it is enabled
+	TotpSecret         *string                `json:"-"`
+	TotpEnabled        bool                   `json:"-"`
+	TotpRecoveryCodes  *UserTotpRecoveryCodes `json:"-"`
+	TotpLastUsedStep   int64                  `json:"-"`
+	TotpFailedAttempts int                    `json:"-"`
+	TotpLastFailedAt   *time.Time             `json:"-"`
 
 	ApiToken *ApiToken `gorm:"-" json:"-"`
 }
@@ -38,6 +51,23 @@ func (c *UserConfig) Value() (driver.Value, error) {
 	return json.Marshal(c)
 }
 
+// UserTotpRecoveryCodes are the hashes of the unused recovery codes
+type UserTotpRecoveryCodes []string
+
+func (c *UserTotpRecoveryCodes) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	return json.Unmarshal([]byte(value.(string)), c)
+}
+
+func (c *UserTotpRecoveryCodes) Value() (driver.Value, error) {
+	if c == nil {
+		return nil, nil
+	}
+	return json.Marshal(c)
+}
+
 func (u *User) GetResourceType() modelschemas.ResourceType {
 	return modelschemas.ResourceTypeUser
 }
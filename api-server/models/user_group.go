@@ -4,4 +4,5 @@ type UserGroup struct {
 	ResourceMixin
 	OrganizationAssociate
 	CreatorAssociate
+	Description string `json:"description"`
 }
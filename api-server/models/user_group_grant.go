@@ -0,0 +1,14 @@
+package models
+
+import "github.com/bentoml/yatai-schemas/modelschemas"
+
+// UserGroupGrant grants the role on an organization, a cluster or a repository to all users of the group
+type UserGroupGrant struct {
+	BaseModel
+	CreatorAssociate
+	UserGroupAssociate
+
+	ResourceType modelschemas.ResourceType `json:"resource_type"`
+	ResourceId   uint                      `json:"resource_id"`
+	Role         modelschemas.MemberRole   `json:"role"`
+}
@@ -3,5 +3,6 @@ package models
 type UserGroupUserRelation struct {
 	BaseModel
 	UserGroupAssociate
+	UserAssociate
 	CreatorAssociate
 }
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// UserSession is a browser login of the user, the cookie only carries its uid and secret
+type UserSession struct {
+	BaseModel
+	UserAssociate
+	SecretHash string    `json:"-"`
+	ClientIp   string    `json:"client_ip"`
+	UserAgent  string    `json:"user_agent"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+}
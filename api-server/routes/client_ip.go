@@ -0,0 +1,76 @@
+package routes
+
+import (
+	"net"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+var trustedProxyNets []*net.IPNet
+
+func parseTrustedProxies(trustedProxies []string) ([]*net.IPNet, error) {
+	res := make([]*net.IPNet, 0, len(trustedProxies))
+	for _, trustedProxy := range trustedProxies {
+		trustedProxy = strings.TrimSpace(trustedProxy)
+		if trustedProxy == "" {
+			continue
+		}
+		if !strings.Contains(trustedProxy, "/") {
+			ip := net.ParseIP(trustedProxy)
+			if ip == nil {
+				return nil, errors.Errorf("invalid trusted proxy %s", trustedProxy)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip = ip.To4()
+				bits = 8 * net.IPv4len
+			}
+			res = append(res, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(trustedProxy)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid trusted proxy %s", trustedProxy)
+		}
+		res = append(res, ipNet)
+	}
+	return res, nil
+}
+
+func isTrustedProxy(ip net.IP) bool {
+	for _, ipNet := range trustedProxyNets {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// getClientIp returns the remote ip of the request, the X-Forwarded-For header is only honored
+// when the remote ip is a trusted proxy, then the nearest hop which is not a trusted proxy is the client.
+// The gin engine is not started by engine.Run, so its own trusted proxies are never prepared.
+func getClientIp(ctx *gin.Context) string {
+	remoteIp, _, err := net.SplitHostPort(strings.TrimSpace(ctx.Request.RemoteAddr))
+	if err != nil {
+		return ""
+	}
+	ip := net.ParseIP(remoteIp)
+	if ip == nil || !isTrustedProxy(ip) {
+		return remoteIp
+	}
+	hops := strings.Split(ctx.GetHeader("X-Forwarded-For"), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		hopIp := net.ParseIP(hop)
+		if hopIp == nil {
+			break
+		}
+		remoteIp = hop
+		if !isTrustedProxy(hopIp) {
+			break
+		}
+	}
+	return remoteIp
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/huandu/xstrings"
 	"github.com/loopfz/gadgeto/tonic"
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/wI2L/fizz"
 	"github.com/wI2L/fizz/openapi"
 
@@ -24,6 +25,7 @@ import (
 	"github.com/bentoml/yatai/api-server/controllers/web"
 	"github.com/bentoml/yatai/api-server/models"
 	"github.com/bentoml/yatai/api-server/services"
+	"github.com/bentoml/yatai/common/metrics"
 	"github.com/bentoml/yatai/common/scookie"
 	"github.com/bentoml/yatai/common/utils"
 	"github.com/bentoml/yatai/common/yataicontext"
@@ -65,6 +67,11 @@ func injectCurrentOrganization(c *gin.Context) {
 	c.Next()
 }
 
+func injectClientIp(c *gin.Context) {
+	yataicontext.SetClientIp(c, getClientIp(c))
+	c.Next()
+}
+
 func NewRouter() (*fizz.Fizz, error) {
 	tonic.SetRenderHook(func(c *gin.Context, statusCode int, payload interface{}) {
 		if _, exists := c.Get(WebsocketConnectContextKey); exists {
@@ -87,6 +94,12 @@ func NewRouter() (*fizz.Fizz, error) {
 		}
 	}, "")
 
+	var err error
+	trustedProxyNets, err = parseTrustedProxies(config.YataiConfig.Server.TrustedProxies)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse trusted proxies")
+	}
+
 	engine := gin.New()
 
 	store := cookie.NewStore([]byte(config.YataiConfig.Server.SessionSecretKey))
@@ -99,10 +112,18 @@ func NewRouter() (*fizz.Fizz, error) {
 			MaxAge: int(time.Hour * 24 * 30),
 		})
 	}
+	engine.Use(metrics.HTTPMiddleware)
 	engine.Use(injectCurrentOrganization)
+	engine.Use(injectClientIp)
 	engine.Use(sessions.Sessions("yatai-session-v2", store))
 
 	engine.GET("/logout", web.Logout)
+	engine.GET("/oauth/oidc/login", web.OIDCLogin)
+	engine.GET("/oauth/oidc/callback", web.OIDCCallback)
+
+	engine.GET("/healthz", controllersv1.HealthController.Healthz)
+	engine.GET("/readyz", requireLoginForClusterChecks, controllersv1.HealthController.Readyz)
+	engine.GET("/metrics", gin.WrapH(promhttp.Handler()))
 
 	fizzApp := fizz.NewFromEngine(engine)
 
@@ -202,6 +223,7 @@ func NewRouter() (*fizz.Fizz, error) {
 	userRoutes(apiRootGroup)
 	organizationRoutes(apiRootGroup)
 	apiTokenRoutes(apiRootGroup)
+	userGroupRoutes(apiRootGroup)
 	labelRoutes(apiRootGroup)
 	clusterRoutes(apiRootGroup)
 	bentoRepositoryRoutes(apiRootGroup)
@@ -289,6 +311,11 @@ func getLoginUser(ctx *gin.Context) (user *models.User, err error) {
 			err = errors.New("the api token is expired")
 			return
 		}
+		clientIp := getClientIp(ctx)
+		if !apiToken.IsClientIpAllowed(clientIp) {
+			err = errors.Errorf("the api token is not allowed to be used from %s", clientIp)
+			return
+		}
 		user, err = services.UserService.GetAssociatedUser(ctx, apiToken)
 		if err != nil {
 			err = errors.Wrap(err, "get user by api token")
@@ -305,16 +332,28 @@ func getLoginUser(ctx *gin.Context) (user *models.User, err error) {
 		}
 		user.ApiToken = apiToken
 	} else {
-		username := scookie.GetUsernameFromCookie(ctx)
-		if username == "" {
-			err = errors.New("username in cookie is empty")
+		sessionKey := scookie.GetSessionKeyFromCookie(ctx)
+		if sessionKey == "" {
+			err = errors.New("session in cookie is empty")
+			return
+		}
+		var session *models.UserSession
+		session, err = services.UserSessionService.GetByKey(ctx, sessionKey)
+		if err != nil {
+			err = errors.Wrap(err, "get session in cookie")
+			return
+		}
+		err = services.UserSessionService.Touch(ctx, session, getClientIp(ctx), ctx.Request.UserAgent())
+		if err != nil {
+			err = errors.Wrap(err, "update session")
 			return
 		}
-		user, err = services.UserService.GetByName(ctx, username)
+		user, err = services.UserService.GetAssociatedUser(ctx, session)
 		if err != nil {
-			err = errors.Wrapf(err, "get user by name in cookie %s", username)
+			err = errors.Wrap(err, "get user by session in cookie")
 			return
 		}
+		services.SetCurrentUserSession(ctx, session)
 	}
 
 	yataicontext.SetUserName(ctx, user.Name)
@@ -334,7 +373,7 @@ func getLoginUser(ctx *gin.Context) (user *models.User, err error) {
 		}
 		services.SetCurrentOrganization(ctx, org)
 	} else {
-		err = services.MemberService.CanView(ctx, &services.OrganizationMemberService, user, org.ID)
+		err = services.MemberService.CanVisit(ctx, &services.OrganizationMemberService, user, org.ID)
 		if err != nil {
 			return
 		}
@@ -343,17 +382,76 @@ func getLoginUser(ctx *gin.Context) (user *models.User, err error) {
 }
 
 func requireLogin(ctx *gin.Context) {
-	_, loginErr := getLoginUser(ctx)
+	user, loginErr := getLoginUser(ctx)
 	if loginErr != nil {
 		msg := schemasv1.MsgSchema{Message: loginErr.Error()}
 		ctx.AbortWithStatusJSON(http.StatusForbidden, &msg)
 		return
 	}
 
+	if err := checkTwoFactor(ctx, user); err != nil {
+		msg := schemasv1.MsgSchema{Message: err.Error()}
+		ctx.AbortWithStatusJSON(http.StatusForbidden, &msg)
+		return
+	}
+
 	// https://github.com/gorilla/handlers/pull/187
 	if ctx.GetHeader("Upgrade") == "" {
 		ctx.Next()
 	}
+
+	// the fizz operation is only known after the handler ran, the websockets fall back to the route path
+	if user.ApiToken != nil {
+		services.ApiTokenUsageService.Record(services.RecordApiTokenUsageOption{
+			ApiTokenId:  user.ApiToken.ID,
+			ClientIp:    getClientIp(ctx),
+			UserAgent:   ctx.Request.UserAgent(),
+			OperationId: metrics.OperationId(ctx),
+		})
+	}
+}
+
+// requireLoginForClusterChecks leaves the readiness probe open, only the checks of the clusters need the login.
+func requireLoginForClusterChecks(ctx *gin.Context) {
+	if ctx.Query("clusters") != "true" {
+		ctx.Next()
+		return
+	}
+	requireLogin(ctx)
+}
+
+// checkTwoFactor rejects the login sessions of the users who must enable the two-factor authentication
+// in the current organization, only the auth api is left open for them to enroll.
+func checkTwoFactor(ctx *gin.Context, user *models.User) error {
+	if user.ApiToken != nil || user.TotpEnabled || strings.HasPrefix(ctx.Request.URL.Path, "/api/v1/auth/") {
+		return nil
+	}
+	org, err := services.GetCurrentOrganization(ctx)
+	if err != nil {
+		return errors.Wrap(err, "get current organization")
+	}
+	required, err := services.TwoFactorService.IsRequired(ctx, user, org)
+	if err != nil {
+		return errors.Wrap(err, "check two-factor requirement")
+	}
+	if required {
+		return errors.Errorf("the organization %s requires the two-factor authentication, please enable it first", org.Name)
+	}
+	return nil
+}
+
+// requireClientCert makes sure the machine clients present a client certificate verified by the tls handshake.
+func requireClientCert(ctx *gin.Context) {
+	if !config.YataiConfig.Server.TLS.RequireClientCert {
+		ctx.Next()
+		return
+	}
+	if ctx.Request.TLS == nil || len(ctx.Request.TLS.VerifiedChains) == 0 {
+		msg := schemasv1.MsgSchema{Message: "a verified client certificate is required"}
+		ctx.AbortWithStatusJSON(http.StatusForbidden, &msg)
+		return
+	}
+	ctx.Next()
 }
 
 func authRoutes(publicGrp *fizz.RouterGroup) {
@@ -371,6 +469,11 @@ func authRoutes(publicGrp *fizz.RouterGroup) {
 		fizz.Summary("Login an user"),
 	}, tonic.Handler(controllersv1.AuthController.Login, 200))
 
+	publicGrp.POST("/login/two_factor", []fizz.OperationOption{
+		fizz.ID("Login an user with two-factor code"),
+		fizz.Summary("Login an user with two-factor code"),
+	}, tonic.Handler(controllersv1.AuthController.LoginTwoFactor, 200))
+
 	grp.GET("/current", []fizz.OperationOption{
 		fizz.ID("Get current user"),
 		fizz.Summary("Get current user"),
@@ -380,6 +483,41 @@ func authRoutes(publicGrp *fizz.RouterGroup) {
 		fizz.ID("Reset password"),
 		fizz.Summary("Reset password"),
 	}, tonic.Handler(controllersv1.AuthController.ResetPassword, 200))
+
+	grp.GET("/sessions", []fizz.OperationOption{
+		fizz.ID("List current user sessions"),
+		fizz.Summary("List current user sessions"),
+	}, tonic.Handler(controllersv1.AuthController.ListSessions, 200))
+
+	grp.DELETE("/sessions/:sessionUid", []fizz.OperationOption{
+		fizz.ID("Revoke a current user session"),
+		fizz.Summary("Revoke a current user session"),
+	}, tonic.Handler(controllersv1.AuthController.RevokeSession, 200))
+
+	grp.GET("/two_factor", []fizz.OperationOption{
+		fizz.ID("Get current user two-factor authentication"),
+		fizz.Summary("Get current user two-factor authentication"),
+	}, tonic.Handler(controllersv1.AuthController.GetTwoFactor, 200))
+
+	grp.POST("/two_factor/enroll", []fizz.OperationOption{
+		fizz.ID("Enroll two-factor authentication"),
+		fizz.Summary("Enroll two-factor authentication"),
+	}, tonic.Handler(controllersv1.AuthController.EnrollTwoFactor, 200))
+
+	grp.POST("/two_factor/enable", []fizz.OperationOption{
+		fizz.ID("Enable two-factor authentication"),
+		fizz.Summary("Enable two-factor authentication"),
+	}, tonic.Handler(controllersv1.AuthController.EnableTwoFactor, 200))
+
+	grp.POST("/two_factor/disable", []fizz.OperationOption{
+		fizz.ID("Disable two-factor authentication"),
+		fizz.Summary("Disable two-factor authentication"),
+	}, tonic.Handler(controllersv1.AuthController.DisableTwoFactor, 200))
+
+	grp.POST("/two_factor/recovery_codes", []fizz.OperationOption{
+		fizz.ID("Regenerate two-factor recovery codes"),
+		fizz.Summary("Regenerate two-factor recovery codes"),
+	}, tonic.Handler(controllersv1.AuthController.RegenerateTwoFactorRecoveryCodes, 200))
 }
 
 func userRoutes(grp *fizz.RouterGroup) {
@@ -392,6 +530,16 @@ func userRoutes(grp *fizz.RouterGroup) {
 		fizz.Summary("Get an user"),
 	}, tonic.Handler(controllersv1.UserController.Get, 200))
 
+	resourceGrp.GET("/sessions", []fizz.OperationOption{
+		fizz.ID("List an user sessions"),
+		fizz.Summary("List an user sessions"),
+	}, tonic.Handler(controllersv1.UserController.ListSessions, 200))
+
+	resourceGrp.DELETE("/sessions", []fizz.OperationOption{
+		fizz.ID("Revoke all sessions of an user"),
+		fizz.Summary("Revoke all sessions of an user"),
+	}, tonic.Handler(controllersv1.UserController.RevokeSessions, 200))
+
 	grp.GET("", []fizz.OperationOption{
 		fizz.ID("List users"),
 		fizz.Summary("List users"),
@@ -496,6 +644,16 @@ func apiTokenRoutes(grp *fizz.RouterGroup) {
 		fizz.Summary("Delete a api token"),
 	}, tonic.Handler(controllersv1.ApiTokenController.Delete, 200))
 
+	resourceGrp.POST("/rotate", []fizz.OperationOption{
+		fizz.ID("Rotate a api token"),
+		fizz.Summary("Rotate a api token"),
+	}, tonic.Handler(controllersv1.ApiTokenController.Rotate, 200))
+
+	resourceGrp.GET("/usages", []fizz.OperationOption{
+		fizz.ID("List api token usages"),
+		fizz.Summary("List api token usages"),
+	}, tonic.Handler(controllersv1.ApiTokenController.ListUsages, 200))
+
 	grp.GET("", []fizz.OperationOption{
 		fizz.ID("List api tokens"),
 		fizz.Summary("List api tokens"),
@@ -507,6 +665,67 @@ func apiTokenRoutes(grp *fizz.RouterGroup) {
 	}, tonic.Handler(controllersv1.ApiTokenController.Create, 200))
 }
 
+func userGroupRoutes(grp *fizz.RouterGroup) {
+	grp = grp.Group("/user_groups", "user groups", "user groups")
+
+	resourceGrp := grp.Group("/:userGroupName", "user group resource", "user group resource")
+
+	resourceGrp.GET("", []fizz.OperationOption{
+		fizz.ID("Get an user group"),
+		fizz.Summary("Get an user group"),
+	}, tonic.Handler(controllersv1.UserGroupController.Get, 200))
+
+	resourceGrp.PATCH("", []fizz.OperationOption{
+		fizz.ID("Update an user group"),
+		fizz.Summary("Update an user group"),
+	}, tonic.Handler(controllersv1.UserGroupController.Update, 200))
+
+	resourceGrp.DELETE("", []fizz.OperationOption{
+		fizz.ID("Delete an user group"),
+		fizz.Summary("Delete an user group"),
+	}, tonic.Handler(controllersv1.UserGroupController.Delete, 200))
+
+	resourceGrp.GET("/users", []fizz.OperationOption{
+		fizz.ID("List user group users"),
+		fizz.Summary("List user group users"),
+	}, tonic.Handler(controllersv1.UserGroupController.ListUsers, 200))
+
+	resourceGrp.POST("/users", []fizz.OperationOption{
+		fizz.ID("Add user group users"),
+		fizz.Summary("Add user group users"),
+	}, tonic.Handler(controllersv1.UserGroupController.AddUsers, 200))
+
+	resourceGrp.DELETE("/users", []fizz.OperationOption{
+		fizz.ID("Remove an user group user"),
+		fizz.Summary("Remove an user group user"),
+	}, tonic.Handler(controllersv1.UserGroupController.RemoveUser, 200))
+
+	resourceGrp.GET("/grants", []fizz.OperationOption{
+		fizz.ID("List user group grants"),
+		fizz.Summary("List user group grants"),
+	}, tonic.Handler(controllersv1.UserGroupController.ListGrants, 200))
+
+	resourceGrp.POST("/grants", []fizz.OperationOption{
+		fizz.ID("Create an user group grant"),
+		fizz.Summary("Create an user group grant"),
+	}, tonic.Handler(controllersv1.UserGroupController.CreateGrant, 200))
+
+	resourceGrp.DELETE("/grants/:grantUid", []fizz.OperationOption{
+		fizz.ID("Delete an user group grant"),
+		fizz.Summary("Delete an user group grant"),
+	}, tonic.Handler(controllersv1.UserGroupController.DeleteGrant, 200))
+
+	grp.GET("", []fizz.OperationOption{
+		fizz.ID("List user groups"),
+		fizz.Summary("List user groups"),
+	}, tonic.Handler(controllersv1.UserGroupController.List, 200))
+
+	grp.POST("", []fizz.OperationOption{
+		fizz.ID("Create user group"),
+		fizz.Summary("Create user group"),
+	}, tonic.Handler(controllersv1.UserGroupController.Create, 200))
+}
+
 func labelRoutes(grp *fizz.RouterGroup) {
 	grp = grp.Group("/labels", "labels", "labels")
 	grp.GET("", []fizz.OperationOption{
@@ -574,6 +793,56 @@ func bentoRepositoryRoutes(grp *fizz.RouterGroup) {
 		fizz.Summary("Update a bento repository"),
 	}, tonic.Handler(controllersv1.BentoRepositoryController.Update, 200))
 
+	resourceGrp.DELETE("", []fizz.OperationOption{
+		fizz.ID("Delete a bento repository"),
+		fizz.Summary("Delete a bento repository"),
+	}, tonic.Handler(controllersv1.BentoRepositoryController.Delete, 200))
+
+	resourceGrp.GET("/members", []fizz.OperationOption{
+		fizz.ID("List bento repository members"),
+		fizz.Summary("List bento repository members"),
+	}, tonic.Handler(controllersv1.BentoRepositoryMemberController.List, 200))
+
+	resourceGrp.POST("/members", []fizz.OperationOption{
+		fizz.ID("Create a bento repository member"),
+		fizz.Summary("Create a bento repository member"),
+	}, tonic.Handler(controllersv1.BentoRepositoryMemberController.Create, 200))
+
+	resourceGrp.DELETE("/members", []fizz.OperationOption{
+		fizz.ID("Remove a bento repository member"),
+		fizz.Summary("Remove a bento repository member"),
+	}, tonic.Handler(controllersv1.BentoRepositoryMemberController.Delete, 200))
+
+	resourceGrp.GET("/retention_policy", []fizz.OperationOption{
+		fizz.ID("Get a bento repository retention policy"),
+		fizz.Summary("Get a bento repository retention policy"),
+	}, tonic.Handler(controllersv1.BentoRepositoryController.GetRetentionPolicy, 200))
+
+	resourceGrp.PUT("/retention_policy", []fizz.OperationOption{
+		fizz.ID("Update a bento repository retention policy"),
+		fizz.Summary("Update a bento repository retention policy"),
+	}, tonic.Handler(controllersv1.BentoRepositoryController.UpdateRetentionPolicy, 200))
+
+	resourceGrp.GET("/retention_policy/dry_run", []fizz.OperationOption{
+		fizz.ID("Dry run a bento repository retention policy"),
+		fizz.Summary("Dry run a bento repository retention policy"),
+	}, tonic.Handler(controllersv1.BentoRepositoryController.DryRunRetentionPolicy, 200))
+
+	resourceGrp.GET("/aliases", []fizz.OperationOption{
+		fizz.ID("List bento repository aliases"),
+		fizz.Summary("List bento repository aliases"),
+	}, tonic.Handler(controllersv1.BentoAliasController.List, 200))
+
+	resourceGrp.PUT("/aliases/:aliasName", []fizz.OperationOption{
+		fizz.ID("Set a bento alias"),
+		fizz.Summary("Set a bento alias"),
+	}, tonic.Handler(controllersv1.BentoAliasController.Set, 200))
+
+	resourceGrp.DELETE("/aliases/:aliasName", []fizz.OperationOption{
+		fizz.ID("Delete a bento alias"),
+		fizz.Summary("Delete a bento alias"),
+	}, tonic.Handler(controllersv1.BentoAliasController.Delete, 200))
+
 	resourceGrp.GET("/deployments", []fizz.OperationOption{
 		fizz.ID("List bento repository deployments"),
 		fizz.Summary("List bento repository deployments"),
@@ -607,6 +876,11 @@ func bentoRoutes(grp *fizz.RouterGroup) {
 		fizz.Summary("Update a bento"),
 	}, tonic.Handler(controllersv1.BentoController.Update, 200))
 
+	resourceGrp.DELETE("", []fizz.OperationOption{
+		fizz.ID("Delete a bento"),
+		fizz.Summary("Delete a bento"),
+	}, tonic.Handler(controllersv1.BentoController.Delete, 200))
+
 	resourceGrp.PATCH("/update_image_build_status_syncing_at", []fizz.OperationOption{
 		fizz.ID("Update a bento image build status syncing_at"),
 		fizz.Summary("Update a bento image build status syncing_at"),
@@ -689,7 +963,7 @@ func yataiComponentRoutes(grp *fizz.RouterGroup) {
 	grp.POST("", []fizz.OperationOption{
 		fizz.ID("Register yatai component"),
 		fizz.Summary("Register yatai component"),
-	}, tonic.Handler(controllersv1.YataiComponentController.Register, 200))
+	}, requireClientCert, tonic.Handler(controllersv1.YataiComponentController.Register, 200))
 }
 
 func deploymentRoutes(grp *fizz.RouterGroup) {
@@ -723,6 +997,66 @@ func deploymentRoutes(grp *fizz.RouterGroup) {
 		fizz.Summary("Delete a deployment"),
 	}, tonic.Handler(controllersv1.DeploymentController.Delete, 200))
 
+	resourceGrp.GET("/follow_policy", []fizz.OperationOption{
+		fizz.ID("Get a deployment follow policy"),
+		fizz.Summary("Get a deployment follow policy"),
+	}, tonic.Handler(controllersv1.DeploymentController.GetFollowPolicy, 200))
+
+	resourceGrp.PUT("/follow_policy", []fizz.OperationOption{
+		fizz.ID("Update a deployment follow policy"),
+		fizz.Summary("Update a deployment follow policy"),
+	}, tonic.Handler(controllersv1.DeploymentController.UpdateFollowPolicy, 200))
+
+	resourceGrp.DELETE("/follow_policy", []fizz.OperationOption{
+		fizz.ID("Delete a deployment follow policy"),
+		fizz.Summary("Delete a deployment follow policy"),
+	}, tonic.Handler(controllersv1.DeploymentController.DeleteFollowPolicy, 200))
+
+	resourceGrp.GET("/rollback_policy", []fizz.OperationOption{
+		fizz.ID("Get a deployment rollback policy"),
+		fizz.Summary("Get a deployment rollback policy"),
+	}, tonic.Handler(controllersv1.DeploymentController.GetRollbackPolicy, 200))
+
+	resourceGrp.PUT("/rollback_policy", []fizz.OperationOption{
+		fizz.ID("Update a deployment rollback policy"),
+		fizz.Summary("Update a deployment rollback policy"),
+	}, tonic.Handler(controllersv1.DeploymentController.UpdateRollbackPolicy, 200))
+
+	resourceGrp.GET("/timeline", []fizz.OperationOption{
+		fizz.ID("List a deployment timeline"),
+		fizz.Summary("List a deployment timeline"),
+	}, tonic.Handler(controllersv1.DeploymentController.ListTimeline, 200))
+
+	resourceGrp.GET("/canary_rollout", []fizz.OperationOption{
+		fizz.ID("Get a deployment canary rollout"),
+		fizz.Summary("Get a deployment canary rollout"),
+	}, tonic.Handler(controllersv1.CanaryRolloutController.Get, 200))
+
+	resourceGrp.PATCH("/canary_rollout", []fizz.OperationOption{
+		fizz.ID("Update a deployment canary rollout"),
+		fizz.Summary("Update a deployment canary rollout"),
+	}, tonic.Handler(controllersv1.CanaryRolloutController.Update, 200))
+
+	resourceGrp.POST("/canary_rollout/pause", []fizz.OperationOption{
+		fizz.ID("Pause a deployment canary rollout"),
+		fizz.Summary("Pause a deployment canary rollout"),
+	}, tonic.Handler(controllersv1.CanaryRolloutController.Pause, 200))
+
+	resourceGrp.POST("/canary_rollout/resume", []fizz.OperationOption{
+		fizz.ID("Resume a deployment canary rollout"),
+		fizz.Summary("Resume a deployment canary rollout"),
+	}, tonic.Handler(controllersv1.CanaryRolloutController.Resume, 200))
+
+	resourceGrp.POST("/canary_rollout/promote", []fizz.OperationOption{
+		fizz.ID("Promote a deployment canary"),
+		fizz.Summary("Promote a deployment canary"),
+	}, tonic.Handler(controllersv1.CanaryRolloutController.Promote, 200))
+
+	resourceGrp.POST("/canary_rollout/abort", []fizz.OperationOption{
+		fizz.ID("Abort a deployment canary"),
+		fizz.Summary("Abort a deployment canary"),
+	}, tonic.Handler(controllersv1.CanaryRolloutController.Abort, 200))
+
 	resourceGrp.GET("/terminal_records", []fizz.OperationOption{
 		fizz.ID("List deployment terminal records"),
 		fizz.Summary("List deployment terminal records"),
@@ -751,6 +1085,16 @@ func deploymentRevisionRoutes(grp *fizz.RouterGroup) {
 		fizz.Summary("Get a deployment revision"),
 	}, tonic.Handler(controllersv1.DeploymentRevisionController.Get, 200))
 
+	resourceGrp.POST("/rollback", []fizz.OperationOption{
+		fizz.ID("Rollback to a deployment revision"),
+		fizz.Summary("Rollback to a deployment revision"),
+	}, tonic.Handler(controllersv1.DeploymentRevisionController.Rollback, 200))
+
+	resourceGrp.GET("/diff", []fizz.OperationOption{
+		fizz.ID("Diff a deployment revision against another one"),
+		fizz.Summary("Diff a deployment revision against another one"),
+	}, tonic.Handler(controllersv1.DeploymentRevisionController.Diff, 200))
+
 	grp.GET("", []fizz.OperationOption{
 		fizz.ID("List deployment revisions"),
 		fizz.Summary("List deployment revisions"),
@@ -788,6 +1132,56 @@ func modelRepositoryRoutes(grp *fizz.RouterGroup) {
 		fizz.Summary("Update a model repository"),
 	}, tonic.Handler(controllersv1.ModelRepositoryController.Update, 200))
 
+	resourceGrp.DELETE("", []fizz.OperationOption{
+		fizz.ID("Delete a model repository"),
+		fizz.Summary("Delete a model repository"),
+	}, tonic.Handler(controllersv1.ModelRepositoryController.Delete, 200))
+
+	resourceGrp.GET("/members", []fizz.OperationOption{
+		fizz.ID("List model repository members"),
+		fizz.Summary("List model repository members"),
+	}, tonic.Handler(controllersv1.ModelRepositoryMemberController.List, 200))
+
+	resourceGrp.POST("/members", []fizz.OperationOption{
+		fizz.ID("Create a model repository member"),
+		fizz.Summary("Create a model repository member"),
+	}, tonic.Handler(controllersv1.ModelRepositoryMemberController.Create, 200))
+
+	resourceGrp.DELETE("/members", []fizz.OperationOption{
+		fizz.ID("Remove a model repository member"),
+		fizz.Summary("Remove a model repository member"),
+	}, tonic.Handler(controllersv1.ModelRepositoryMemberController.Delete, 200))
+
+	resourceGrp.GET("/retention_policy", []fizz.OperationOption{
+		fizz.ID("Get a model repository retention policy"),
+		fizz.Summary("Get a model repository retention policy"),
+	}, tonic.Handler(controllersv1.ModelRepositoryController.GetRetentionPolicy, 200))
+
+	resourceGrp.PUT("/retention_policy", []fizz.OperationOption{
+		fizz.ID("Update a model repository retention policy"),
+		fizz.Summary("Update a model repository retention policy"),
+	}, tonic.Handler(controllersv1.ModelRepositoryController.UpdateRetentionPolicy, 200))
+
+	resourceGrp.GET("/retention_policy/dry_run", []fizz.OperationOption{
+		fizz.ID("Dry run a model repository retention policy"),
+		fizz.Summary("Dry run a model repository retention policy"),
+	}, tonic.Handler(controllersv1.ModelRepositoryController.DryRunRetentionPolicy, 200))
+
+	resourceGrp.GET("/aliases", []fizz.OperationOption{
+		fizz.ID("List model repository aliases"),
+		fizz.Summary("List model repository aliases"),
+	}, tonic.Handler(controllersv1.ModelAliasController.List, 200))
+
+	resourceGrp.PUT("/aliases/:aliasName", []fizz.OperationOption{
+		fizz.ID("Set a model alias"),
+		fizz.Summary("Set a model alias"),
+	}, tonic.Handler(controllersv1.ModelAliasController.Set, 200))
+
+	resourceGrp.DELETE("/aliases/:aliasName", []fizz.OperationOption{
+		fizz.ID("Delete a model alias"),
+		fizz.Summary("Delete a model alias"),
+	}, tonic.Handler(controllersv1.ModelAliasController.Delete, 200))
+
 	grp.GET("", []fizz.OperationOption{
 		fizz.ID("List model repositories"),
 		fizz.Summary("List model repositories"),
@@ -816,6 +1210,11 @@ func modelRoutes(grp *fizz.RouterGroup) {
 		fizz.Summary("Update a model"),
 	}, tonic.Handler(controllersv1.ModelController.Update, 200))
 
+	resourceGrp.DELETE("", []fizz.OperationOption{
+		fizz.ID("Delete a model"),
+		fizz.Summary("Delete a model"),
+	}, tonic.Handler(controllersv1.ModelController.Delete, 200))
+
 	resourceGrp.GET("/bentos", []fizz.OperationOption{
 		fizz.ID("List model bentos"),
 		fizz.Summary("List model bentos"),
@@ -2,11 +2,16 @@ package services
 
 import (
 	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net"
 	"strings"
 	"time"
 
 	"github.com/pkg/errors"
-	"github.com/rs/xid"
+	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 	"k8s.io/apimachinery/pkg/util/validation"
 
@@ -17,6 +22,16 @@ import (
 	"github.com/bentoml/yatai/common/utils"
 )
 
+const (
+	apiTokenPrefix       = "yt"
+	apiTokenPrefixLength = 8
+	apiTokenSecretLength = 32
+	apiTokenSaltLength   = 16
+)
+
+// DefaultApiTokenRotationOverlap is how long the previous secret keeps working after a rotation
+const DefaultApiTokenRotationOverlap = 24 * time.Hour
+
 type apiTokenService struct{}
 
 var ApiTokenService = apiTokenService{}
@@ -26,19 +41,23 @@ func (*apiTokenService) getBaseDB(ctx context.Context) *gorm.DB {
 }
 
 type CreateApiTokenOption struct {
-	UserId         uint
-	OrganizationId uint
-	Name           string
-	Description    string
-	Scopes         *modelschemas.ApiTokenScopes
-	ExpiredAt      *time.Time
+	UserId            uint
+	OrganizationId    uint
+	Name              string
+	Description       string
+	Scopes            *modelschemas.ApiTokenScopes
+	ResourceSelectors *models.ApiTokenResourceSelectors
+	AllowedCidrs      *models.ApiTokenAllowedCidrs
+	ExpiredAt         *time.Time
 }
 
 type UpdateApiTokenOption struct {
-	Description *string
-	Scopes      **modelschemas.ApiTokenScopes
-	ExpiredAt   **time.Time
-	LastUsedAt  **time.Time
+	Description       *string
+	Scopes            **modelschemas.ApiTokenScopes
+	ResourceSelectors **models.ApiTokenResourceSelectors
+	AllowedCidrs      **models.ApiTokenAllowedCidrs
+	ExpiredAt         **time.Time
+	LastUsedAt        **time.Time
 }
 
 type ListApiTokenOption struct {
@@ -54,9 +73,13 @@ func (s *apiTokenService) Create(ctx context.Context, opt CreateApiTokenOption)
 	if len(errs) > 0 {
 		return nil, errors.New(strings.Join(errs, ";"))
 	}
+	err := s.validateAllowedCidrs(opt.AllowedCidrs)
+	if err != nil {
+		return nil, err
+	}
 
-	guid := xid.New()
-	token := guid.String()
+	prefix := strings.ToLower(utils.RandAlphanumString(apiTokenPrefixLength))
+	token := s.makeToken(prefix)
 
 	apiToken := models.ApiToken{
 		ResourceMixin: models.ResourceMixin{
@@ -69,17 +92,116 @@ func (s *apiTokenService) Create(ctx context.Context, opt CreateApiTokenOption)
 		OrganizationAssociate: models.OrganizationAssociate{
 			OrganizationId: opt.OrganizationId,
 		},
-		Token:     token,
-		Scopes:    opt.Scopes,
-		ExpiredAt: opt.ExpiredAt,
+		TokenPrefix:       prefix,
+		TokenHash:         s.hashToken(token),
+		Scopes:            opt.Scopes,
+		ResourceSelectors: opt.ResourceSelectors,
+		AllowedCidrs:      opt.AllowedCidrs,
+		ExpiredAt:         opt.ExpiredAt,
 	}
-	err := mustGetSession(ctx).Create(&apiToken).Error
+	err = mustGetSession(ctx).Create(&apiToken).Error
 	if err != nil {
 		return nil, err
 	}
+	apiToken.RawToken = token
 	return &apiToken, err
 }
 
+func (s *apiTokenService) validateAllowedCidrs(allowedCidrs *models.ApiTokenAllowedCidrs) error {
+	if allowedCidrs == nil {
+		return nil
+	}
+	for _, cidr := range *allowedCidrs {
+		_, _, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return errors.Wrapf(err, "invalid allowed cidr %s", cidr)
+		}
+	}
+	return nil
+}
+
+// Rotate issues a new secret with the same prefix, the previous secret keeps working during the overlap.
+func (s *apiTokenService) Rotate(ctx context.Context, apiToken *models.ApiToken, overlap time.Duration) (*models.ApiToken, error) {
+	token := s.makeToken(apiToken.TokenPrefix)
+	tokenHash := s.hashToken(token)
+	var previousTokenHash *string
+	var previousTokenExpiredAt *time.Time
+	if overlap > 0 {
+		previousTokenHash = &apiToken.TokenHash
+		previousTokenExpiredAt_ := time.Now().Add(overlap)
+		previousTokenExpiredAt = &previousTokenExpiredAt_
+	}
+	err := s.getBaseDB(ctx).Where("id = ?", apiToken.ID).Updates(map[string]interface{}{
+		"token_hash":                tokenHash,
+		"previous_token_hash":       previousTokenHash,
+		"previous_token_expired_at": previousTokenExpiredAt,
+	}).Error
+	if err != nil {
+		return nil, err
+	}
+	apiToken.TokenHash = tokenHash
+	apiToken.PreviousTokenHash = previousTokenHash
+	apiToken.PreviousTokenExpiredAt = previousTokenExpiredAt
+	apiToken.RawToken = token
+	return apiToken, nil
+}
+
+func (s *apiTokenService) makeToken(prefix string) string {
+	return fmt.Sprintf("%s_%s_%s", apiTokenPrefix, prefix, utils.RandAlphanumString(apiTokenSecretLength))
+}
+
+// getTokenPrefix returns the visible prefix of the token, the legacy tokens are plain xids.
+func (s *apiTokenService) getTokenPrefix(token string) string {
+	pieces := strings.SplitN(token, "_", 3)
+	if len(pieces) == 3 && pieces[0] == apiTokenPrefix {
+		return pieces[1]
+	}
+	if len(token) > apiTokenPrefixLength {
+		return token[:apiTokenPrefixLength]
+	}
+	return token
+}
+
+// hashToken returns the salted sha256 of the token as salt$hash, the tokens are random enough
+// for a fast hash, so the lookup on every request stays cheap.
+func (s *apiTokenService) hashToken(token string) string {
+	salt := utils.RandAlphanumString(apiTokenSaltLength)
+	sum := sha256.Sum256([]byte(salt + token))
+	return fmt.Sprintf("%s$%s", salt, hex.EncodeToString(sum[:]))
+}
+
+func (s *apiTokenService) verifyToken(tokenHash, token string) bool {
+	salt, hash, ok := strings.Cut(tokenHash, "$")
+	if !ok {
+		return false
+	}
+	sum := sha256.Sum256([]byte(salt + token))
+	return subtle.ConstantTimeCompare([]byte(hash), []byte(hex.EncodeToString(sum[:]))) == 1
+}
+
+// HashLegacyTokens replaces the plaintext tokens stored before the tokens were hashed.
+func (s *apiTokenService) HashLegacyTokens(ctx context.Context) error {
+	apiTokens := make([]*models.ApiToken, 0)
+	err := getBaseQuery(ctx, s).Where("token IS NOT NULL").Find(&apiTokens).Error
+	if err != nil {
+		return err
+	}
+	for _, apiToken := range apiTokens {
+		err = s.getBaseDB(ctx).Where("id = ?", apiToken.ID).Updates(map[string]interface{}{
+			"token":        nil,
+			"token_prefix": s.getTokenPrefix(*apiToken.Token),
+			"token_hash":   s.hashToken(*apiToken.Token),
+		}).Error
+		if err != nil {
+			return errors.Wrapf(err, "hash api token %s", apiToken.Name)
+		}
+	}
+	if len(apiTokens) > 0 {
+		logrus.Infof("hashed %d plaintext api tokens", len(apiTokens))
+	}
+	return nil
+}
+
 func (s *apiTokenService) Update(ctx context.Context, c *models.ApiToken, opt UpdateApiTokenOption) (*models.ApiToken, error) {
 	var err error
 	updaters := make(map[string]interface{})
@@ -99,6 +221,26 @@ func (s *apiTokenService) Update(ctx context.Context, c *models.ApiToken, opt Up
 			}
 		}()
 	}
+	if opt.AllowedCidrs != nil {
+		err = s.validateAllowedCidrs(*opt.AllowedCidrs)
+		if err != nil {
+			return nil, err
+		}
+		updaters["allowed_cidrs"] = *opt.AllowedCidrs
+		defer func() {
+			if err == nil {
+				c.AllowedCidrs = *opt.AllowedCidrs
+			}
+		}()
+	}
+	if opt.ResourceSelectors != nil {
+		updaters["resource_selectors"] = *opt.ResourceSelectors
+		defer func() {
+			if err == nil {
+				c.ResourceSelectors = *opt.ResourceSelectors
+			}
+		}()
+	}
 	if opt.ExpiredAt != nil {
 		updaters["expired_at"] = *opt.ExpiredAt
 		defer func() {
@@ -228,15 +370,21 @@ func (s *apiTokenService) GetByToken(ctx context.Context, token string) (*models
 		}
 		return apiToken, nil
 	}
-	var apiToken models.ApiToken
-	err := getBaseQuery(ctx, s).Where("token = ?", token).First(&apiToken).Error
+	apiTokens := make([]*models.ApiToken, 0)
+	err := getBaseQuery(ctx, s).Where("token_prefix = ?", s.getTokenPrefix(token)).Find(&apiTokens).Error
 	if err != nil {
 		return nil, err
 	}
-	if apiToken.ID == 0 {
-		return nil, consts.ErrNotFound
+	now := time.Now()
+	for _, apiToken := range apiTokens {
+		if s.verifyToken(apiToken.TokenHash, token) {
+			return apiToken, nil
+		}
+		if apiToken.PreviousTokenHash != nil && apiToken.PreviousTokenExpiredAt != nil && now.Before(*apiToken.PreviousTokenExpiredAt) && s.verifyToken(*apiToken.PreviousTokenHash, token) {
+			return apiToken, nil
+		}
 	}
-	return &apiToken, nil
+	return nil, consts.ErrNotFound
 }
 
 func (s *apiTokenService) GetByName(ctx context.Context, organizationId, userId uint, name string) (*models.ApiToken, error) {
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/common/utils"
+)
+
+const (
+	apiTokenUsageUserAgentMaxLength   = 512
+	apiTokenUsageOperationIdMaxLength = 128
+
+	ApiTokenUsageRetention = 90 * 24 * time.Hour
+)
+
+// the usages are aggregated in memory and written in batches, so the requests do not write the db
+const apiTokenUsageFlushInterval = 30 * time.Second
+
+type apiTokenUsageKey struct {
+	ApiTokenId  uint
+	Minute      time.Time
+	ClientIp    string
+	UserAgent   string
+	OperationId string
+}
+
+type apiTokenUsageService struct {
+	mu      sync.Mutex
+	pending map[apiTokenUsageKey]uint
+}
+
+var ApiTokenUsageService = apiTokenUsageService{}
+
+func (s *apiTokenUsageService) getBaseDB(ctx context.Context) *gorm.DB {
+	return mustGetSession(ctx).Model(&models.ApiTokenUsage{})
+}
+
+type RecordApiTokenUsageOption struct {
+	ApiTokenId  uint
+	ClientIp    string
+	UserAgent   string
+	OperationId string
+}
+
+type ListApiTokenUsageOption struct {
+	BaseListOption
+	ApiTokenId *uint
+}
+
+// Record counts the usage in the minute of now, the counts are written by Flush.
+func (s *apiTokenUsageService) Record(opt RecordApiTokenUsageOption) {
+	key := apiTokenUsageKey{
+		ApiTokenId: opt.ApiTokenId,
+		Minute:     time.Now().Truncate(time.Minute),
+		ClientIp:   opt.ClientIp,
+		// the headers may carry invalid utf-8, which postgres rejects
+		UserAgent:   utils.TruncateString(strings.ToValidUTF8(opt.UserAgent, ""), apiTokenUsageUserAgentMaxLength),
+		OperationId: utils.TruncateString(opt.OperationId, apiTokenUsageOperationIdMaxLength),
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.pending == nil {
+		s.pending = make(map[apiTokenUsageKey]uint)
+	}
+	s.pending[key]++
+}
+
+// Flush adds the recorded counts to the usage rows of their minutes, the counts are kept for the
+// next flush if the db cannot be written.
+func (s *apiTokenUsageService) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	pending := s.pending
+	s.pending = nil
+	s.mu.Unlock()
+	if len(pending) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	apiTokenUsages := make([]*models.ApiTokenUsage, 0, len(pending))
+	for key, count := range pending {
+		apiTokenUsages = append(apiTokenUsages, &models.ApiTokenUsage{
+			ApiTokenId:  key.ApiTokenId,
+			Minute:      key.Minute,
+			ClientIp:    key.ClientIp,
+			UserAgent:   key.UserAgent,
+			OperationId: key.OperationId,
+			Count:       count,
+		})
+	}
+	err := s.getBaseDB(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "api_token_id"}, {Name: "minute"}, {Name: "client_ip"}, {Name: "user_agent"}, {Name: "operation_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"count":      gorm.Expr("api_token_usage.count + excluded.count"),
+			"updated_at": now,
+		}),
+	}).Create(&apiTokenUsages).Error
+	if err != nil {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.pending == nil {
+			s.pending = make(map[apiTokenUsageKey]uint, len(pending))
+		}
+		for key, count := range pending {
+			s.pending[key] += count
+		}
+		return errors.Wrap(err, "flush api token usages")
+	}
+	return nil
+}
+
+// Run flushes the recorded usages periodically until ctx is done, every replica records its own usages.
+func (s *apiTokenUsageService) Run(ctx context.Context) {
+	ticker := time.NewTicker(apiTokenUsageFlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := s.Flush(ctx); err != nil {
+			logrus.Error(err.Error())
+		}
+	}
+}
+
+func (s *apiTokenUsageService) List(ctx context.Context, opt ListApiTokenUsageOption) ([]*models.ApiTokenUsage, uint, error) {
+	query := getBaseQuery(ctx, s)
+	if opt.ApiTokenId != nil {
+		query = query.Where("api_token_id = ?", *opt.ApiTokenId)
+	}
+	var total int64
+	err := query.Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	query = opt.BindQueryWithLimit(query)
+	apiTokenUsages := make([]*models.ApiTokenUsage, 0)
+	err = query.Order("minute DESC").Order("id DESC").Find(&apiTokenUsages).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return apiTokenUsages, uint(total), err
+}
+
+// PruneBefore deletes the usages recorded before t.
+func (s *apiTokenUsageService) PruneBefore(ctx context.Context, t time.Time) error {
+	return mustGetSession(ctx).Unscoped().Where("minute < ?", t).Delete(&models.ApiTokenUsage{}).Error
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/bentoml/yatai/api-server/config"
+	"github.com/bentoml/yatai/api-server/models"
+)
+
+var ErrInvalidCredentials = errors.New("invalid username or password")
+
+// Authenticator checks the credentials of a password login
+type Authenticator interface {
+	Name() string
+	Authenticate(ctx context.Context, nameOrEmail, password string) (*models.User, error)
+}
+
+type authService struct{}
+
+var AuthService = authService{}
+
+func (s *authService) getAuthenticators() []Authenticator {
+	authenticators := make([]Authenticator, 0, 2)
+	if config.YataiConfig.LDAP != nil {
+		authenticators = append(authenticators, &LDAPService)
+	}
+	// the local password login is the fallback of the other authenticators
+	if !config.YataiConfig.DisableLocalLogin {
+		authenticators = append(authenticators, &localAuthenticator{})
+	}
+	return authenticators
+}
+
+// Login tries the authenticators in order and returns the user of the first one accepting the credentials
+func (s *authService) Login(ctx context.Context, nameOrEmail, password string) (*models.User, error) {
+	for _, authenticator := range s.getAuthenticators() {
+		user, err := authenticator.Authenticate(ctx, nameOrEmail, password)
+		if err == nil {
+			return user, nil
+		}
+		if errors.Is(err, ErrInvalidCredentials) {
+			logrus.Debugf("%s authenticator rejected the login of %s", authenticator.Name(), nameOrEmail)
+			continue
+		}
+		logrus.Errorf("%s authenticator failed to login %s: %s", authenticator.Name(), nameOrEmail, err.Error())
+	}
+	return nil, ErrInvalidCredentials
+}
+
+type localAuthenticator struct{}
+
+func (a *localAuthenticator) Name() string {
+	return "local"
+}
+
+func (a *localAuthenticator) Authenticate(ctx context.Context, nameOrEmail, password string) (*models.User, error) {
+	isEmail := strings.Contains(nameOrEmail, "@")
+	var err error
+	var user *models.User
+	if isEmail {
+		user, err = UserService.GetByEmail(ctx, nameOrEmail)
+	} else {
+		user, err = UserService.GetByName(ctx, nameOrEmail)
+	}
+	if err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	if user.Email == nil || *user.Email == "" {
+		return nil, errors.Errorf("user %s email is empty, it looks like yatai did not complete the setup process", user.Name)
+	}
+	if err = UserService.CheckPassword(ctx, user, password); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	return user, nil
+}
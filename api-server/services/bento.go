@@ -22,6 +22,7 @@ import (
 	"github.com/bentoml/yatai-schemas/modelschemas"
 	"github.com/bentoml/yatai/api-server/models"
 	"github.com/bentoml/yatai/common/consts"
+	"github.com/bentoml/yatai/common/metrics"
 	"github.com/bentoml/yatai/common/utils"
 )
 
@@ -67,6 +68,9 @@ type ListBentoOption struct {
 	Order             *string
 	Names             *[]string
 	Ids               *[]uint
+	UploadStatus      *modelschemas.BentoUploadStatus
+	// VisitorId filters out the bentos of the private repositories which the visitor cannot view
+	VisitorId *uint
 }
 
 func (s *bentoService) Create(ctx context.Context, opt CreateBentoOption) (bento *models.Bento, err error) {
@@ -165,7 +169,9 @@ func (s *bentoService) PreSignUploadUrl(ctx context.Context, bento *models.Bento
 		return
 	}
 
+	start := time.Now()
 	url, err = minioClient.PresignedPutObject(ctx, bucketName, objectName, time.Hour)
+	metrics.ObserveS3Operation("presigned_put_object", start, err)
 	if err != nil {
 		err = errors.Wrap(err, "presigned put object")
 		return
@@ -210,7 +216,9 @@ func (s *bentoService) StartMultipartUpload(ctx context.Context, bento *models.B
 		return
 	}
 
+	start := time.Now()
 	uploadId, err = minioCore.NewMultipartUpload(ctx, bucketName, objectName, minio.PutObjectOptions{})
+	metrics.ObserveS3Operation("new_multipart_upload", start, err)
 	if err != nil {
 		err = errors.Wrap(err, "new multipart upload")
 		return
@@ -256,7 +264,9 @@ func (s *bentoService) PreSignMultipartUploadUrl(ctx context.Context, bento *mod
 	queryValues.Set("partNumber", strconv.Itoa(partNumber))
 	queryValues.Set("uploadId", uploadId)
 
+	start := time.Now()
 	url_, err = minioCore.Presign(ctx, http.MethodPut, bucketName, objectName, time.Hour, queryValues)
+	metrics.ObserveS3Operation("presign", start, err)
 	if err != nil {
 		err = errors.Wrap(err, "presigned put object")
 		return
@@ -301,7 +311,9 @@ func (s *bentoService) CompleteMultipartUpload(ctx context.Context, bento *model
 		return
 	}
 
+	start := time.Now()
 	_, err = minioCore.CompleteMultipartUpload(ctx, bucketName, objectName, uploadId, parts, minio.PutObjectOptions{})
+	metrics.ObserveS3Operation("complete_multipart_upload", start, err)
 	if err != nil {
 		err = errors.Wrap(err, "new multipart upload")
 		return
@@ -344,7 +356,9 @@ func (s *bentoService) Upload(ctx context.Context, bento *models.Bento, reader i
 	}
 
 	logrus.Debugf("uploading to s3: %s/%s", bucketName, objectName)
+	start := time.Now()
 	_, err = minioClient.PutObject(ctx, bucketName, objectName, reader, objectSize, minio.PutObjectOptions{ContentType: "application/octet-stream"})
+	metrics.ObserveS3Operation("put_object", start, err)
 	if err != nil {
 		err = errors.Wrap(err, "put object")
 		return
@@ -388,7 +402,9 @@ func (s *bentoService) PreSignDownloadUrl(ctx context.Context, bento *models.Ben
 		return
 	}
 
+	start := time.Now()
 	url, err = minioClient.PresignedGetObject(ctx, bucketName, objectName, time.Hour, nil)
+	metrics.ObserveS3Operation("presigned_get_object", start, err)
 	if err != nil {
 		err = errors.Wrap(err, "presigned get object")
 		return
@@ -433,7 +449,9 @@ func (s *bentoService) Download(ctx context.Context, bento *models.Bento, writer
 		return
 	}
 
+	start := time.Now()
 	obj, err := minioClient.GetObject(ctx, bucketName, objectName, minio.GetObjectOptions{})
+	metrics.ObserveS3Operation("get_object", start, err)
 	if err != nil {
 		err = errors.Wrap(err, "get object")
 		return
@@ -447,6 +465,137 @@ func (s *bentoService) Download(ctx context.Context, bento *models.Bento, writer
 	return
 }
 
+func (s *bentoService) removeS3Object(ctx context.Context, bento *models.Bento) (err error) {
+	bentoRepository, err := BentoRepositoryService.GetAssociatedBentoRepository(ctx, bento)
+	if err != nil {
+		return
+	}
+	org, err := OrganizationService.GetAssociatedOrganization(ctx, bentoRepository)
+	if err != nil {
+		return
+	}
+	s3Config, err := OrganizationService.GetS3Config(ctx, org)
+	if err != nil {
+		return
+	}
+	minioClient, err := s3Config.GetMinioClient()
+	if err != nil {
+		err = errors.Wrap(err, "create s3 client")
+		return
+	}
+
+	bucketName, err := s.GetS3BucketName(ctx, bento)
+	if err != nil {
+		return
+	}
+
+	objectName, err := s.getS3ObjectName(ctx, bento)
+	if err != nil {
+		return
+	}
+
+	logrus.Debugf("removing from s3: %s/%s", bucketName, objectName)
+	start := time.Now()
+	err = minioClient.RemoveObject(ctx, bucketName, objectName, minio.RemoveObjectOptions{})
+	metrics.ObserveS3Operation("remove_object", start, err)
+	if err != nil {
+		err = errors.Wrap(err, "remove object")
+		return
+	}
+	return
+}
+
+func (s *bentoService) Delete(ctx context.Context, bento *models.Bento) (*models.Bento, error) {
+	bentoRepository, err := BentoRepositoryService.GetAssociatedBentoRepository(ctx, bento)
+	if err != nil {
+		return nil, err
+	}
+	org, err := OrganizationService.GetAssociatedOrganization(ctx, bentoRepository)
+	if err != nil {
+		return nil, err
+	}
+
+	_, total, err := DeploymentTargetService.List(ctx, ListDeploymentTargetOption{
+		DeploymentRevisionStatus: modelschemas.DeploymentRevisionStatusActive.Ptr(),
+		BentoIds:                 &[]uint{bento.ID},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "list deployment targets")
+	}
+	if total > 0 {
+		return nil, errors.Errorf("bento %s:%s is still used by %d active deployment targets", bentoRepository.Name, bento.Version, total)
+	}
+	// the deployment targets are deleted with the bento, the inactive revisions have to keep them for rollbacks and diffs
+	deploymentTargets, _, err := DeploymentTargetService.List(ctx, ListDeploymentTargetOption{
+		BentoIds: &[]uint{bento.ID},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "list deployment targets")
+	}
+	if len(deploymentTargets) > 0 {
+		deploymentIds := make([]uint, 0, len(deploymentTargets))
+		for _, deploymentTarget := range deploymentTargets {
+			deploymentIds = append(deploymentIds, deploymentTarget.DeploymentId)
+		}
+		deployments, _, err := DeploymentService.List(ctx, ListDeploymentOption{
+			Ids: &deploymentIds,
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "list deployments")
+		}
+		deploymentNames := make([]string, 0, len(deployments))
+		for _, deployment := range deployments {
+			deploymentNames = append(deploymentNames, deployment.Name)
+		}
+		return nil, errors.Errorf("bento %s:%s is still referenced by the revision history of deployments %s", bentoRepository.Name, bento.Version, strings.Join(deploymentNames, ", "))
+	}
+
+	bentoAliases, err := BentoAliasService.List(ctx, ListBentoAliasOption{
+		BentoIds: &[]uint{bento.ID},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "list bento aliases")
+	}
+	if len(bentoAliases) > 0 {
+		return nil, errors.Errorf("bento %s:%s is still referenced by alias %s", bentoRepository.Name, bento.Version, bentoAliases[0].Name)
+	}
+
+	// nolint: ineffassign,staticcheck
+	db, ctx, df, err := startTransaction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { df(err) }()
+
+	err = LabelService.DeleteByResource(ctx, bento)
+	if err != nil {
+		return nil, errors.Wrap(err, "delete labels")
+	}
+
+	_, err = EventService.CreateByCurrentUser(ctx, CreateEventOption{
+		OrganizationId: &org.ID,
+		ResourceType:   modelschemas.ResourceTypeBento,
+		ResourceId:     bento.ID,
+		Status:         modelschemas.EventStatusSuccess,
+		OperationName:  "deleted",
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "create event")
+	}
+
+	err = db.Unscoped().Delete(bento).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.removeS3Object(ctx, bento)
+	if err != nil {
+		return nil, err
+	}
+
+	return bento, nil
+}
+
 func (s *bentoService) getS3ObjectName(ctx context.Context, bento *models.Bento) (string, error) {
 	bentoRepository, err := BentoRepositoryService.GetAssociatedBentoRepository(ctx, bento)
 	if err != nil {
@@ -647,6 +796,22 @@ func (s *bentoService) GetByVersion(ctx context.Context, bentoRepositoryId uint,
 	return &bento, nil
 }
 
+// GetByVersionOrAlias falls back to resolve the version as an alias of the repository
+func (s *bentoService) GetByVersionOrAlias(ctx context.Context, bentoRepositoryId uint, versionOrAlias string) (*models.Bento, error) {
+	bento, err := s.GetByVersion(ctx, bentoRepositoryId, versionOrAlias)
+	if err == nil || !utils.IsNotFound(err) {
+		return bento, err
+	}
+	bentoAlias, aliasErr := BentoAliasService.GetByName(ctx, bentoRepositoryId, versionOrAlias)
+	if aliasErr != nil {
+		if utils.IsNotFound(aliasErr) {
+			return nil, err
+		}
+		return nil, aliasErr
+	}
+	return s.GetAssociatedBento(ctx, bentoAlias)
+}
+
 func (s *bentoService) ListByUids(ctx context.Context, uids []string) ([]*models.Bento, error) {
 	bentos := make([]*models.Bento, 0, len(uids))
 	if len(uids) == 0 {
@@ -730,6 +895,16 @@ func (s *bentoService) List(ctx context.Context, opt ListBentoOption) ([]*models
 	if opt.CreatorIds != nil {
 		query = query.Where("bento.creator_id in (?)", *opt.CreatorIds)
 	}
+	if opt.UploadStatus != nil {
+		query = query.Where("bento.upload_status = ?", *opt.UploadStatus)
+	}
+	if opt.VisitorId != nil {
+		var err error
+		query, err = BentoRepositoryMemberService.BindVisibleQuery(ctx, query, "bento_repository", *opt.VisitorId, opt.OrganizationId)
+		if err != nil {
+			return nil, 0, errors.Wrap(err, "bind visible query")
+		}
+	}
 	query = opt.BindQueryWithKeywords(query, "bento_repository")
 	query = opt.BindQueryWithLabels(query, modelschemas.ResourceTypeBento)
 	query = query.Select("distinct(bento.*)")
@@ -0,0 +1,216 @@
+package services
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/bentoml/yatai-schemas/modelschemas"
+	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/common/consts"
+	"github.com/bentoml/yatai/common/utils"
+)
+
+type bentoAliasService struct{}
+
+var BentoAliasService = bentoAliasService{}
+
+func (s *bentoAliasService) getBaseDB(ctx context.Context) *gorm.DB {
+	return mustGetSession(ctx).Model(&models.BentoAlias{})
+}
+
+type SetBentoAliasOption struct {
+	CreatorId         uint
+	BentoRepositoryId uint
+	BentoId           uint
+	Name              string
+}
+
+type ListBentoAliasOption struct {
+	BentoRepositoryId *uint
+	BentoIds          *[]uint
+	Names             *[]string
+}
+
+// Set creates the alias or moves it to another bento, every move is recorded as an event.
+func (s *bentoAliasService) Set(ctx context.Context, opt SetBentoAliasOption) (bentoAlias *models.BentoAlias, err error) {
+	errs := validation.IsDNS1123Label(opt.Name)
+	if len(errs) > 0 {
+		err = errors.New(strings.Join(errs, ";"))
+		return
+	}
+
+	bento, err := BentoService.Get(ctx, opt.BentoId)
+	if err != nil {
+		return
+	}
+	if bento.BentoRepositoryId != opt.BentoRepositoryId {
+		err = errors.Errorf("bento %s does not belong to this bento repository", bento.Version)
+		return
+	}
+	_, err = BentoService.GetByVersion(ctx, opt.BentoRepositoryId, opt.Name)
+	if err == nil {
+		err = errors.Errorf("alias %s conflicts with an existing bento version", opt.Name)
+		return
+	}
+	if !utils.IsNotFound(err) {
+		return
+	}
+
+	// nolint: ineffassign,staticcheck
+	db, ctx, df, err := startTransaction(ctx)
+	if err != nil {
+		return
+	}
+	defer func() { df(err) }()
+
+	bentoAlias, err = s.GetByName(ctx, opt.BentoRepositoryId, opt.Name)
+	isNotFound := utils.IsNotFound(err)
+	if err != nil && !isNotFound {
+		return
+	}
+	if isNotFound {
+		bentoAlias = &models.BentoAlias{
+			ResourceMixin: models.ResourceMixin{
+				Name: opt.Name,
+			},
+			CreatorAssociate: models.CreatorAssociate{
+				CreatorId: opt.CreatorId,
+			},
+			BentoRepositoryAssociate: models.BentoRepositoryAssociate{
+				BentoRepositoryId: opt.BentoRepositoryId,
+			},
+			BentoAssociate: models.BentoAssociate{
+				BentoId: opt.BentoId,
+			},
+		}
+		err = db.Create(bentoAlias).Error
+		if err != nil {
+			return
+		}
+	} else {
+		if bentoAlias.BentoId == opt.BentoId {
+			return
+		}
+		err = db.Model(&models.BentoAlias{}).Where("id = ?", bentoAlias.ID).Updates(map[string]interface{}{
+			"bento_id":   opt.BentoId,
+			"creator_id": opt.CreatorId,
+		}).Error
+		if err != nil {
+			return
+		}
+		bentoAlias.BentoId = opt.BentoId
+		bentoAlias.CreatorId = opt.CreatorId
+		bentoAlias.AssociatedCreatorCache = nil
+	}
+	bentoAlias.AssociatedBentoCache = bento
+
+	org, err := s.getOrganization(ctx, bento)
+	if err != nil {
+		return
+	}
+	_, err = EventService.CreateByCurrentUser(ctx, CreateEventOption{
+		Name:           opt.Name,
+		OrganizationId: &org.ID,
+		ResourceType:   modelschemas.ResourceTypeBento,
+		ResourceId:     bento.ID,
+		Status:         modelschemas.EventStatusSuccess,
+		OperationName:  "aliased",
+	})
+	if err != nil {
+		err = errors.Wrap(err, "create event")
+	}
+	return
+}
+
+func (s *bentoAliasService) Delete(ctx context.Context, bentoAlias *models.BentoAlias) (*models.BentoAlias, error) {
+	bento, err := BentoService.GetAssociatedBento(ctx, bentoAlias)
+	if err != nil {
+		return nil, err
+	}
+	org, err := s.getOrganization(ctx, bento)
+	if err != nil {
+		return nil, err
+	}
+
+	// nolint: ineffassign,staticcheck
+	db, ctx, df, err := startTransaction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { df(err) }()
+
+	_, err = EventService.CreateByCurrentUser(ctx, CreateEventOption{
+		Name:           bentoAlias.Name,
+		OrganizationId: &org.ID,
+		ResourceType:   modelschemas.ResourceTypeBento,
+		ResourceId:     bento.ID,
+		Status:         modelschemas.EventStatusSuccess,
+		OperationName:  "unaliased",
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "create event")
+	}
+
+	err = db.Unscoped().Delete(bentoAlias).Error
+	if err != nil {
+		return nil, err
+	}
+	return bentoAlias, nil
+}
+
+func (s *bentoAliasService) getOrganization(ctx context.Context, bento *models.Bento) (*models.Organization, error) {
+	bentoRepository, err := BentoRepositoryService.GetAssociatedBentoRepository(ctx, bento)
+	if err != nil {
+		return nil, err
+	}
+	return OrganizationService.GetAssociatedOrganization(ctx, bentoRepository)
+}
+
+func (s *bentoAliasService) GetByName(ctx context.Context, bentoRepositoryId uint, name string) (*models.BentoAlias, error) {
+	var bentoAlias models.BentoAlias
+	err := getBaseQuery(ctx, s).Where("bento_repository_id = ?", bentoRepositoryId).Where("name = ?", name).First(&bentoAlias).Error
+	if err != nil {
+		return nil, errors.Wrapf(err, "get bento alias %s", name)
+	}
+	if bentoAlias.ID == 0 {
+		return nil, consts.ErrNotFound
+	}
+	return &bentoAlias, nil
+}
+
+func (s *bentoAliasService) List(ctx context.Context, opt ListBentoAliasOption) ([]*models.BentoAlias, error) {
+	query := getBaseQuery(ctx, s)
+	if opt.BentoRepositoryId != nil {
+		query = query.Where("bento_repository_id = ?", *opt.BentoRepositoryId)
+	}
+	if opt.BentoIds != nil {
+		query = query.Where("bento_id in (?)", *opt.BentoIds)
+	}
+	if opt.Names != nil {
+		query = query.Where("name in (?)", *opt.Names)
+	}
+	bentoAliases := make([]*models.BentoAlias, 0)
+	err := query.Order("name ASC").Find(&bentoAliases).Error
+	return bentoAliases, err
+}
+
+func (s *bentoAliasService) GroupNamesByBentoIds(ctx context.Context, bentoIds []uint) (map[uint][]string, error) {
+	res := make(map[uint][]string, len(bentoIds))
+	if len(bentoIds) == 0 {
+		return res, nil
+	}
+	bentoAliases, err := s.List(ctx, ListBentoAliasOption{
+		BentoIds: &bentoIds,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, bentoAlias := range bentoAliases {
+		res[bentoAlias.BentoId] = append(res[bentoAlias.BentoId], bentoAlias.Name)
+	}
+	return res, nil
+}
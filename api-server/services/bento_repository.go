@@ -25,26 +25,38 @@ type CreateBentoRepositoryOption struct {
 	OrganizationId uint
 	Name           string
 	Labels         modelschemas.LabelItemsSchema
+	Visibility     models.RepositoryVisibility
 }
 
 type UpdateBentoRepositoryOption struct {
-	Description *string
-	Labels      *modelschemas.LabelItemsSchema
+	Description     *string
+	Labels          *modelschemas.LabelItemsSchema
+	RetentionPolicy **models.RetentionPolicy
+	Visibility      *models.RepositoryVisibility
 }
 
 type ListBentoRepositoryOption struct {
 	BaseListOption
 	BaseListByLabelsOption
-	OrganizationId *uint
-	CreatorId      *uint
-	CreatorIds     *[]uint
-	LastUpdaterIds *[]uint
-	Order          *string
-	Names          *[]string
-	Ids            *[]uint
+	OrganizationId     *uint
+	CreatorId          *uint
+	CreatorIds         *[]uint
+	LastUpdaterIds     *[]uint
+	Order              *string
+	Names              *[]string
+	Ids                *[]uint
+	HasRetentionPolicy *bool
+	// VisitorId filters out the private repositories which the visitor cannot view
+	VisitorId *uint
 }
 
 func (*bentoRepositoryService) Create(ctx context.Context, opt CreateBentoRepositoryOption) (*models.BentoRepository, error) {
+	if opt.Visibility == "" {
+		opt.Visibility = models.RepositoryVisibilityInternal
+	}
+	if !opt.Visibility.IsValid() {
+		return nil, errors.Errorf("invalid repository visibility %s", opt.Visibility)
+	}
 	bentoRepository := models.BentoRepository{
 		ResourceMixin: models.ResourceMixin{
 			Name: opt.Name,
@@ -55,11 +67,24 @@ func (*bentoRepositoryService) Create(ctx context.Context, opt CreateBentoReposi
 		OrganizationAssociate: models.OrganizationAssociate{
 			OrganizationId: opt.OrganizationId,
 		},
+		Visibility: opt.Visibility,
 	}
 	err := mustGetSession(ctx).Create(&bentoRepository).Error
 	if err != nil {
 		return nil, err
 	}
+	if opt.Visibility == models.RepositoryVisibilityPrivate {
+		// the creator must keep the access to the private repository
+		_, err = BentoRepositoryMemberService.Create(ctx, opt.CreatorId, CreateRepositoryMemberOption{
+			CreatorId:    opt.CreatorId,
+			UserId:       opt.CreatorId,
+			RepositoryId: bentoRepository.ID,
+			Role:         modelschemas.MemberRoleAdmin,
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "create repository member")
+		}
+	}
 	err = LabelService.CreateOrUpdateLabelsFromLabelItemsSchema(ctx, opt.Labels, opt.CreatorId, opt.OrganizationId, &bentoRepository)
 	return &bentoRepository, err
 }
@@ -75,6 +100,25 @@ func (s *bentoRepositoryService) Update(ctx context.Context, bentoRepository *mo
 			}
 		}()
 	}
+	if opt.RetentionPolicy != nil {
+		updaters["retention_policy"] = *opt.RetentionPolicy
+		defer func() {
+			if err == nil {
+				bentoRepository.RetentionPolicy = *opt.RetentionPolicy
+			}
+		}()
+	}
+	if opt.Visibility != nil {
+		if !opt.Visibility.IsValid() {
+			return nil, errors.Errorf("invalid repository visibility %s", *opt.Visibility)
+		}
+		updaters["visibility"] = *opt.Visibility
+		defer func() {
+			if err == nil {
+				bentoRepository.Visibility = *opt.Visibility
+			}
+		}()
+	}
 
 	if len(updaters) == 0 {
 		return bentoRepository, nil
@@ -103,6 +147,58 @@ func (s *bentoRepositoryService) Update(ctx context.Context, bentoRepository *mo
 	return bentoRepository, err
 }
 
+func (s *bentoRepositoryService) Delete(ctx context.Context, bentoRepository *models.BentoRepository) (*models.BentoRepository, error) {
+	_, total, err := BentoService.List(ctx, ListBentoOption{
+		BentoRepositoryId: &bentoRepository.ID,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "list bentos")
+	}
+	if total > 0 {
+		return nil, errors.Errorf("bento repository %s is not empty, it still has %d bentos", bentoRepository.Name, total)
+	}
+
+	// nolint: ineffassign,staticcheck
+	db, ctx, df, err := startTransaction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { df(err) }()
+
+	err = LabelService.DeleteByResource(ctx, bentoRepository)
+	if err != nil {
+		return nil, errors.Wrap(err, "delete labels")
+	}
+
+	err = UserGroupGrantService.DeleteByResource(ctx, bentoRepository)
+	if err != nil {
+		return nil, errors.Wrap(err, "delete user group grants")
+	}
+
+	err = BentoRepositoryMemberService.DeleteByRepository(ctx, bentoRepository)
+	if err != nil {
+		return nil, errors.Wrap(err, "delete repository members")
+	}
+
+	_, err = EventService.CreateByCurrentUser(ctx, CreateEventOption{
+		OrganizationId: &bentoRepository.OrganizationId,
+		ResourceType:   modelschemas.ResourceTypeBentoRepository,
+		ResourceId:     bentoRepository.ID,
+		Status:         modelschemas.EventStatusSuccess,
+		OperationName:  "deleted",
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "create event")
+	}
+
+	err = db.Unscoped().Delete(bentoRepository).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return bentoRepository, nil
+}
+
 func (s *bentoRepositoryService) Get(ctx context.Context, id uint) (*models.BentoRepository, error) {
 	var bentoRepository models.BentoRepository
 	err := getBaseQuery(ctx, s).Where("id = ?", id).First(&bentoRepository).Error
@@ -156,6 +252,20 @@ func (s *bentoRepositoryService) List(ctx context.Context, opt ListBentoReposito
 	if opt.CreatorIds != nil {
 		query = query.Where("bento_repository.creator_id in (?)", *opt.CreatorIds)
 	}
+	if opt.VisitorId != nil {
+		var err error
+		query, err = BentoRepositoryMemberService.BindVisibleQuery(ctx, query, "bento_repository", *opt.VisitorId, opt.OrganizationId)
+		if err != nil {
+			return nil, 0, errors.Wrap(err, "bind visible query")
+		}
+	}
+	if opt.HasRetentionPolicy != nil {
+		if *opt.HasRetentionPolicy {
+			query = query.Where("bento_repository.retention_policy IS NOT NULL")
+		} else {
+			query = query.Where("bento_repository.retention_policy IS NULL")
+		}
+	}
 	query = query.Joins("LEFT JOIN bento ON bento.bento_repository_id = bento_repository.id")
 	query = query.Joins("LEFT OUTER JOIN bento b2 ON b2.bento_repository_id = bento_repository.id AND bento.id < b2.id")
 	query = query.Where("b2.id IS NULL")
@@ -0,0 +1,487 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm/clause"
+
+	"github.com/bentoml/yatai-schemas/modelschemas"
+	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/common/utils"
+)
+
+type canaryRolloutService struct{}
+
+var CanaryRolloutService = canaryRolloutService{}
+
+type UpdateCanaryRolloutOption struct {
+	Steps               *[]uint
+	StepIntervalSeconds *uint
+}
+
+func (s *canaryRolloutService) getCanaryTarget(deploymentTargets []*models.DeploymentTarget) *models.DeploymentTarget {
+	for _, deploymentTarget := range deploymentTargets {
+		if deploymentTarget.Type == modelschemas.DeploymentTargetTypeCanary {
+			return deploymentTarget
+		}
+	}
+	return nil
+}
+
+// withOperator makes sure there is a current user in ctx, the deployment
+// creator is used when the rollout is progressed by cron.
+func (s *canaryRolloutService) withOperator(ctx context.Context, deployment *models.Deployment) (context.Context, error) {
+	if _, err := GetCurrentUser(ctx); err == nil {
+		return ctx, nil
+	}
+	user, err := UserService.Get(ctx, deployment.CreatorId)
+	if err != nil {
+		return nil, errors.Wrap(err, "get deployment creator")
+	}
+	return context.WithValue(ctx, CurrentUserKey, user), nil // nolint: staticcheck
+}
+
+func (s *canaryRolloutService) createEvent(ctx context.Context, deployment *models.Deployment, name, operationName string) error {
+	cluster, err := ClusterService.GetAssociatedCluster(ctx, deployment)
+	if err != nil {
+		return err
+	}
+	_, err = EventService.CreateByCurrentUser(ctx, CreateEventOption{
+		Name:           name,
+		OrganizationId: &cluster.OrganizationId,
+		ClusterId:      &cluster.ID,
+		ResourceType:   modelschemas.ResourceTypeDeployment,
+		ResourceId:     deployment.ID,
+		Status:         modelschemas.EventStatusSuccess,
+		OperationName:  operationName,
+	})
+	if err != nil {
+		return errors.Wrap(err, "create event")
+	}
+	return nil
+}
+
+func (s *canaryRolloutService) save(ctx context.Context, deploymentRevision *models.DeploymentRevision, rollout *models.DeploymentCanaryRollout) error {
+	info := &models.DeploymentRevisionInfo{}
+	if deploymentRevision.Info != nil {
+		*info = *deploymentRevision.Info
+	}
+	info.CanaryRollout = rollout
+	_, err := DeploymentRevisionService.Update(ctx, deploymentRevision, UpdateDeploymentRevisionOption{
+		Info: &info,
+	})
+	return err
+}
+
+// applyWeight routes the given share of traffic to the canary target through the nginx canary ingress.
+func (s *canaryRolloutService) applyWeight(ctx context.Context, deploymentRevision *models.DeploymentRevision, canaryTarget *models.DeploymentTarget, weight *uint) error {
+	ownerReferences, err := DeploymentRevisionService.MakeSureKubeOwnerReferences(ctx, deploymentRevision)
+	if err != nil {
+		return errors.Wrap(err, "make sure kube owner references")
+	}
+	return KubeIngressService.DeployDeploymentTargetAsKubeIngresses(ctx, canaryTarget, &models.DeployOption{
+		OwnerReferences: ownerReferences,
+		CanaryWeight:    weight,
+	})
+}
+
+// Start begins the rollout of the canary target of a freshly deployed revision.
+// The weight canary rules are the steps of the rollout, without any weight rule
+// the canary only receives traffic by header or cookie and waits for a manual promotion.
+func (s *canaryRolloutService) Start(ctx context.Context, deploymentRevision *models.DeploymentRevision, deploymentTargets []*models.DeploymentTarget) error {
+	canaryTarget := s.getCanaryTarget(deploymentTargets)
+	if canaryTarget == nil {
+		return nil
+	}
+	steps := make([]uint, 0)
+	if canaryTarget.CanaryRules != nil {
+		for _, rule := range *canaryTarget.CanaryRules {
+			if rule.Type == modelschemas.DeploymentTargetCanaryRuleTypeWeight && rule.Weight != nil {
+				steps = append(steps, *rule.Weight)
+			}
+		}
+	}
+	rollout := &models.DeploymentCanaryRollout{
+		Status:              models.DeploymentCanaryRolloutStatusProgressing,
+		CanaryTargetId:      canaryTarget.ID,
+		Steps:               steps,
+		StepIntervalSeconds: models.DefaultDeploymentCanaryRolloutStepIntervalSeconds,
+		StepStartedAt:       time.Now(),
+	}
+	var weight *uint
+	if len(steps) == 0 {
+		rollout.Status = models.DeploymentCanaryRolloutStatusPaused
+	} else {
+		rollout.Weight = steps[0]
+		weight = &rollout.Weight
+	}
+	err := s.applyWeight(ctx, deploymentRevision, canaryTarget, weight)
+	if err != nil {
+		return errors.Wrap(err, "apply canary weight")
+	}
+	err = s.save(ctx, deploymentRevision, rollout)
+	if err != nil {
+		return err
+	}
+	deployment, err := DeploymentService.GetAssociatedDeployment(ctx, deploymentRevision)
+	if err != nil {
+		return err
+	}
+	return s.createEvent(ctx, deployment, fmt.Sprintf("weight %d%%", rollout.Weight), "canary started")
+}
+
+func (s *canaryRolloutService) getRollout(deploymentRevision *models.DeploymentRevision) (*models.DeploymentCanaryRollout, error) {
+	if deploymentRevision.Info == nil || deploymentRevision.Info.CanaryRollout == nil {
+		return nil, errors.Errorf("deployment revision %s has no canary rollout", deploymentRevision.Uid)
+	}
+	rollout := *deploymentRevision.Info.CanaryRollout
+	return &rollout, nil
+}
+
+func (s *canaryRolloutService) getActiveRollout(deploymentRevision *models.DeploymentRevision) (*models.DeploymentCanaryRollout, error) {
+	rollout, err := s.getRollout(deploymentRevision)
+	if err != nil {
+		return nil, err
+	}
+	if rollout.Status != models.DeploymentCanaryRolloutStatusProgressing && rollout.Status != models.DeploymentCanaryRolloutStatusPaused {
+		return nil, errors.Errorf("canary rollout of deployment revision %s is already %s", deploymentRevision.Uid, rollout.Status)
+	}
+	return rollout, nil
+}
+
+// GetActive returns the active revision of the deployment together with its canary rollout.
+func (s *canaryRolloutService) GetActive(ctx context.Context, deployment *models.Deployment) (*models.DeploymentRevision, *models.DeploymentCanaryRollout, error) {
+	status_ := modelschemas.DeploymentRevisionStatusActive
+	deploymentRevisions, _, err := DeploymentRevisionService.List(ctx, ListDeploymentRevisionOption{
+		DeploymentId: utils.UintPtr(deployment.ID),
+		Status:       &status_,
+	})
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "list deployment revisions")
+	}
+	if len(deploymentRevisions) == 0 {
+		return nil, nil, errors.Errorf("deployment %s has no active revision", deployment.Name)
+	}
+	deploymentRevision := deploymentRevisions[0]
+	deploymentRevision.AssociatedDeploymentCache = deployment
+	rollout, err := s.getRollout(deploymentRevision)
+	if err != nil {
+		return nil, nil, err
+	}
+	return deploymentRevision, rollout, nil
+}
+
+// lock locks the deployment row of the revision, like the auto deploy and the auto rollback do, and
+// reloads the revision under the lock. Every change of a rollout goes through it within a transaction,
+// so the cron job and the api handlers of every replica never act on a stale rollout. The returned
+// revision is nil if it is no longer active.
+func (s *canaryRolloutService) lock(ctx context.Context, deploymentRevision *models.DeploymentRevision) (*models.DeploymentRevision, error) {
+	var deployment models.Deployment
+	err := DeploymentService.getBaseDB(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", deploymentRevision.DeploymentId).First(&deployment).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "lock deployment")
+	}
+	lockedDeploymentRevision, err := DeploymentRevisionService.Get(ctx, deploymentRevision.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "get deployment revision")
+	}
+	if lockedDeploymentRevision.Status != modelschemas.DeploymentRevisionStatusActive {
+		return nil, nil
+	}
+	lockedDeploymentRevision.AssociatedDeploymentCache = &deployment
+	return lockedDeploymentRevision, nil
+}
+
+// lockActiveRollout is lock for the api handlers, the rollout has to be still going on.
+func (s *canaryRolloutService) lockActiveRollout(ctx context.Context, deploymentRevision *models.DeploymentRevision) (*models.DeploymentRevision, *models.DeploymentCanaryRollout, error) {
+	lockedDeploymentRevision, err := s.lock(ctx, deploymentRevision)
+	if err != nil {
+		return nil, nil, err
+	}
+	if lockedDeploymentRevision == nil {
+		return nil, nil, errors.Errorf("deployment revision %s is no longer active", deploymentRevision.Uid)
+	}
+	rollout, err := s.getActiveRollout(lockedDeploymentRevision)
+	if err != nil {
+		return nil, nil, err
+	}
+	return lockedDeploymentRevision, rollout, nil
+}
+
+func (s *canaryRolloutService) Update(ctx context.Context, deploymentRevision *models.DeploymentRevision, opt UpdateCanaryRolloutOption) (rollout *models.DeploymentCanaryRollout, err error) {
+	_, ctx, df, err := startTransaction(ctx)
+	if err != nil {
+		return
+	}
+	defer func() { df(err) }()
+
+	deploymentRevision, rollout, err = s.lockActiveRollout(ctx, deploymentRevision)
+	if err != nil {
+		return
+	}
+	if opt.Steps != nil {
+		for _, weight := range *opt.Steps {
+			if weight > 100 {
+				err = errors.Errorf("canary weight %d is out of range", weight)
+				return
+			}
+		}
+		rollout.Steps = *opt.Steps
+		if rollout.CurrentStep >= len(rollout.Steps) {
+			rollout.CurrentStep = len(rollout.Steps) - 1
+		}
+		if rollout.CurrentStep < 0 {
+			rollout.CurrentStep = 0
+		}
+	}
+	if opt.StepIntervalSeconds != nil {
+		rollout.StepIntervalSeconds = *opt.StepIntervalSeconds
+	}
+	err = s.save(ctx, deploymentRevision, rollout)
+	return
+}
+
+func (s *canaryRolloutService) Pause(ctx context.Context, deploymentRevision *models.DeploymentRevision) (rollout *models.DeploymentCanaryRollout, err error) {
+	_, ctx, df, err := startTransaction(ctx)
+	if err != nil {
+		return
+	}
+	defer func() { df(err) }()
+
+	deploymentRevision, rollout, err = s.lockActiveRollout(ctx, deploymentRevision)
+	if err != nil {
+		return
+	}
+	if rollout.Status == models.DeploymentCanaryRolloutStatusPaused {
+		return
+	}
+	rollout.Status = models.DeploymentCanaryRolloutStatusPaused
+	err = s.save(ctx, deploymentRevision, rollout)
+	if err != nil {
+		return
+	}
+	deployment, err := DeploymentService.GetAssociatedDeployment(ctx, deploymentRevision)
+	if err != nil {
+		return
+	}
+	err = s.createEvent(ctx, deployment, fmt.Sprintf("weight %d%%", rollout.Weight), "canary paused")
+	return
+}
+
+func (s *canaryRolloutService) Resume(ctx context.Context, deploymentRevision *models.DeploymentRevision) (rollout *models.DeploymentCanaryRollout, err error) {
+	_, ctx, df, err := startTransaction(ctx)
+	if err != nil {
+		return
+	}
+	defer func() { df(err) }()
+
+	deploymentRevision, rollout, err = s.lockActiveRollout(ctx, deploymentRevision)
+	if err != nil {
+		return
+	}
+	if rollout.Status == models.DeploymentCanaryRolloutStatusProgressing {
+		return
+	}
+	if len(rollout.Steps) == 0 {
+		err = errors.New("canary rollout has no weight steps, it can only be promoted or aborted")
+		return
+	}
+	rollout.Status = models.DeploymentCanaryRolloutStatusProgressing
+	rollout.StepStartedAt = time.Now()
+	err = s.save(ctx, deploymentRevision, rollout)
+	if err != nil {
+		return
+	}
+	deployment, err := DeploymentService.GetAssociatedDeployment(ctx, deploymentRevision)
+	if err != nil {
+		return
+	}
+	err = s.createEvent(ctx, deployment, fmt.Sprintf("weight %d%%", rollout.Weight), "canary resumed")
+	return
+}
+
+// Progress shifts the canary to the next weight step once the step interval is over,
+// the canary is promoted after the last step. The revision may be read before the lock,
+// so the status and the step time are checked again on the locked one.
+func (s *canaryRolloutService) Progress(ctx context.Context, deploymentRevision *models.DeploymentRevision) (err error) {
+	_, ctx, df, err := startTransaction(ctx)
+	if err != nil {
+		return
+	}
+	defer func() { df(err) }()
+
+	deploymentRevision, err = s.lock(ctx, deploymentRevision)
+	if err != nil || deploymentRevision == nil {
+		return
+	}
+	rollout, err := s.getRollout(deploymentRevision)
+	if err != nil {
+		return
+	}
+	if rollout.Status != models.DeploymentCanaryRolloutStatusProgressing {
+		return
+	}
+	if time.Since(rollout.StepStartedAt) < time.Duration(rollout.StepIntervalSeconds)*time.Second {
+		return
+	}
+	deployment, err := DeploymentService.GetAssociatedDeployment(ctx, deploymentRevision)
+	if err != nil {
+		return
+	}
+	ctx, err = s.withOperator(ctx, deployment)
+	if err != nil {
+		return
+	}
+	if rollout.CurrentStep+1 >= len(rollout.Steps) {
+		_, err = s.finish(ctx, deploymentRevision, rollout, true)
+		return
+	}
+	canaryTarget, err := DeploymentTargetService.Get(ctx, rollout.CanaryTargetId)
+	if err != nil {
+		err = errors.Wrap(err, "get canary deployment target")
+		return
+	}
+	canaryTarget.AssociatedDeploymentCache = deployment
+	rollout.CurrentStep++
+	rollout.Weight = rollout.Steps[rollout.CurrentStep]
+	rollout.StepStartedAt = time.Now()
+	err = s.applyWeight(ctx, deploymentRevision, canaryTarget, &rollout.Weight)
+	if err != nil {
+		err = errors.Wrap(err, "apply canary weight")
+		return
+	}
+	err = s.save(ctx, deploymentRevision, rollout)
+	if err != nil {
+		return
+	}
+	err = s.createEvent(ctx, deployment, fmt.Sprintf("weight %d%%", rollout.Weight), "canary step")
+	return
+}
+
+// finish replaces the revision by a new one made of its stable targets, or of its canary target
+// turned stable when promoting. The canary resources are owned by the old revision and
+// get garbage collected once it is deactivated. It runs within the transaction holding the lock.
+func (s *canaryRolloutService) finish(ctx context.Context, deploymentRevision *models.DeploymentRevision, rollout *models.DeploymentCanaryRollout, promote bool) (newDeploymentRevision *models.DeploymentRevision, err error) {
+	user, err := GetCurrentUser(ctx)
+	if err != nil {
+		return
+	}
+	deployment, err := DeploymentService.GetAssociatedDeployment(ctx, deploymentRevision)
+	if err != nil {
+		return
+	}
+
+	deploymentTargets, _, err := DeploymentTargetService.List(ctx, ListDeploymentTargetOption{
+		DeploymentRevisionId: utils.UintPtr(deploymentRevision.ID),
+	})
+	if err != nil {
+		err = errors.Wrap(err, "list deployment targets")
+		return
+	}
+
+	newDeploymentRevision, err = DeploymentRevisionService.Create(ctx, CreateDeploymentRevisionOption{
+		CreatorId:    user.ID,
+		DeploymentId: deployment.ID,
+		Status:       modelschemas.DeploymentRevisionStatusActive,
+	})
+	if err != nil {
+		err = errors.Wrap(err, "create deployment revision")
+		return
+	}
+
+	newDeploymentTargets := make([]*models.DeploymentTarget, 0, len(deploymentTargets))
+	for _, deploymentTarget := range deploymentTargets {
+		var newDeploymentTarget *models.DeploymentTarget
+		if promote {
+			if deploymentTarget.ID != rollout.CanaryTargetId {
+				continue
+			}
+			newDeploymentTarget, err = DeploymentTargetService.Create(ctx, CreateDeploymentTargetOption{
+				CreatorId:            user.ID,
+				DeploymentId:         deployment.ID,
+				DeploymentRevisionId: newDeploymentRevision.ID,
+				BentoId:              deploymentTarget.BentoId,
+				Type:                 modelschemas.DeploymentTargetTypeStable,
+				Config:               cloneDeploymentTargetConfig(deploymentTarget.Config),
+			})
+		} else {
+			if deploymentTarget.Type == modelschemas.DeploymentTargetTypeCanary {
+				continue
+			}
+			newDeploymentTarget, err = DeploymentTargetService.Clone(ctx, deploymentTarget, user.ID, newDeploymentRevision.ID, deploymentTarget.BentoId)
+		}
+		if err != nil {
+			err = errors.Wrap(err, "create deployment target")
+			return
+		}
+		newDeploymentTargets = append(newDeploymentTargets, newDeploymentTarget)
+	}
+	if len(newDeploymentTargets) == 0 {
+		err = errors.Errorf("deployment revision %s has no deployment target to keep", deploymentRevision.Uid)
+		return
+	}
+
+	rollout.Status = models.DeploymentCanaryRolloutStatusAborted
+	operationName := "canary aborted"
+	if promote {
+		rollout.Status = models.DeploymentCanaryRolloutStatusPromoted
+		operationName = "canary promoted"
+	}
+	err = s.save(ctx, deploymentRevision, rollout)
+	if err != nil {
+		return
+	}
+
+	err = DeploymentRevisionService.Deploy(ctx, newDeploymentRevision, newDeploymentTargets, false)
+	if err != nil {
+		err = errors.Wrap(err, "deploy deployment revision")
+		return
+	}
+
+	err = s.createEvent(ctx, deployment, fmt.Sprintf("weight %d%%", rollout.Weight), operationName)
+	return
+}
+
+func (s *canaryRolloutService) lockAndFinish(ctx context.Context, deploymentRevision *models.DeploymentRevision, promote bool) (newDeploymentRevision *models.DeploymentRevision, err error) {
+	_, ctx, df, err := startTransaction(ctx)
+	if err != nil {
+		return
+	}
+	defer func() { df(err) }()
+
+	deploymentRevision, rollout, err := s.lockActiveRollout(ctx, deploymentRevision)
+	if err != nil {
+		return
+	}
+	newDeploymentRevision, err = s.finish(ctx, deploymentRevision, rollout, promote)
+	return
+}
+
+func (s *canaryRolloutService) Promote(ctx context.Context, deploymentRevision *models.DeploymentRevision) (*models.DeploymentRevision, error) {
+	return s.lockAndFinish(ctx, deploymentRevision, true)
+}
+
+func (s *canaryRolloutService) Abort(ctx context.Context, deploymentRevision *models.DeploymentRevision) (*models.DeploymentRevision, error) {
+	return s.lockAndFinish(ctx, deploymentRevision, false)
+}
+
+func (s *canaryRolloutService) ProgressAll(ctx context.Context) error {
+	deploymentRevisions, _, err := DeploymentRevisionService.List(ctx, ListDeploymentRevisionOption{
+		Status:              modelschemas.DeploymentRevisionStatusActive.Ptr(),
+		CanaryRolloutStatus: models.DeploymentCanaryRolloutStatusProgressing.Ptr(),
+	})
+	if err != nil {
+		return errors.Wrap(err, "list progressing deployment revisions")
+	}
+	for _, deploymentRevision := range deploymentRevisions {
+		err = s.Progress(ctx, deploymentRevision)
+		if err != nil {
+			logrus.Errorf("progress canary rollout of deployment revision %s: %s", deploymentRevision.Uid, err.Error())
+		}
+	}
+	return nil
+}
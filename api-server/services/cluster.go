@@ -206,6 +206,11 @@ func (s *clusterService) List(ctx context.Context, opt ListClusterOption) ([]*mo
 			for _, member := range clusterMembers {
 				clusterIds = append(clusterIds, member.ClusterId)
 			}
+			groupClusterIds, err := UserGroupGrantService.ListUserResourceIds(ctx, *userID, modelschemas.ResourceTypeCluster)
+			if err != nil {
+				return nil, 0, errors.Wrap(err, "list user group cluster ids")
+			}
+			clusterIds = append(clusterIds, groupClusterIds...)
 			clusterIds = append(clusterIds, 0) // Add a fill value of 0 because it cannot be empty
 			query = query.Where("(id in (?) OR creator_id = ?)", clusterIds, userID)
 		}
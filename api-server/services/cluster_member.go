@@ -130,13 +130,15 @@ func (s *clusterMemberService) GetOrganization(ctx context.Context, resourceId u
 }
 
 func (s *clusterMemberService) CheckRoles(ctx context.Context, userId, resourceId uint, roles []modelschemas.MemberRole) (bool, error) {
-	q := s.getBaseDB(ctx).
-		Where("user_id = ?", userId).
-		Where("cluster_id = ?", resourceId).
-		Where("role in (?)", roles)
-	var total int64
-	err := q.Count(&total).Error
-	return total > 0, err
+	var directRole modelschemas.MemberRole
+	member, err := s.GetBy(ctx, userId, resourceId)
+	if err != nil && !utils.IsNotFound(err) {
+		return false, err
+	}
+	if err == nil {
+		directRole = member.Role
+	}
+	return MemberService.checkEffectiveRole(ctx, userId, s.GetResourceType(), resourceId, directRole, roles)
 }
 
 func (s *clusterMemberService) Delete(ctx context.Context, m *models.ClusterMember, operatorId uint) (*models.ClusterMember, error) {
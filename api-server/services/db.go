@@ -13,8 +13,11 @@ import (
 
 	"github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/sirupsen/logrus"
 	"github.com/uber/jaeger-client-go"
 	postgres "go.elastic.co/apm/module/apmgormv2/driver/postgres"
@@ -137,6 +140,12 @@ func getDB() (*gorm.DB, error) {
 		dbCacheRW.Lock()
 		dbCache[uri] = db
 		dbCacheRW.Unlock()
+		err = prometheus.Register(collectors.NewDBStatsCollector(rawDb, config.YataiConfig.Postgresql.Database))
+		if err != nil {
+			if _, ok := err.(prometheus.AlreadyRegisteredError); !ok {
+				return nil, errors.Wrap(err, "register db stats collector")
+			}
+		}
 	}
 
 	if command.GlobalCommandOption.Debug {
@@ -226,6 +235,21 @@ func (*MigrateLog) Verbose() bool {
 	return false
 }
 
+func getMigrationSourceURL() (string, error) {
+	migrationDir := config.YataiConfig.Server.MigrationDir
+
+	exists, err := utils.PathExists(migrationDir)
+	if err != nil {
+		return "", errors.Wrapf(err, "check migration dir exists: %s", migrationDir)
+	}
+	if !exists {
+		return "", errors.Errorf("migration dir is not exists: %s", migrationDir)
+	}
+
+	logrus.Debugf("migration dir: %s", migrationDir)
+	return fmt.Sprintf("file://%s", migrationDir), nil
+}
+
 func MigrateUp() error {
 	uri, err := getDBURI()
 	if err != nil {
@@ -233,19 +257,13 @@ func MigrateUp() error {
 	}
 
 	logrus.Debugf("db uri: %s", uri)
-	migrationDir := config.YataiConfig.Server.MigrationDir
-
-	exists, err := utils.PathExists(migrationDir)
+	sourceURL, err := getMigrationSourceURL()
 	if err != nil {
-		return errors.Wrapf(err, "check migration dir exists: %s", migrationDir)
-	}
-	if !exists {
-		return errors.Errorf("migration dir is not exists: %s", migrationDir)
+		return err
 	}
 
-	logrus.Debugf("migration dir: %s", migrationDir)
 	m, err := migrate.New(
-		fmt.Sprintf("file://%s", migrationDir),
+		sourceURL,
 		uri,
 	)
 	if err != nil {
@@ -261,3 +279,44 @@ func MigrateUp() error {
 	logrus.Info("[DONE] migrate up")
 	return nil
 }
+
+type MigrationStatus struct {
+	Version       uint `json:"version"`
+	LatestVersion uint `json:"latest_version"`
+	Dirty         bool `json:"dirty"`
+}
+
+// GetMigrationStatus compares the schema version recorded by golang-migrate with the latest migration on disk.
+func GetMigrationStatus(ctx context.Context) (*MigrationStatus, error) {
+	sourceURL, err := getMigrationSourceURL()
+	if err != nil {
+		return nil, err
+	}
+	sourceDriver, err := source.Open(sourceURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "open migration source")
+	}
+	defer sourceDriver.Close()
+
+	status := &MigrationStatus{}
+	version, err := sourceDriver.First()
+	for err == nil {
+		status.LatestVersion = version
+		version, err = sourceDriver.Next(version)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, errors.Wrap(err, "read migration source")
+	}
+
+	var row struct {
+		Version int64
+		Dirty   bool
+	}
+	err = mustGetSession(ctx).Raw("SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&row).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "get schema migration version")
+	}
+	status.Version = uint(row.Version)
+	status.Dirty = row.Dirty
+	return status, nil
+}
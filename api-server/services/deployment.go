@@ -55,6 +55,11 @@ type UpdateDeploymentOption struct {
 	Description *string
 	Labels      *modelschemas.LabelItemsSchema
 	Status      *modelschemas.DeploymentStatus
+
+	FollowPolicy     **models.DeploymentFollowPolicy
+	FollowDeployedAt **time.Time
+
+	RollbackPolicy **models.DeploymentRollbackPolicy
 }
 
 type UpdateDeploymentStatusOption struct {
@@ -62,6 +67,7 @@ type UpdateDeploymentStatusOption struct {
 	SyncingAt **time.Time
 	UpdatedAt **time.Time
 	Labels    *modelschemas.LabelItemsSchema
+	Reason    *models.DeploymentStatusReason
 }
 
 type ListDeploymentOption struct {
@@ -78,6 +84,7 @@ type ListDeploymentOption struct {
 	Ids             *[]uint
 	BentoIds        *[]uint
 	Statuses        *[]modelschemas.DeploymentStatus
+	HasFollowPolicy *bool
 	Order           *string
 }
 
@@ -146,6 +153,33 @@ func (s *deploymentService) Update(ctx context.Context, b *models.Deployment, op
 		}()
 	}
 
+	if opt.FollowPolicy != nil {
+		updaters["follow_policy"] = *opt.FollowPolicy
+		defer func() {
+			if err == nil {
+				b.FollowPolicy = *opt.FollowPolicy
+			}
+		}()
+	}
+
+	if opt.FollowDeployedAt != nil {
+		updaters["follow_deployed_at"] = *opt.FollowDeployedAt
+		defer func() {
+			if err == nil {
+				b.FollowDeployedAt = *opt.FollowDeployedAt
+			}
+		}()
+	}
+
+	if opt.RollbackPolicy != nil {
+		updaters["rollback_policy"] = *opt.RollbackPolicy
+		defer func() {
+			if err == nil {
+				b.RollbackPolicy = *opt.RollbackPolicy
+			}
+		}()
+	}
+
 	if len(updaters) == 0 {
 		return b, nil
 	}
@@ -259,6 +293,13 @@ func (s *deploymentService) List(ctx context.Context, opt ListDeploymentOption)
 	if opt.Statuses != nil {
 		query = query.Where("deployment.status IN (?)", *opt.Statuses)
 	}
+	if opt.HasFollowPolicy != nil {
+		if *opt.HasFollowPolicy {
+			query = query.Where("deployment.follow_policy IS NOT NULL")
+		} else {
+			query = query.Where("deployment.follow_policy IS NULL")
+		}
+	}
 	query = opt.BindQueryWithKeywords(query, "deployment")
 	query = opt.BindQueryWithLabels(query, modelschemas.ResourceTypeDeployment)
 	query = query.Select("deployment_revision.*, deployment.*")
@@ -281,19 +322,28 @@ func (s *deploymentService) List(ctx context.Context, opt ListDeploymentOption)
 	return deployments, uint(total), err
 }
 
+// DeploymentStatusReconcileInterval is how long a deployment status may stay unsynced,
+// the status is mostly kept in sync by DeploymentStatusWatcherService.
+const DeploymentStatusReconcileInterval = 10 * time.Minute
+
 func (s *deploymentService) ListUnsynced(ctx context.Context) ([]*models.Deployment, error) {
 	q := getBaseQuery(ctx, s)
 	now := time.Now()
-	t := now.Add(-time.Minute)
+	t := now.Add(-DeploymentStatusReconcileInterval)
 	q = q.Where("status_syncing_at is null or status_syncing_at < ? or status_updated_at is null or status_updated_at < ?", t, t)
 	envs := make([]*models.Deployment, 0)
 	err := q.Order("id DESC").Find(&envs).Error
 	return envs, err
 }
 
+// UpdateStatus records every status transition in the status history of the deployment.
 func (s *deploymentService) UpdateStatus(ctx context.Context, deployment *models.Deployment, opt UpdateDeploymentStatusOption) (*models.Deployment, error) {
 	updater := map[string]interface{}{}
+	var fromStatus *modelschemas.DeploymentStatus
 	if opt.Status != nil {
+		if *opt.Status != deployment.Status {
+			fromStatus = deployment.Status.Ptr()
+		}
 		deployment.Status = *opt.Status
 		updater["status"] = *opt.Status
 	}
@@ -306,7 +356,21 @@ func (s *deploymentService) UpdateStatus(ctx context.Context, deployment *models
 		updater["status_updated_at"] = *opt.UpdatedAt
 	}
 	err := s.getBaseDB(ctx).Where("id = ?", deployment.ID).Updates(updater).Error
-	return deployment, err
+	if err != nil {
+		return deployment, err
+	}
+	if fromStatus != nil {
+		_, err = DeploymentStatusHistoryService.Create(ctx, CreateDeploymentStatusHistoryOption{
+			DeploymentId: deployment.ID,
+			FromStatus:   fromStatus,
+			Status:       deployment.Status,
+			Reason:       opt.Reason,
+		})
+		if err != nil {
+			return deployment, errors.Wrap(err, "create deployment status history")
+		}
+	}
+	return deployment, nil
 }
 
 type IDeploymentAssociate interface {
@@ -472,7 +536,8 @@ func (s *deploymentService) SyncStatus(ctx context.Context, d *models.Deployment
 	if err != nil {
 		return d.Status, err
 	}
-	currentStatus, err := s.getStatusFromK8s(ctx, d)
+	reason := &models.DeploymentStatusReason{}
+	currentStatus, err := s.getStatusFromK8s(ctx, d, reason)
 	if err != nil {
 		return d.Status, err
 	}
@@ -481,6 +546,7 @@ func (s *deploymentService) SyncStatus(ctx context.Context, d *models.Deployment
 	_, err = s.UpdateStatus(ctx, d, UpdateDeploymentStatusOption{
 		Status:    &currentStatus,
 		UpdatedAt: &nowPtr,
+		Reason:    reason,
 	})
 	if err != nil {
 		return currentStatus, err
@@ -488,7 +554,8 @@ func (s *deploymentService) SyncStatus(ctx context.Context, d *models.Deployment
 	return currentStatus, nil
 }
 
-func (s *deploymentService) getStatusFromK8s(ctx context.Context, d *models.Deployment) (modelschemas.DeploymentStatus, error) {
+// getStatusFromK8s fills reason with the pods the status is derived from.
+func (s *deploymentService) getStatusFromK8s(ctx context.Context, d *models.Deployment, reason *models.DeploymentStatusReason) (modelschemas.DeploymentStatus, error) {
 	defaultStatus := modelschemas.DeploymentStatusUnknown
 
 	cluster, err := ClusterService.GetAssociatedCluster(ctx, d)
@@ -556,6 +623,8 @@ func (s *deploymentService) getStatusFromK8s(ctx context.Context, d *models.Depl
 	}
 
 	if len(imageBuilderPods) != 0 {
+		*reason = *DeploymentStatusHistoryService.MakeReason(imageBuilderPods)
+		reason.Message = "image builder pods"
 		for _, imageBuilderPod := range imageBuilderPods {
 			if imageBuilderPod.Status.Status == modelschemas.KubePodActualStatusPending || imageBuilderPod.Status.Status == modelschemas.KubePodActualStatusRunning {
 				return modelschemas.DeploymentStatusImageBuilding, nil
@@ -576,6 +645,7 @@ func (s *deploymentService) getStatusFromK8s(ctx context.Context, d *models.Depl
 	if err != nil {
 		return defaultStatus, err
 	}
+	*reason = *DeploymentStatusHistoryService.MakeReason(pods)
 
 	if len(pods) == 0 {
 		if d.Status == modelschemas.DeploymentStatusTerminating || d.Status == modelschemas.DeploymentStatusTerminated {
@@ -0,0 +1,161 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm/clause"
+
+	"github.com/bentoml/yatai-schemas/modelschemas"
+	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/common/utils"
+)
+
+type deploymentAutoRollbackService struct{}
+
+var DeploymentAutoRollbackService = deploymentAutoRollbackService{}
+
+// a new revision is only judged after this delay, right after the deploy the status still comes from the pods of the previous revision
+const deploymentAutoRollbackSettleDelay = time.Minute
+
+var deploymentAutoRollbackStatuses = map[modelschemas.DeploymentStatus]struct{}{
+	modelschemas.DeploymentStatusFailed:           {},
+	modelschemas.DeploymentStatusUnhealthy:        {},
+	modelschemas.DeploymentStatusImageBuildFailed: {},
+}
+
+// getRollbackSource returns the latest revision before the given one that has not failed.
+func (s *deploymentAutoRollbackService) getRollbackSource(ctx context.Context, deploymentRevision *models.DeploymentRevision) (*models.DeploymentRevision, error) {
+	current := deploymentRevision
+	for {
+		previous, err := DeploymentRevisionService.GetPrevious(ctx, current)
+		if err != nil {
+			return nil, err
+		}
+		if !previous.Info.IsFailed() {
+			return previous, nil
+		}
+		current = previous
+	}
+}
+
+// Check rolls the deployment back to its previous healthy revision when its active
+// revision turns into a failed status within the grace period of the rollback policy.
+// It runs from the status watcher and the reconciliation of the leader replica only, the
+// deployment row is locked so the concurrent syncs of the same deployment are serialized.
+// requeueAfter is set when the active revision is too new to be judged yet.
+func (s *deploymentAutoRollbackService) Check(ctx context.Context, deploymentId uint) (requeueAfter time.Duration, err error) {
+	if !LeaderElectionService.IsLeader() {
+		return
+	}
+
+	_, ctx, df, err := startTransaction(ctx)
+	if err != nil {
+		return
+	}
+	defer func() { df(err) }()
+
+	var deployment models.Deployment
+	err = DeploymentService.getBaseDB(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", deploymentId).First(&deployment).Error
+	if err != nil {
+		err = errors.Wrap(err, "lock deployment")
+		return
+	}
+	policy := deployment.RollbackPolicy
+	if policy == nil || !policy.Enabled {
+		return
+	}
+	status := deployment.Status
+	if _, ok := deploymentAutoRollbackStatuses[status]; !ok {
+		return
+	}
+
+	status_ := modelschemas.DeploymentRevisionStatusActive
+	deploymentRevisions, _, err := DeploymentRevisionService.List(ctx, ListDeploymentRevisionOption{
+		DeploymentId: utils.UintPtr(deployment.ID),
+		Status:       &status_,
+	})
+	if err != nil {
+		err = errors.Wrap(err, "list deployment revisions")
+		return
+	}
+	if len(deploymentRevisions) == 0 {
+		return
+	}
+	deploymentRevision := deploymentRevisions[0]
+	if deploymentRevision.Info.IsFailed() {
+		return
+	}
+	age := time.Since(deploymentRevision.CreatedAt)
+	if age > policy.GetGracePeriod() {
+		return
+	}
+	if age < deploymentAutoRollbackSettleDelay {
+		requeueAfter = deploymentAutoRollbackSettleDelay - age
+		return
+	}
+
+	creator, err := UserService.Get(ctx, deployment.CreatorId)
+	if err != nil {
+		err = errors.Wrap(err, "get deployment creator")
+		return
+	}
+	// the rollback runs in background, it acts on behalf of the deployment creator
+	ctx = context.WithValue(ctx, CurrentUserKey, creator) // nolint: staticcheck
+
+	info := &models.DeploymentRevisionInfo{}
+	if deploymentRevision.Info != nil {
+		*info = *deploymentRevision.Info
+	}
+	now := time.Now()
+	info.FailedStatus = status
+	info.FailedAt = &now
+	_, err = DeploymentRevisionService.Update(ctx, deploymentRevision, UpdateDeploymentRevisionOption{
+		Info: &info,
+	})
+	if err != nil {
+		err = errors.Wrap(err, "mark deployment revision failed")
+		return
+	}
+	cluster, err := ClusterService.GetAssociatedCluster(ctx, &deployment)
+	if err != nil {
+		return
+	}
+	_, err = EventService.CreateByCurrentUser(ctx, CreateEventOption{
+		Name:           deploymentRevision.Uid,
+		OrganizationId: &cluster.OrganizationId,
+		ClusterId:      &cluster.ID,
+		ResourceType:   modelschemas.ResourceTypeDeployment,
+		ResourceId:     deployment.ID,
+		Status:         modelschemas.EventStatusFailed,
+		OperationName:  "revision failed",
+	})
+	if err != nil {
+		err = errors.Wrap(err, "create event")
+		return
+	}
+
+	if info.AutoRollbackAttempts >= policy.GetMaxAttempts() {
+		logrus.Warnf("deployment %s reached the max auto rollback attempts", deployment.Name)
+		return
+	}
+	sourceDeploymentRevision, err := s.getRollbackSource(ctx, deploymentRevision)
+	if err != nil {
+		if utils.IsNotFound(err) {
+			err = nil
+			return
+		}
+		err = errors.Wrap(err, "get rollback source revision")
+		return
+	}
+	_, err = DeploymentRevisionService.rollback(ctx, sourceDeploymentRevision, &models.DeploymentRevisionInfo{
+		RollbackFromRevisionUid: sourceDeploymentRevision.Uid,
+		AutoRollbackAttempts:    info.AutoRollbackAttempts + 1,
+	}, "auto rolled back")
+	if err != nil {
+		err = errors.Wrap(err, "rollback deployment")
+	}
+	return
+}
@@ -0,0 +1,398 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm/clause"
+	"k8s.io/client-go/util/workqueue"
+
+	"github.com/bentoml/yatai-schemas/modelschemas"
+	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/common/consts"
+	"github.com/bentoml/yatai/common/utils"
+)
+
+const deploymentFollowWorkers = 4
+
+type deploymentFollowService struct {
+	mu    sync.Mutex
+	queue workqueue.DelayingInterface
+}
+
+// DeploymentFollowService redeploys the deployments with a follow policy. The triggers of the
+// api handlers are queued and synced in background, the cron job of the leader catches up the rest.
+var DeploymentFollowService = deploymentFollowService{}
+
+// ResolveBento returns the bento currently followed by the policy in the given bento repository.
+func (s *deploymentFollowService) ResolveBento(ctx context.Context, policy *models.DeploymentFollowPolicy, bentoRepositoryId uint) (*models.Bento, error) {
+	bento, _, err := s.resolveBento(ctx, policy, bentoRepositoryId)
+	return bento, err
+}
+
+// resolveBento also returns since when the bento is the followed one, the alias moved to it or it finished uploading.
+func (s *deploymentFollowService) resolveBento(ctx context.Context, policy *models.DeploymentFollowPolicy, bentoRepositoryId uint) (*models.Bento, time.Time, error) {
+	if policy.Alias != "" {
+		bentoAlias, err := BentoAliasService.GetByName(ctx, bentoRepositoryId, policy.Alias)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		bento, err := BentoService.GetAssociatedBento(ctx, bentoAlias)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		return bento, bentoAlias.UpdatedAt, nil
+	}
+	uploadStatus := modelschemas.BentoUploadStatusSuccess
+	bentos, _, err := BentoService.List(ctx, ListBentoOption{
+		BaseListOption: BaseListOption{
+			Count: utils.UintPtr(1),
+		},
+		BentoRepositoryId: &bentoRepositoryId,
+		UploadStatus:      &uploadStatus,
+		Order:             utils.StringPtr("bento.created_at DESC"),
+	})
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	if len(bentos) == 0 {
+		return nil, time.Time{}, consts.ErrNotFound
+	}
+	bento := bentos[0]
+	if bento.UploadFinishedAt != nil {
+		return bento, *bento.UploadFinishedAt, nil
+	}
+	return bento, bento.CreatedAt, nil
+}
+
+// Sync redeploys the deployment if any of its active targets falls behind the
+// followed bento. A followed bento is only deployed once it stayed the followed
+// one for the whole debounce window, so a burst of uploads is deployed once,
+// requeueAfter tells when the window closes. Nothing is deployed while the image
+// of a followed bento is not built yet, the image build triggers the sync again.
+func (s *deploymentFollowService) Sync(ctx context.Context, deploymentId uint) (requeueAfter time.Duration, err error) {
+	deployment, err := DeploymentService.Get(ctx, deploymentId)
+	if err != nil {
+		return
+	}
+	if deployment.FollowPolicy == nil {
+		return
+	}
+	creator, err := UserService.Get(ctx, deployment.CreatorId)
+	if err != nil {
+		err = errors.Wrap(err, "get deployment creator")
+		return
+	}
+	// auto deploy always acts on behalf of the deployment creator
+	ctx = context.WithValue(ctx, CurrentUserKey, creator) // nolint: staticcheck
+
+	bentos, requeueAfter, deployErr := s.sync(ctx, deploymentId)
+	if len(bentos) == 0 {
+		err = deployErr
+		return
+	}
+
+	if deployErr != nil {
+		// the failed attempt is rolled back with the transaction, it is recorded afterwards
+		now := time.Now()
+		nowPtr := &now
+		_, err = DeploymentService.Update(ctx, deployment, UpdateDeploymentOption{
+			FollowDeployedAt: &nowPtr,
+		})
+		if err != nil {
+			err = errors.Wrap(err, "update deployment follow deployed at")
+			return
+		}
+		if err = s.createEvent(ctx, deployment, bentos, modelschemas.EventStatusFailed); err != nil {
+			return
+		}
+		err = deployErr
+		return
+	}
+	logrus.Infof("auto deployed deployment %s", deployment.Name)
+	return
+}
+
+// sync runs with the deployment row locked, so the triggers and the cron job of
+// every replica cannot deploy the same change twice. It returns the bentos of the
+// new revision when a deploy was attempted.
+func (s *deploymentFollowService) sync(ctx context.Context, deploymentId uint) (bentos []*models.Bento, requeueAfter time.Duration, err error) {
+	_, ctx, df, err := startTransaction(ctx)
+	if err != nil {
+		return
+	}
+	defer func() { df(err) }()
+
+	var deployment models.Deployment
+	err = DeploymentService.getBaseDB(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", deploymentId).First(&deployment).Error
+	if err != nil {
+		err = errors.Wrap(err, "lock deployment")
+		return
+	}
+	policy := deployment.FollowPolicy
+	if policy == nil {
+		return
+	}
+
+	status_ := modelschemas.DeploymentRevisionStatusActive
+	deploymentRevisions, _, err := DeploymentRevisionService.List(ctx, ListDeploymentRevisionOption{
+		DeploymentId: utils.UintPtr(deployment.ID),
+		Status:       &status_,
+	})
+	if err != nil {
+		err = errors.Wrap(err, "list deployment revisions")
+		return
+	}
+	if len(deploymentRevisions) == 0 {
+		return
+	}
+	deploymentTargets, _, err := DeploymentTargetService.List(ctx, ListDeploymentTargetOption{
+		DeploymentRevisionId: utils.UintPtr(deploymentRevisions[0].ID),
+	})
+	if err != nil {
+		err = errors.Wrap(err, "list deployment targets")
+		return
+	}
+
+	changed := false
+	bentos_ := make([]*models.Bento, 0, len(deploymentTargets))
+	for _, deploymentTarget := range deploymentTargets {
+		var bento *models.Bento
+		bento, err = BentoService.GetAssociatedBento(ctx, deploymentTarget)
+		if err != nil {
+			err = errors.Wrap(err, "get deployment target bento")
+			return
+		}
+		followedBento, followedSince, resolveErr := s.resolveBento(ctx, policy, bento.BentoRepositoryId)
+		if resolveErr != nil {
+			if utils.IsNotFound(resolveErr) {
+				bentos_ = append(bentos_, bento)
+				continue
+			}
+			err = errors.Wrap(resolveErr, "resolve followed bento")
+			return
+		}
+		if followedBento.ID == bento.ID {
+			bentos_ = append(bentos_, bento)
+			continue
+		}
+		if wait := policy.GetDebounce() - time.Since(followedSince); wait > 0 {
+			if wait > requeueAfter {
+				requeueAfter = wait
+			}
+			continue
+		}
+		if followedBento.ImageBuildStatus != modelschemas.ImageBuildStatusSuccess {
+			return
+		}
+		changed = true
+		bentos_ = append(bentos_, followedBento)
+	}
+	if !changed || requeueAfter > 0 {
+		return
+	}
+
+	bentos = bentos_
+	err = s.deploy(ctx, &deployment, deploymentTargets, bentos)
+	if err != nil {
+		return
+	}
+	now := time.Now()
+	nowPtr := &now
+	_, err = DeploymentService.Update(ctx, &deployment, UpdateDeploymentOption{
+		FollowDeployedAt: &nowPtr,
+	})
+	if err != nil {
+		err = errors.Wrap(err, "update deployment follow deployed at")
+		return
+	}
+	err = s.createEvent(ctx, &deployment, bentos, modelschemas.EventStatusSuccess)
+	return
+}
+
+func (s *deploymentFollowService) createEvent(ctx context.Context, deployment *models.Deployment, bentos []*models.Bento, status modelschemas.EventStatus) error {
+	cluster, err := ClusterService.GetAssociatedCluster(ctx, deployment)
+	if err != nil {
+		return err
+	}
+	bentoTags := make([]string, 0, len(bentos))
+	for _, bento := range bentos {
+		bentoRepository, err := BentoRepositoryService.GetAssociatedBentoRepository(ctx, bento)
+		if err != nil {
+			return err
+		}
+		bentoTags = append(bentoTags, fmt.Sprintf("%s:%s", bentoRepository.Name, bento.Version))
+	}
+	_, err = EventService.CreateByCurrentUser(ctx, CreateEventOption{
+		Name:           strings.Join(bentoTags, ","),
+		OrganizationId: &cluster.OrganizationId,
+		ClusterId:      &cluster.ID,
+		ResourceType:   modelschemas.ResourceTypeDeployment,
+		ResourceId:     deployment.ID,
+		Status:         status,
+		OperationName:  "auto deployed",
+	})
+	return errors.Wrap(err, "create event")
+}
+
+func (s *deploymentFollowService) deploy(ctx context.Context, deployment *models.Deployment, oldDeploymentTargets []*models.DeploymentTarget, bentos []*models.Bento) (err error) {
+	user, err := GetCurrentUser(ctx)
+	if err != nil {
+		return
+	}
+
+	// nolint: ineffassign,staticcheck
+	_, ctx, df, err := startTransaction(ctx)
+	if err != nil {
+		return
+	}
+	defer func() { df(err) }()
+
+	deploymentRevision, err := DeploymentRevisionService.Create(ctx, CreateDeploymentRevisionOption{
+		CreatorId:    user.ID,
+		DeploymentId: deployment.ID,
+		Status:       modelschemas.DeploymentRevisionStatusActive,
+	})
+	if err != nil {
+		err = errors.Wrap(err, "create deployment revision")
+		return
+	}
+
+	deploymentTargets := make([]*models.DeploymentTarget, 0, len(oldDeploymentTargets))
+	for idx, oldDeploymentTarget := range oldDeploymentTargets {
+		var deploymentTarget *models.DeploymentTarget
+		deploymentTarget, err = DeploymentTargetService.Clone(ctx, oldDeploymentTarget, user.ID, deploymentRevision.ID, bentos[idx].ID)
+		if err != nil {
+			err = errors.Wrap(err, "create deployment target")
+			return
+		}
+		deploymentTargets = append(deploymentTargets, deploymentTarget)
+	}
+
+	err = DeploymentRevisionService.Deploy(ctx, deploymentRevision, deploymentTargets, false)
+	if err != nil {
+		err = errors.Wrap(err, "deploy deployment revision")
+	}
+	return
+}
+
+// Run blocks until ctx is done. It runs on every replica, since the triggers come from the api handlers of any of them.
+func (s *deploymentFollowService) Run(ctx context.Context) {
+	queue := workqueue.NewNamedDelayingQueue("deployment-follow")
+	s.mu.Lock()
+	s.queue = queue
+	s.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for i := 0; i < deploymentFollowWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.work(ctx, queue)
+		}()
+	}
+	<-ctx.Done()
+	queue.ShutDown()
+	wg.Wait()
+}
+
+func (s *deploymentFollowService) getQueue() workqueue.DelayingInterface {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.queue
+}
+
+func (s *deploymentFollowService) work(ctx context.Context, queue workqueue.DelayingInterface) {
+	for {
+		item, shutdown := queue.Get()
+		if shutdown {
+			return
+		}
+		key := item.(string)
+		pieces := strings.SplitN(key, "/", 2)
+		id, err := strconv.ParseUint(pieces[len(pieces)-1], 10, 64)
+		if len(pieces) != 2 || err != nil {
+			logrus.Errorf("invalid deployment follow key %s", key)
+			queue.Done(item)
+			continue
+		}
+		var requeueAfter time.Duration
+		switch pieces[0] {
+		case "organization":
+			err = s.enqueueDeployments(ctx, queue, ListDeploymentOption{
+				OrganizationId: utils.UintPtr(uint(id)),
+			})
+		case "deployment":
+			requeueAfter, err = s.Sync(ctx, uint(id))
+		}
+		if err != nil {
+			logrus.Errorf("auto deploy %s: %s", key, err.Error())
+		}
+		queue.Done(item)
+		if requeueAfter > 0 {
+			queue.AddAfter(item, requeueAfter)
+		}
+	}
+}
+
+func (s *deploymentFollowService) enqueueDeployments(ctx context.Context, queue workqueue.DelayingInterface, opt ListDeploymentOption) error {
+	opt.HasFollowPolicy = utils.BoolPtr(true)
+	deployments, _, err := DeploymentService.List(ctx, opt)
+	if err != nil {
+		return errors.Wrap(err, "list following deployments")
+	}
+	for _, deployment := range deployments {
+		queue.Add(fmt.Sprintf("deployment/%d", deployment.ID))
+	}
+	return nil
+}
+
+// TriggerByBento is called when a bento may have become the followed one:
+// it finished uploading, its image was built or an alias moved to it.
+// The following deployments of the organization are synced in background.
+func (s *deploymentFollowService) TriggerByBento(ctx context.Context, bento *models.Bento) error {
+	if bento.UploadStatus != modelschemas.BentoUploadStatusSuccess || bento.ImageBuildStatus != modelschemas.ImageBuildStatusSuccess {
+		return nil
+	}
+	queue := s.getQueue()
+	if queue == nil {
+		return nil
+	}
+	bentoRepository, err := BentoRepositoryService.GetAssociatedBentoRepository(ctx, bento)
+	if err != nil {
+		return err
+	}
+	queue.Add(fmt.Sprintf("organization/%d", bentoRepository.OrganizationId))
+	return nil
+}
+
+// TriggerByDeployment syncs the deployment in background, after its follow policy changed.
+func (s *deploymentFollowService) TriggerByDeployment(deployment *models.Deployment) {
+	queue := s.getQueue()
+	if queue == nil {
+		return
+	}
+	queue.Add(fmt.Sprintf("deployment/%d", deployment.ID))
+}
+
+// SyncAll catches up the following deployments whose triggers were missed, e.g. by a restart.
+func (s *deploymentFollowService) SyncAll(ctx context.Context) error {
+	deployments, _, err := DeploymentService.List(ctx, ListDeploymentOption{
+		HasFollowPolicy: utils.BoolPtr(true),
+	})
+	if err != nil {
+		return errors.Wrap(err, "list following deployments")
+	}
+	for _, deployment := range deployments {
+		if _, err = s.Sync(ctx, deployment.ID); err != nil {
+			logrus.Errorf("auto deploy deployment %s: %s", deployment.Name, err.Error())
+		}
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"fmt"
+	"sort"
 
 	"github.com/pkg/errors"
 	"github.com/rs/xid"
@@ -19,6 +20,8 @@ import (
 	"github.com/bentoml/yatai/api-server/models"
 	"github.com/bentoml/yatai/common/consts"
 	"github.com/bentoml/yatai/common/utils"
+
+	servingv1alpha2 "github.com/bentoml/yatai-deployment/apis/serving/v1alpha2"
 )
 
 type deploymentRevisionService struct{}
@@ -33,10 +36,12 @@ type CreateDeploymentRevisionOption struct {
 	CreatorId    uint
 	DeploymentId uint
 	Status       modelschemas.DeploymentRevisionStatus
+	Info         *models.DeploymentRevisionInfo
 }
 
 type UpdateDeploymentRevisionOption struct {
 	Status *modelschemas.DeploymentRevisionStatus
+	Info   **models.DeploymentRevisionInfo
 }
 
 type ListDeploymentRevisionOption struct {
@@ -46,6 +51,8 @@ type ListDeploymentRevisionOption struct {
 	DeploymentIds *[]uint
 	Ids           *[]uint
 	Status        *modelschemas.DeploymentRevisionStatus
+
+	CanaryRolloutStatus *models.DeploymentCanaryRolloutStatus
 }
 
 func (*deploymentRevisionService) Create(ctx context.Context, opt CreateDeploymentRevisionOption) (*models.DeploymentRevision, error) {
@@ -57,6 +64,7 @@ func (*deploymentRevisionService) Create(ctx context.Context, opt CreateDeployme
 			DeploymentId: opt.DeploymentId,
 		},
 		Status: opt.Status,
+		Info:   opt.Info,
 	}
 	err := mustGetSession(ctx).Create(&deploymentRevision).Error
 	if err != nil {
@@ -77,6 +85,15 @@ func (s *deploymentRevisionService) Update(ctx context.Context, deploymentRevisi
 		}()
 	}
 
+	if opt.Info != nil {
+		updaters["info"] = *opt.Info
+		defer func() {
+			if err == nil {
+				deploymentRevision.Info = *opt.Info
+			}
+		}()
+	}
+
 	if len(updaters) == 0 {
 		return deploymentRevision, nil
 	}
@@ -127,6 +144,9 @@ func (s *deploymentRevisionService) List(ctx context.Context, opt ListDeployment
 	if opt.Ids != nil {
 		query = query.Where("deployment_revision.id in (?)", *opt.Ids)
 	}
+	if opt.CanaryRolloutStatus != nil {
+		query = query.Where("(deployment_revision.info::jsonb -> 'canary_rollout' ->> 'status') = ?", *opt.CanaryRolloutStatus)
+	}
 	query = opt.BindQueryWithLabels(query, modelschemas.ResourceTypeDeploymentRevision)
 	query = query.Select("distinct(deployment_revision.*)")
 	var total int64
@@ -244,17 +264,16 @@ func (s *deploymentRevisionService) Terminate(ctx context.Context, deploymentRev
 		err = errors.Wrap(err, "get associated deployment")
 		return err
 	}
-	cluster, err := ClusterService.GetAssociatedCluster(ctx, deployment)
+	// canary targets are owned by the revision
+	err = s.DeleteKubeOwnerReferences(ctx, deploymentRevision)
 	if err != nil {
-		err = errors.Wrap(err, "get associated cluster")
 		return err
 	}
-	yataiDeploymentComp, err := YataiComponentService.GetByName(ctx, cluster.ID, string(modelschemas.YataiComponentNameDeployment))
+	useV1alpha3, err := KubeBentoDeploymentService.UseV1alpha3(ctx, deployment)
 	if err != nil {
-		err = errors.Wrap(err, "get yatai deployment component")
 		return err
 	}
-	if yataiDeploymentComp.Manifest == nil && yataiDeploymentComp.Manifest.LatestCRDVersion == "v1alpha3" {
+	if useV1alpha3 {
 		cli, err := DeploymentService.GetKubeBentoDeploymentV1alpha3Cli(ctx, deployment)
 		if err != nil {
 			return err
@@ -364,6 +383,17 @@ func (s *deploymentRevisionService) Deploy(ctx context.Context, deploymentRevisi
 		}
 	}
 
+	for _, deploymentTarget := range deploymentTargets {
+		if deploymentTarget.Type == modelschemas.DeploymentTargetTypeCanary {
+			deployOption.OwnerReferences, err = s.MakeSureKubeOwnerReferences(ctx, deploymentRevision)
+			if err != nil {
+				err = errors.Wrap(err, "make sure kube owner references")
+				return
+			}
+			break
+		}
+	}
+
 	// Can not use goroutine here because of pgx transaction bug
 	for _, deploymentTarget := range deploymentTargets {
 		_, err = DeploymentTargetService.Deploy(ctx, deploymentTarget, deployOption)
@@ -372,9 +402,37 @@ func (s *deploymentRevisionService) Deploy(ctx context.Context, deploymentRevisi
 		}
 	}
 
+	err = CanaryRolloutService.Start(ctx, deploymentRevision, deploymentTargets)
+	if err != nil {
+		err = errors.Wrap(err, "start canary rollout")
+		return
+	}
+
 	return nil
 }
 
+// DryRun submits the BentoDeployments of the deployment targets with server-side dry-run, nothing is applied to the cluster.
+func (s *deploymentRevisionService) DryRun(ctx context.Context, deploymentRevision *models.DeploymentRevision, deploymentTargets []*models.DeploymentTarget) ([]*KubeBentoDeploymentDryRunResult, error) {
+	if len(deploymentTargets) == 0 {
+		var err error
+		deploymentTargets, _, err = DeploymentTargetService.List(ctx, ListDeploymentTargetOption{
+			DeploymentRevisionId: utils.UintPtr(deploymentRevision.ID),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	res := make([]*KubeBentoDeploymentDryRunResult, 0, len(deploymentTargets))
+	for _, deploymentTarget := range deploymentTargets {
+		result, err := KubeBentoDeploymentService.DryRun(ctx, deploymentTarget)
+		if err != nil {
+			return nil, errors.Wrap(err, "dry run kube bento deployment")
+		}
+		res = append(res, result)
+	}
+	return res, nil
+}
+
 func (s *deploymentRevisionService) GetKubeCliSet(ctx context.Context, deploymentRevision *models.DeploymentRevision) (kubeCli *kubernetes.Clientset, restConfig *rest.Config, err error) {
 	deployment, err := DeploymentService.GetAssociatedDeployment(ctx, deploymentRevision)
 	if err != nil {
@@ -402,3 +460,210 @@ func (s *deploymentRevisionService) GetAssociatedDeploymentRevision(ctx context.
 	associate.SetAssociatedDeploymentRevisionCache(deployment)
 	return deployment, err
 }
+
+// Rollback clones the targets of a previous revision into a new active revision and deploys it.
+// The follow policy of the deployment is disabled, otherwise the next sync would undo the rollback.
+func (s *deploymentRevisionService) Rollback(ctx context.Context, sourceDeploymentRevision *models.DeploymentRevision) (*models.DeploymentRevision, error) {
+	return s.rollback(ctx, sourceDeploymentRevision, &models.DeploymentRevisionInfo{
+		RollbackFromRevisionUid: sourceDeploymentRevision.Uid,
+	}, "rolled back")
+}
+
+func (s *deploymentRevisionService) rollback(ctx context.Context, sourceDeploymentRevision *models.DeploymentRevision, info *models.DeploymentRevisionInfo, operationName string) (deploymentRevision *models.DeploymentRevision, err error) {
+	if sourceDeploymentRevision.Status == modelschemas.DeploymentRevisionStatusActive {
+		err = errors.Errorf("deployment revision %s is already active", sourceDeploymentRevision.Uid)
+		return
+	}
+	user, err := GetCurrentUser(ctx)
+	if err != nil {
+		return
+	}
+	deployment, err := DeploymentService.GetAssociatedDeployment(ctx, sourceDeploymentRevision)
+	if err != nil {
+		return
+	}
+	cluster, err := ClusterService.GetAssociatedCluster(ctx, deployment)
+	if err != nil {
+		return
+	}
+
+	defer func() {
+		createEventOpt := CreateEventOption{
+			Name:           sourceDeploymentRevision.Uid,
+			OrganizationId: &cluster.OrganizationId,
+			ClusterId:      &cluster.ID,
+			ResourceType:   modelschemas.ResourceTypeDeployment,
+			ResourceId:     deployment.ID,
+			Status:         modelschemas.EventStatusSuccess,
+			OperationName:  operationName,
+		}
+		if err != nil {
+			createEventOpt.Status = modelschemas.EventStatusFailed
+		}
+		if _, err_ := EventService.CreateByCurrentUser(ctx, createEventOpt); err_ != nil {
+			logrus.Errorf("create event failed: %v", err_)
+		}
+	}()
+
+	// nolint: ineffassign,staticcheck
+	_, txCtx, df, err := startTransaction(ctx)
+	if err != nil {
+		return
+	}
+	defer func() { df(err) }()
+
+	sourceDeploymentTargets, _, err := DeploymentTargetService.List(txCtx, ListDeploymentTargetOption{
+		DeploymentRevisionId: utils.UintPtr(sourceDeploymentRevision.ID),
+	})
+	if err != nil {
+		err = errors.Wrap(err, "list deployment targets")
+		return
+	}
+	if len(sourceDeploymentTargets) == 0 {
+		err = errors.Errorf("deployment revision %s has no deployment targets", sourceDeploymentRevision.Uid)
+		return
+	}
+
+	clearedFollowPolicy := deployment.FollowPolicy
+	info.ClearedFollowPolicy = clearedFollowPolicy
+
+	deploymentRevision, err = s.Create(txCtx, CreateDeploymentRevisionOption{
+		CreatorId:    user.ID,
+		DeploymentId: deployment.ID,
+		Status:       modelschemas.DeploymentRevisionStatusActive,
+		Info:         info,
+	})
+	if err != nil {
+		err = errors.Wrap(err, "create deployment revision")
+		return
+	}
+
+	deploymentTargets := make([]*models.DeploymentTarget, 0, len(sourceDeploymentTargets))
+	for _, sourceDeploymentTarget := range sourceDeploymentTargets {
+		var deploymentTarget *models.DeploymentTarget
+		deploymentTarget, err = DeploymentTargetService.Clone(txCtx, sourceDeploymentTarget, user.ID, deploymentRevision.ID, sourceDeploymentTarget.BentoId)
+		if err != nil {
+			err = errors.Wrap(err, "create deployment target")
+			return
+		}
+		deploymentTargets = append(deploymentTargets, deploymentTarget)
+	}
+
+	if clearedFollowPolicy != nil {
+		var followPolicy *models.DeploymentFollowPolicy
+		_, err = DeploymentService.Update(txCtx, deployment, UpdateDeploymentOption{
+			FollowPolicy: &followPolicy,
+		})
+		if err != nil {
+			err = errors.Wrap(err, "disable deployment follow policy")
+			return
+		}
+		followedBento := clearedFollowPolicy.Alias
+		if followedBento == "" {
+			followedBento = "latest"
+		}
+		_, err = EventService.CreateByCurrentUser(txCtx, CreateEventOption{
+			Name:           followedBento,
+			OrganizationId: &cluster.OrganizationId,
+			ClusterId:      &cluster.ID,
+			ResourceType:   modelschemas.ResourceTypeDeployment,
+			ResourceId:     deployment.ID,
+			Status:         modelschemas.EventStatusSuccess,
+			OperationName:  "disabled auto deploy",
+		})
+		if err != nil {
+			err = errors.Wrap(err, "create event")
+			return
+		}
+	}
+
+	err = s.Deploy(txCtx, deploymentRevision, deploymentTargets, false)
+	if err != nil {
+		err = errors.Wrap(err, "deploy deployment revision")
+	}
+	return
+}
+
+// GetPrevious returns the revision created right before the given one.
+func (s *deploymentRevisionService) GetPrevious(ctx context.Context, deploymentRevision *models.DeploymentRevision) (*models.DeploymentRevision, error) {
+	var previous models.DeploymentRevision
+	err := getBaseQuery(ctx, s).Where("deployment_id = ?", deploymentRevision.DeploymentId).Where("id < ?", deploymentRevision.ID).Order("id DESC").First(&previous).Error
+	if err != nil {
+		return nil, err
+	}
+	if previous.ID == 0 {
+		return nil, consts.ErrNotFound
+	}
+	return &previous, nil
+}
+
+type deploymentTargetSnapshot struct {
+	Type            modelschemas.DeploymentTargetType         `json:"type"`
+	Bento           string                                    `json:"bento"`
+	CanaryRules     *modelschemas.DeploymentTargetCanaryRules `json:"canary_rules"`
+	Config          *modelschemas.DeploymentTargetConfig      `json:"config"`
+	BentoDeployment *servingv1alpha2.BentoDeployment          `json:"bento_deployment"`
+}
+
+type deploymentRevisionSnapshot struct {
+	Targets []*deploymentTargetSnapshot `json:"targets"`
+}
+
+func (s *deploymentRevisionService) getSnapshot(ctx context.Context, deploymentRevision *models.DeploymentRevision) (*deploymentRevisionSnapshot, error) {
+	deploymentTargets, _, err := DeploymentTargetService.List(ctx, ListDeploymentTargetOption{
+		DeploymentRevisionId: utils.UintPtr(deploymentRevision.ID),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "list deployment targets")
+	}
+	snapshot := &deploymentRevisionSnapshot{
+		Targets: make([]*deploymentTargetSnapshot, 0, len(deploymentTargets)),
+	}
+	for _, deploymentTarget := range deploymentTargets {
+		bento, err := BentoService.GetAssociatedBento(ctx, deploymentTarget)
+		if err != nil {
+			return nil, errors.Wrap(err, "get associated bento")
+		}
+		tag, err := BentoService.GetTag(ctx, bento)
+		if err != nil {
+			return nil, errors.Wrap(err, "get bento tag")
+		}
+		var config *modelschemas.DeploymentTargetConfig
+		if deploymentTarget.Config != nil {
+			config_ := *deploymentTarget.Config
+			// the kube resource version changes on every sync, it is not a change of the revision
+			config_.KubeResourceVersion = ""
+			config_.KubeResourceUid = ""
+			config = &config_
+		}
+		kubeBentoDeployment, err := KubeBentoDeploymentService.transformToBentoDeploymentV1alpha2(ctx, deploymentTarget)
+		if err != nil {
+			return nil, errors.Wrap(err, "transform to kube bento deployment")
+		}
+		runners := kubeBentoDeployment.Spec.Runners
+		sort.SliceStable(runners, func(i, j int) bool {
+			return runners[i].Name < runners[j].Name
+		})
+		snapshot.Targets = append(snapshot.Targets, &deploymentTargetSnapshot{
+			Type:            deploymentTarget.Type,
+			Bento:           string(tag),
+			CanaryRules:     deploymentTarget.CanaryRules,
+			Config:          config,
+			BentoDeployment: kubeBentoDeployment,
+		})
+	}
+	return snapshot, nil
+}
+
+// Diff compares the targets of two revisions, including the rendered BentoDeployment CRs.
+func (s *deploymentRevisionService) Diff(ctx context.Context, oldDeploymentRevision, newDeploymentRevision *models.DeploymentRevision) ([]utils.DiffItem, error) {
+	oldSnapshot, err := s.getSnapshot(ctx, oldDeploymentRevision)
+	if err != nil {
+		return nil, errors.Wrapf(err, "get deployment revision %s snapshot", oldDeploymentRevision.Uid)
+	}
+	newSnapshot, err := s.getSnapshot(ctx, newDeploymentRevision)
+	if err != nil {
+		return nil, errors.Wrapf(err, "get deployment revision %s snapshot", newDeploymentRevision.Uid)
+	}
+	return utils.Diff(oldSnapshot, newSnapshot)
+}
@@ -0,0 +1,179 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/bentoml/yatai-schemas/modelschemas"
+	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/common/utils"
+)
+
+type deploymentStatusHistoryService struct{}
+
+var DeploymentStatusHistoryService = deploymentStatusHistoryService{}
+
+func (s *deploymentStatusHistoryService) getBaseDB(ctx context.Context) *gorm.DB {
+	return mustGetSession(ctx).Model(&models.DeploymentStatusHistory{})
+}
+
+type CreateDeploymentStatusHistoryOption struct {
+	DeploymentId uint
+	FromStatus   *modelschemas.DeploymentStatus
+	Status       modelschemas.DeploymentStatus
+	Reason       *models.DeploymentStatusReason
+}
+
+type ListDeploymentStatusHistoryOption struct {
+	BaseListOption
+	DeploymentId *uint
+}
+
+func (s *deploymentStatusHistoryService) Create(ctx context.Context, opt CreateDeploymentStatusHistoryOption) (*models.DeploymentStatusHistory, error) {
+	deploymentStatusHistory := models.DeploymentStatusHistory{
+		DeploymentAssociate: models.DeploymentAssociate{
+			DeploymentId: opt.DeploymentId,
+		},
+		FromStatus: opt.FromStatus,
+		Status:     opt.Status,
+		Reason:     opt.Reason,
+	}
+	err := s.getBaseDB(ctx).Create(&deploymentStatusHistory).Error
+	if err != nil {
+		return nil, err
+	}
+	return &deploymentStatusHistory, nil
+}
+
+func (s *deploymentStatusHistoryService) List(ctx context.Context, opt ListDeploymentStatusHistoryOption) ([]*models.DeploymentStatusHistory, uint, error) {
+	query := getBaseQuery(ctx, s)
+	if opt.DeploymentId != nil {
+		query = query.Where("deployment_id = ?", *opt.DeploymentId)
+	}
+	var total int64
+	err := query.Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	query = opt.BindQueryWithLimit(query)
+	deploymentStatusHistories := make([]*models.DeploymentStatusHistory, 0)
+	err = query.Order("id DESC").Find(&deploymentStatusHistories).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return deploymentStatusHistories, uint(total), err
+}
+
+func (s *deploymentStatusHistoryService) MakeReason(pods []*models.KubePodWithStatus) *models.DeploymentStatusReason {
+	reason := &models.DeploymentStatusReason{
+		Pods: make([]*models.DeploymentStatusPodReason, 0, len(pods)),
+	}
+	for _, pod := range pods {
+		podReason := &models.DeploymentStatusPodReason{
+			Name:   pod.Pod.Name,
+			Phase:  pod.Status.Phase,
+			Status: pod.Status.Status,
+		}
+		for _, warning := range pod.Warnings {
+			podReason.Warnings = append(podReason.Warnings, fmt.Sprintf("%s: %s", warning.Reason, warning.Message))
+		}
+		reason.Pods = append(reason.Pods, podReason)
+	}
+	return reason
+}
+
+type DeploymentTimelineItemType string
+
+const (
+	DeploymentTimelineItemTypeStatus   DeploymentTimelineItemType = "status"
+	DeploymentTimelineItemTypeRevision DeploymentTimelineItemType = "revision"
+	DeploymentTimelineItemTypeEvent    DeploymentTimelineItemType = "event"
+)
+
+type DeploymentTimelineItem struct {
+	Type          DeploymentTimelineItemType
+	CreatedAt     time.Time
+	StatusHistory *models.DeploymentStatusHistory
+	Revision      *models.DeploymentRevision
+	Event         *models.Event
+}
+
+// ListTimeline merges the status changes, revisions and events of the deployment, latest first.
+// Every source is listed up to the end of the requested page, so the merged page is exact.
+func (s *deploymentStatusHistoryService) ListTimeline(ctx context.Context, deployment *models.Deployment, opt BaseListOption) ([]*DeploymentTimelineItem, uint, error) {
+	var start, count uint
+	if opt.Start != nil {
+		start = *opt.Start
+	}
+	count = 20
+	if opt.Count != nil && *opt.Count > 0 {
+		count = *opt.Count
+	}
+	limitOpt := BaseListOption{
+		Start: utils.UintPtr(0),
+		Count: utils.UintPtr(start + count),
+	}
+
+	deploymentStatusHistories, statusTotal, err := s.List(ctx, ListDeploymentStatusHistoryOption{
+		BaseListOption: limitOpt,
+		DeploymentId:   utils.UintPtr(deployment.ID),
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	deploymentRevisions, revisionTotal, err := DeploymentRevisionService.List(ctx, ListDeploymentRevisionOption{
+		BaseListOption: limitOpt,
+		DeploymentId:   utils.UintPtr(deployment.ID),
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	events, eventTotal, err := EventService.List(ctx, ListEventOption{
+		BaseListOption: limitOpt,
+		ResourceType:   modelschemas.ResourceTypeDeployment.Ptr(),
+		ResourceId:     utils.UintPtr(deployment.ID),
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	items := make([]*DeploymentTimelineItem, 0, len(deploymentStatusHistories)+len(deploymentRevisions)+len(events))
+	for _, deploymentStatusHistory := range deploymentStatusHistories {
+		items = append(items, &DeploymentTimelineItem{
+			Type:          DeploymentTimelineItemTypeStatus,
+			CreatedAt:     deploymentStatusHistory.CreatedAt,
+			StatusHistory: deploymentStatusHistory,
+		})
+	}
+	for _, deploymentRevision := range deploymentRevisions {
+		items = append(items, &DeploymentTimelineItem{
+			Type:      DeploymentTimelineItemTypeRevision,
+			CreatedAt: deploymentRevision.CreatedAt,
+			Revision:  deploymentRevision,
+		})
+	}
+	for _, event := range events {
+		items = append(items, &DeploymentTimelineItem{
+			Type:      DeploymentTimelineItemTypeEvent,
+			CreatedAt: event.CreatedAt,
+			Event:     event,
+		})
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].CreatedAt.After(items[j].CreatedAt)
+	})
+
+	total := statusTotal + revisionTotal + eventTotal
+	if start >= uint(len(items)) {
+		return []*DeploymentTimelineItem{}, total, nil
+	}
+	end := start + count
+	if end > uint(len(items)) {
+		end = uint(len(items))
+	}
+	return items[start:end], total, nil
+}
@@ -0,0 +1,259 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+
+	commonconsts "github.com/bentoml/yatai-common/consts"
+	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/common/metrics"
+	"github.com/bentoml/yatai/common/utils"
+)
+
+const (
+	deploymentStatusWatcherWorkers = 8
+	// events of a deployment are coalesced within this delay, a rollout touches many pods at once
+	deploymentStatusWatcherDelay = 2 * time.Second
+	// new deployment namespaces are picked up at this interval
+	deploymentStatusWatcherRefreshInterval = time.Minute
+)
+
+type deploymentStatusWatch struct {
+	cancel context.CancelFunc
+	// the BentoDeployment CR version watched, the namespace is watched again when the cluster upgrades its CRD
+	version string
+}
+
+type deploymentStatusWatcherService struct {
+	mu sync.Mutex
+	// informers of the namespaces, keyed by cluster id and namespace
+	watching map[string]*deploymentStatusWatch
+	queue    workqueue.DelayingInterface
+}
+
+// DeploymentStatusWatcherService recomputes the status of a deployment as soon as
+// one of its pods or BentoDeployment CRs changes in the cluster.
+var DeploymentStatusWatcherService = deploymentStatusWatcherService{}
+
+// Run blocks until ctx is done and the in-flight syncs returned.
+func (s *deploymentStatusWatcherService) Run(ctx context.Context) {
+	queue := workqueue.NewNamedDelayingQueue("deployment-status")
+	s.mu.Lock()
+	s.watching = make(map[string]*deploymentStatusWatch)
+	s.queue = queue
+	s.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for i := 0; i < deploymentStatusWatcherWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.work(ctx, queue)
+		}()
+	}
+
+	ticker := time.NewTicker(deploymentStatusWatcherRefreshInterval)
+	defer ticker.Stop()
+	for {
+		if err := s.refresh(ctx); err != nil {
+			logrus.Errorf("refresh deployment status watchers: %s", err.Error())
+		}
+		select {
+		case <-ctx.Done():
+			queue.ShutDown()
+			wg.Wait()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// refresh starts the informers of the namespaces that got their first deployment, and stops the
+// informers of the namespaces without deployments anymore, e.g. their cluster was deleted.
+func (s *deploymentStatusWatcherService) refresh(ctx context.Context) error {
+	deployments, _, err := DeploymentService.List(ctx, ListDeploymentOption{})
+	if err != nil {
+		return errors.Wrap(err, "list deployments")
+	}
+	namespacesByCluster := make(map[uint]map[string]struct{})
+	for _, deployment := range deployments {
+		namespaces, ok := namespacesByCluster[deployment.ClusterId]
+		if !ok {
+			namespaces = make(map[string]struct{})
+			namespacesByCluster[deployment.ClusterId] = namespaces
+		}
+		namespaces[DeploymentService.GetKubeNamespace(deployment)] = struct{}{}
+	}
+
+	keys := make(map[string]struct{})
+	for clusterId, namespaces := range namespacesByCluster {
+		for namespace := range namespaces {
+			keys[s.getWatchKey(clusterId, namespace)] = struct{}{}
+		}
+	}
+	s.mu.Lock()
+	for key, watch := range s.watching {
+		if _, ok := keys[key]; !ok {
+			watch.cancel()
+			delete(s.watching, key)
+			logrus.Infof("stopped watching deployments of %s", key)
+		}
+	}
+	s.mu.Unlock()
+
+	for clusterId, namespaces := range namespacesByCluster {
+		cluster, err := ClusterService.Get(ctx, clusterId)
+		if err != nil {
+			logrus.Errorf("get cluster %d: %s", clusterId, err.Error())
+			continue
+		}
+		// the same selector as the deploy, so the watched CRs are the ones written
+		useV1alpha3, err := KubeBentoDeploymentService.UseV1alpha3ByCluster(ctx, cluster)
+		if err != nil {
+			logrus.Errorf("get bento deployment version of cluster %s: %s", cluster.Name, err.Error())
+			continue
+		}
+		version := "v1alpha2"
+		if useV1alpha3 {
+			version = "v1alpha3"
+		}
+		for namespace := range namespaces {
+			key := s.getWatchKey(clusterId, namespace)
+			s.mu.Lock()
+			watch, ok := s.watching[key]
+			if ok && watch.version != version {
+				watch.cancel()
+				delete(s.watching, key)
+				ok = false
+			}
+			s.mu.Unlock()
+			if ok {
+				continue
+			}
+			err = s.watch(ctx, cluster, namespace, version)
+			if err != nil {
+				logrus.Errorf("watch deployments of cluster %s in namespace %s: %s", cluster.Name, namespace, err.Error())
+			}
+		}
+	}
+	return nil
+}
+
+func (s *deploymentStatusWatcherService) getWatchKey(clusterId uint, namespace string) string {
+	return fmt.Sprintf("%d/%s", clusterId, namespace)
+}
+
+// watch starts the shared informers of kube_informer.go, they live until the namespace is not watched anymore.
+func (s *deploymentStatusWatcherService) watch(ctx context.Context, cluster *models.Cluster, namespace string, version string) (err error) {
+	watchCtx, cancel := context.WithCancel(ctx)
+	defer func() {
+		if err != nil {
+			cancel()
+		}
+	}()
+	podInformer, _, err := GetPodInformer(watchCtx, cluster, namespace)
+	if err != nil {
+		return errors.Wrap(err, "get pod informer")
+	}
+	bentoDeploymentInformer, err := GetBentoDeploymentInformer(watchCtx, cluster, namespace, version)
+	if err != nil {
+		return errors.Wrap(err, "get bento deployment informer")
+	}
+	podInformer.Informer().AddEventHandler(s.makeEventHandler(cluster.ID, namespace, false))
+	bentoDeploymentInformer.AddEventHandler(s.makeEventHandler(cluster.ID, namespace, true))
+
+	s.mu.Lock()
+	s.watching[s.getWatchKey(cluster.ID, namespace)] = &deploymentStatusWatch{
+		cancel:  cancel,
+		version: version,
+	}
+	s.mu.Unlock()
+	logrus.Infof("watching deployments of cluster %s in namespace %s", cluster.Name, namespace)
+	return nil
+}
+
+// makeEventHandler enqueues the deployment owning the object, pods and canary CRs carry
+// the deployment name label and the stable CR is named after the deployment.
+func (s *deploymentStatusWatcherService) makeEventHandler(clusterId uint, namespace string, isBentoDeployment bool) cache.ResourceEventHandler {
+	queue := s.queue
+	enqueue := func(obj interface{}) {
+		if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+			obj = tombstone.Obj
+		}
+		object, err := meta.Accessor(obj)
+		if err != nil {
+			return
+		}
+		name := object.GetLabels()[commonconsts.KubeLabelYataiBentoDeployment]
+		if name == "" && isBentoDeployment {
+			name = object.GetName()
+		}
+		if name == "" {
+			return
+		}
+		queue.AddAfter(fmt.Sprintf("%d/%s/%s", clusterId, namespace, name), deploymentStatusWatcherDelay)
+	}
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc: enqueue,
+		UpdateFunc: func(_, newObj interface{}) {
+			enqueue(newObj)
+		},
+		DeleteFunc: enqueue,
+	}
+}
+
+func (s *deploymentStatusWatcherService) work(ctx context.Context, queue workqueue.DelayingInterface) {
+	for {
+		item, shutdown := queue.Get()
+		if shutdown {
+			return
+		}
+		requeueAfter, err := s.sync(ctx, item.(string))
+		if err != nil {
+			logrus.Errorf("sync deployment %s status: %s", item, err.Error())
+		}
+		queue.Done(item)
+		if requeueAfter > 0 {
+			queue.AddAfter(item, requeueAfter)
+		}
+	}
+}
+
+// sync returns the delay after which the deployment has to be synced again for its auto rollback check
+func (s *deploymentStatusWatcherService) sync(ctx context.Context, key string) (time.Duration, error) {
+	pieces := strings.SplitN(key, "/", 3)
+	if len(pieces) != 3 {
+		return 0, errors.Errorf("invalid key %s", key)
+	}
+	clusterId, err := strconv.ParseUint(pieces[0], 10, 64)
+	if err != nil {
+		return 0, errors.Wrapf(err, "invalid key %s", key)
+	}
+	deployment, err := DeploymentService.GetByName(ctx, uint(clusterId), pieces[1], pieces[2])
+	if err != nil {
+		if utils.IsNotFound(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+	start := time.Now()
+	_, err = DeploymentService.SyncStatus(ctx, deployment)
+	metrics.ObserveDeploymentSync(metrics.DeploymentSyncSourceWatcher, start, err)
+	if err != nil {
+		return 0, err
+	}
+	requeueAfter, err := DeploymentAutoRollbackService.Check(ctx, deployment.ID)
+	return requeueAfter, errors.Wrap(err, "auto rollback")
+}
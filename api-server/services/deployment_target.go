@@ -45,6 +45,7 @@ type ListDeploymentTargetOption struct {
 	DeploymentIds            *[]uint
 	DeploymentRevisionId     *uint
 	DeploymentRevisionIds    *[]uint
+	BentoIds                 *[]uint
 	Type                     *modelschemas.DeploymentTargetType
 }
 
@@ -93,6 +94,30 @@ func (*deploymentTargetService) Create(ctx context.Context, opt CreateDeployment
 	return &deploymentTarget, err
 }
 
+// cloneDeploymentTargetConfig copies the config without the state of the kube resource it was deployed as.
+func cloneDeploymentTargetConfig(config *modelschemas.DeploymentTargetConfig) *modelschemas.DeploymentTargetConfig {
+	if config == nil {
+		return nil
+	}
+	config_ := *config
+	config_.KubeResourceVersion = ""
+	config_.KubeResourceUid = ""
+	return &config_
+}
+
+// Clone copies the target into another revision, optionally pointing it to another bento.
+func (s *deploymentTargetService) Clone(ctx context.Context, deploymentTarget *models.DeploymentTarget, creatorId, deploymentRevisionId, bentoId uint) (*models.DeploymentTarget, error) {
+	return s.Create(ctx, CreateDeploymentTargetOption{
+		CreatorId:            creatorId,
+		DeploymentId:         deploymentTarget.DeploymentId,
+		DeploymentRevisionId: deploymentRevisionId,
+		BentoId:              bentoId,
+		Type:                 deploymentTarget.Type,
+		CanaryRules:          deploymentTarget.CanaryRules,
+		Config:               cloneDeploymentTargetConfig(deploymentTarget.Config),
+	})
+}
+
 func (s *deploymentTargetService) Get(ctx context.Context, id uint) (*models.DeploymentTarget, error) {
 	var deploymentTarget models.DeploymentTarget
 	err := getBaseQuery(ctx, s).Where("id = ?", id).First(&deploymentTarget).Error
@@ -134,6 +159,9 @@ func (s *deploymentTargetService) List(ctx context.Context, opt ListDeploymentTa
 	if opt.DeploymentRevisionIds != nil {
 		query = query.Where("deployment_target.deployment_revision_id in (?)", *opt.DeploymentRevisionIds)
 	}
+	if opt.BentoIds != nil {
+		query = query.Where("deployment_target.bento_id in (?)", *opt.BentoIds)
+	}
 	if opt.Type != nil {
 		query = query.Where("deployment_target.type = ?", *opt.Type)
 	}
@@ -227,17 +255,11 @@ func (s *deploymentTargetService) Deploy(ctx context.Context, deploymentTarget *
 		return
 	}
 
-	cluster, err := ClusterService.GetAssociatedCluster(ctx, deployment)
-	if err != nil {
-		err = errors.Wrap(err, "get associated cluster")
-		return
-	}
-	yataiDeploymentComp, err := YataiComponentService.GetByName(ctx, cluster.ID, string(modelschemas.YataiComponentNameDeployment))
+	useV1alpha3, err := KubeBentoDeploymentService.UseV1alpha3(ctx, deployment)
 	if err != nil {
-		err = errors.Wrap(err, "get yatai deployment component")
 		return
 	}
-	if yataiDeploymentComp.Manifest == nil && yataiDeploymentComp.Manifest.LatestCRDVersion == "v1alpha3" {
+	if useV1alpha3 {
 		_, err = KubeBentoDeploymentService.DeployV1alpha3(ctx, deploymentTarget, deployOption)
 	} else {
 		_, err = KubeBentoDeploymentService.DeployV1alpha2(ctx, deploymentTarget, deployOption)
@@ -89,6 +89,18 @@ func (s *eventService) Create(ctx context.Context, opt CreateEventOption) (event
 	return
 }
 
+func (s *eventService) CreateByCurrentUser(ctx context.Context, opt CreateEventOption) (*models.Event, error) {
+	user, err := GetCurrentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	opt.CreatorId = user.ID
+	if user.ApiToken != nil {
+		opt.ApiTokenName = user.ApiToken.Name
+	}
+	return s.Create(ctx, opt)
+}
+
 func (s *eventService) ListOperationNames(ctx context.Context, organizationId uint, resourceType modelschemas.ResourceType) (names []string, err error) {
 	db := s.getBaseDB(ctx)
 	query := db.Raw(`select distinct(operation_name) from event where organization_id = ? and resource_type = ?`, organizationId, resourceType)
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/bentoml/yatai-schemas/modelschemas"
+	"github.com/bentoml/yatai/api-server/config"
+	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/common/utils"
+)
+
+var memberRoleRanks = map[modelschemas.MemberRole]int{
+	modelschemas.MemberRoleGuest:     1,
+	modelschemas.MemberRoleDeveloper: 2,
+	modelschemas.MemberRoleAdmin:     3,
+}
+
+// mapGroupRoles returns the role in every mapped organization, the highest role wins when
+// several groups of the user are mapped to the same organization. An organization is mapped
+// to the empty role when none of the groups of the user grant a role in it.
+func mapGroupRoles(mappings []config.YataiGroupMappingYaml, groups []string) map[string]modelschemas.MemberRole {
+	userGroups := make(map[string]struct{}, len(groups))
+	for _, group := range groups {
+		userGroups[group] = struct{}{}
+	}
+	res := make(map[string]modelschemas.MemberRole)
+	for _, mapping := range mappings {
+		if _, ok := res[mapping.Organization]; !ok {
+			res[mapping.Organization] = ""
+		}
+		if _, ok := userGroups[mapping.Group]; !ok {
+			continue
+		}
+		if memberRoleRanks[mapping.Role] > memberRoleRanks[res[mapping.Organization]] {
+			res[mapping.Organization] = mapping.Role
+		}
+	}
+	return res
+}
+
+// syncGroupMemberships grants and revokes the memberships of the mapped organizations by the
+// external groups of the user, the organizations which are not mapped are left untouched.
+func syncGroupMemberships(ctx context.Context, user *models.User, mappings []config.YataiGroupMappingYaml, groups []string) error {
+	for orgName, role := range mapGroupRoles(mappings, groups) {
+		org, err := OrganizationService.GetByName(ctx, orgName)
+		if err != nil {
+			if utils.IsNotFound(err) {
+				logrus.Warnf("the organization %s of the group mappings is not found", orgName)
+				continue
+			}
+			return errors.Wrapf(err, "get organization %s", orgName)
+		}
+		member, err := OrganizationMemberService.GetBy(ctx, user.ID, org.ID)
+		if err != nil && !utils.IsNotFound(err) {
+			return errors.Wrapf(err, "get member of organization %s", orgName)
+		}
+		isMember := err == nil
+		switch {
+		case role == "" && isMember:
+			_, err = OrganizationMemberService.Delete(ctx, member, user.ID)
+		case role != "" && (!isMember || member.Role != role):
+			_, err = OrganizationMemberService.Create(ctx, user.ID, CreateOrganizationMemberOption{
+				CreatorId:      user.ID,
+				UserId:         user.ID,
+				OrganizationId: org.ID,
+				Role:           role,
+			})
+		}
+		if err != nil {
+			return errors.Wrapf(err, "sync member of organization %s", orgName)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/bentoml/yatai/api-server/config"
+	"github.com/bentoml/yatai/api-server/models"
+)
+
+type healthService struct{}
+
+var HealthService = healthService{}
+
+func (s *healthService) CheckDB(ctx context.Context) error {
+	db, err := getDB()
+	if err != nil {
+		return err
+	}
+	rawDb, err := db.DB()
+	if err != nil {
+		return errors.Wrap(err, "get raw db")
+	}
+	return rawDb.PingContext(ctx)
+}
+
+// CheckS3 checks the buckets of the globally configured S3, organizations may bring their own S3 which is not checked.
+func (s *healthService) CheckS3(ctx context.Context) error {
+	if config.YataiConfig.S3 == nil {
+		return nil
+	}
+	s3Config, err := OrganizationService.GetS3Config(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "get s3 config")
+	}
+	bucketNames := []string{s3Config.BentosBucketName}
+	if s3Config.ModelsBucketName != s3Config.BentosBucketName {
+		bucketNames = append(bucketNames, s3Config.ModelsBucketName)
+	}
+	for _, bucketName := range bucketNames {
+		err = s3Config.CheckBucket(ctx, bucketName)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *healthService) CheckMigration(ctx context.Context) error {
+	status, err := GetMigrationStatus(ctx)
+	if err != nil {
+		return err
+	}
+	if status.Dirty {
+		return errors.Errorf("migration %d is dirty", status.Version)
+	}
+	// a newer replica may have migrated ahead during a rolling upgrade, the old replicas stay ready
+	if status.Version < status.LatestVersion {
+		return errors.Errorf("migration version is %d, expected %d", status.Version, status.LatestVersion)
+	}
+	return nil
+}
+
+func (s *healthService) CheckCluster(ctx context.Context, cluster *models.Cluster) error {
+	clientset, _, err := ClusterService.GetKubeCliSet(ctx, cluster)
+	if err != nil {
+		return errors.Wrap(err, "get kube cli set")
+	}
+	_, err = clientset.Discovery().RESTClient().Get().AbsPath("/version").Do(ctx).Raw()
+	return err
+}
@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/pkg/errors"
@@ -10,9 +11,12 @@ import (
 
 	"github.com/bentoml/yatai-schemas/modelschemas"
 	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/common/utils"
 
 	servingv1alpha2 "github.com/bentoml/yatai-deployment/apis/serving/v1alpha2"
 	servingv1alpha3 "github.com/bentoml/yatai-deployment/apis/serving/v1alpha3"
+	servingv1alpha2client "github.com/bentoml/yatai-deployment/generated/serving/clientset/versioned/typed/serving/v1alpha2"
+	servingv1alpha3client "github.com/bentoml/yatai-deployment/generated/serving/clientset/versioned/typed/serving/v1alpha3"
 )
 
 type kubeBentoDeploymentService struct{}
@@ -79,10 +83,28 @@ func (s *kubeBentoDeploymentService) transformToBentoDeploymentV1alpha2(ctx cont
 		ingress.Enabled = true
 	}
 
+	kubeName := deployment.Name
+	var kubeLabels map[string]string
+	if deploymentTarget.Type == modelschemas.DeploymentTargetTypeCanary {
+		// canary targets run next to the stable target, their traffic is routed by the canary ingress
+		kubeName, err = DeploymentTargetService.GetKubeName(ctx, deploymentTarget)
+		if err != nil {
+			err = errors.Wrap(err, "failed to get deployment target kube name")
+			return
+		}
+		kubeLabels, err = DeploymentTargetService.GetKubeLabels(ctx, deploymentTarget)
+		if err != nil {
+			err = errors.Wrap(err, "failed to get deployment target kube labels")
+			return
+		}
+		ingress.Enabled = false
+	}
+
 	kubeBentoDeployment = &servingv1alpha2.BentoDeployment{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      deployment.Name,
+			Name:      kubeName,
 			Namespace: DeploymentService.GetKubeNamespace(deployment),
+			Labels:    kubeLabels,
 		},
 		Spec: servingv1alpha2.BentoDeploymentSpec{
 			BentoTag:    string(tag),
@@ -97,6 +119,140 @@ func (s *kubeBentoDeploymentService) transformToBentoDeploymentV1alpha2(ctx cont
 	return
 }
 
+// UseV1alpha3 reports whether the yatai-deployment component of the cluster serves the v1alpha3 BentoDeployment CRD.
+// The deploy, the terminate, the dry run and the status watcher all pick the CRD version with it.
+func (s *kubeBentoDeploymentService) UseV1alpha3(ctx context.Context, deployment *models.Deployment) (bool, error) {
+	cluster, err := ClusterService.GetAssociatedCluster(ctx, deployment)
+	if err != nil {
+		return false, errors.Wrap(err, "get associated cluster")
+	}
+	return s.UseV1alpha3ByCluster(ctx, cluster)
+}
+
+func (s *kubeBentoDeploymentService) UseV1alpha3ByCluster(ctx context.Context, cluster *models.Cluster) (bool, error) {
+	yataiDeploymentComp, err := YataiComponentService.GetByName(ctx, cluster.ID, string(modelschemas.YataiComponentNameDeployment))
+	if err != nil {
+		return false, errors.Wrap(err, "get yatai deployment component")
+	}
+	return isV1alpha3Manifest(yataiDeploymentComp.Manifest), nil
+}
+
+func isV1alpha3Manifest(manifest *modelschemas.YataiComponentManifestSchema) bool {
+	return manifest != nil && manifest.LatestCRDVersion == "v1alpha3"
+}
+
+// prepareV1alpha2 renders the BentoDeployment of the deployment target and merges the fields managed outside of yatai from the live object.
+func (s *kubeBentoDeploymentService) prepareV1alpha2(ctx context.Context, cli servingv1alpha2client.BentoDeploymentInterface, deploymentTarget *models.DeploymentTarget) (kubeBentoDeployment, oldKubeBentoDeployment *servingv1alpha2.BentoDeployment, err error) {
+	kubeBentoDeployment, err = s.transformToBentoDeploymentV1alpha2(ctx, deploymentTarget)
+	if err != nil {
+		err = errors.Wrap(err, "failed to transform to kube bento deployment")
+		return
+	}
+
+	oldKubeBentoDeployment, err = cli.Get(ctx, kubeBentoDeployment.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		oldKubeBentoDeployment = nil
+		err = nil
+		return
+	}
+	if err != nil {
+		err = errors.Wrap(err, "failed to get kube bento deployment")
+		return
+	}
+
+	kubeBentoDeployment.SetResourceVersion(oldKubeBentoDeployment.GetResourceVersion())
+	if kubeBentoDeployment.Annotations == nil {
+		kubeBentoDeployment.Annotations = make(map[string]string, len(oldKubeBentoDeployment.Annotations))
+	}
+	for k, v := range oldKubeBentoDeployment.Annotations {
+		if _, ok := kubeBentoDeployment.Annotations[k]; !ok {
+			kubeBentoDeployment.Annotations[k] = v
+		}
+	}
+	if kubeBentoDeployment.Labels == nil {
+		kubeBentoDeployment.Labels = make(map[string]string, len(oldKubeBentoDeployment.Labels))
+	}
+	for k, v := range oldKubeBentoDeployment.Labels {
+		if _, ok := kubeBentoDeployment.Labels[k]; !ok {
+			kubeBentoDeployment.Labels[k] = v
+		}
+	}
+	kubeBentoDeployment.Spec.Autoscaling = oldKubeBentoDeployment.Spec.Autoscaling
+	for idx, runner := range kubeBentoDeployment.Spec.Runners {
+		for _, oldRunner := range oldKubeBentoDeployment.Spec.Runners {
+			if runner.Name == oldRunner.Name {
+				kubeBentoDeployment.Spec.Runners[idx].Autoscaling = oldRunner.Autoscaling
+			}
+		}
+	}
+	return
+}
+
+// prepareV1alpha3 is the v1alpha3 counterpart of prepareV1alpha2.
+func (s *kubeBentoDeploymentService) prepareV1alpha3(ctx context.Context, cli servingv1alpha3client.BentoDeploymentInterface, deploymentTarget *models.DeploymentTarget) (kubeBentoDeployment, oldKubeBentoDeployment *servingv1alpha3.BentoDeployment, err error) {
+	kubeBentoDeploymentV1alpha2, err := s.transformToBentoDeploymentV1alpha2(ctx, deploymentTarget)
+	if err != nil {
+		err = errors.Wrap(err, "failed to transform to kube bento deployment")
+		return
+	}
+
+	kubeBentoDeployment = &servingv1alpha3.BentoDeployment{}
+	err = kubeBentoDeploymentV1alpha2.ConvertTo(kubeBentoDeployment)
+	if err != nil {
+		err = errors.Wrap(err, "failed to convert kube bento deployment v1alpha2 to v1alpha3")
+		return
+	}
+
+	oldKubeBentoDeployment, err = cli.Get(ctx, kubeBentoDeployment.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		oldKubeBentoDeployment = nil
+		err = nil
+		return
+	}
+	if err != nil {
+		err = errors.Wrap(err, "failed to get kube bento deployment")
+		return
+	}
+
+	kubeBentoDeployment.SetResourceVersion(oldKubeBentoDeployment.GetResourceVersion())
+	if kubeBentoDeployment.Annotations == nil {
+		kubeBentoDeployment.Annotations = make(map[string]string, len(oldKubeBentoDeployment.Annotations))
+	}
+	for k, v := range oldKubeBentoDeployment.Annotations {
+		if _, ok := kubeBentoDeployment.Annotations[k]; !ok {
+			kubeBentoDeployment.Annotations[k] = v
+		}
+	}
+	if kubeBentoDeployment.Labels == nil {
+		kubeBentoDeployment.Labels = make(map[string]string, len(oldKubeBentoDeployment.Labels))
+	}
+	for k, v := range oldKubeBentoDeployment.Labels {
+		if _, ok := kubeBentoDeployment.Labels[k]; !ok {
+			kubeBentoDeployment.Labels[k] = v
+		}
+	}
+	kubeBentoDeployment.Spec.Annotations = oldKubeBentoDeployment.Spec.Annotations
+	kubeBentoDeployment.Spec.Labels = oldKubeBentoDeployment.Spec.Labels
+	kubeBentoDeployment.Spec.ExtraPodMetadata = oldKubeBentoDeployment.Spec.ExtraPodMetadata
+	kubeBentoDeployment.Spec.ExtraPodSpec = oldKubeBentoDeployment.Spec.ExtraPodSpec
+	kubeBentoDeployment.Spec.Ingress.Annotations = oldKubeBentoDeployment.Spec.Ingress.Annotations
+	kubeBentoDeployment.Spec.Ingress.Labels = oldKubeBentoDeployment.Spec.Ingress.Labels
+	kubeBentoDeployment.Spec.Ingress.TLS = oldKubeBentoDeployment.Spec.Ingress.TLS
+	kubeBentoDeployment.Spec.Autoscaling = oldKubeBentoDeployment.Spec.Autoscaling
+	for idx, runner := range kubeBentoDeployment.Spec.Runners {
+		for _, oldRunner := range oldKubeBentoDeployment.Spec.Runners {
+			if runner.Name == oldRunner.Name {
+				kubeBentoDeployment.Spec.Runners[idx].Annotations = oldRunner.Annotations
+				kubeBentoDeployment.Spec.Runners[idx].Labels = oldRunner.Labels
+				kubeBentoDeployment.Spec.Runners[idx].ExtraPodMetadata = oldRunner.ExtraPodMetadata
+				kubeBentoDeployment.Spec.Runners[idx].ExtraPodSpec = oldRunner.ExtraPodSpec
+				kubeBentoDeployment.Spec.Runners[idx].Autoscaling = oldRunner.Autoscaling
+			}
+		}
+	}
+	return
+}
+
 func (s *kubeBentoDeploymentService) DeployV1alpha2(ctx context.Context, deploymentTarget *models.DeploymentTarget, deployOption *models.DeployOption) (kubeBentoDeployment *servingv1alpha2.BentoDeployment, err error) {
 	deployment, err := DeploymentService.GetAssociatedDeployment(ctx, deploymentTarget)
 	if err != nil {
@@ -140,48 +296,24 @@ func (s *kubeBentoDeploymentService) DeployV1alpha2(ctx context.Context, deploym
 		}()
 	}()
 
-	kubeBentoDeployment, err = s.transformToBentoDeploymentV1alpha2(ctx, deploymentTarget)
+	kubeBentoDeployment, oldKubeBentoDeployment, err := s.prepareV1alpha2(ctx, cli, deploymentTarget)
 	if err != nil {
-		err = errors.Wrap(err, "failed to transform to kube bento deployment")
 		return
 	}
-
-	var oldKubeBentoDeployment *servingv1alpha2.BentoDeployment
-	oldKubeBentoDeployment, err = cli.Get(ctx, kubeBentoDeployment.Name, metav1.GetOptions{})
-	isNotFound := apierrors.IsNotFound(err)
-	if err != nil && !isNotFound {
-		err = errors.Wrap(err, "failed to get kube bento deployment")
-		return
+	if deploymentTarget.Type == modelschemas.DeploymentTargetTypeCanary {
+		// the canary is garbage collected together with its revision
+		kubeBentoDeployment.OwnerReferences = deployOption.OwnerReferences
 	}
-	if isNotFound {
+	if oldKubeBentoDeployment == nil {
 		kubeBentoDeployment, err = cli.Create(ctx, kubeBentoDeployment, metav1.CreateOptions{})
 		if err != nil {
-			err = errors.Wrapf(err, "failed to create kube bento deployment %s", kubeBentoDeployment.Name)
+			err = errors.Wrapf(err, "failed to create kube bento deployment %s", deployment.Name)
 			return
 		}
 	} else {
-		kubeBentoDeployment.SetResourceVersion(oldKubeBentoDeployment.GetResourceVersion())
-		for k, v := range oldKubeBentoDeployment.Annotations {
-			if _, ok := kubeBentoDeployment.Annotations[k]; !ok {
-				kubeBentoDeployment.Annotations[k] = v
-			}
-		}
-		for k, v := range oldKubeBentoDeployment.Labels {
-			if _, ok := kubeBentoDeployment.Labels[k]; !ok {
-				kubeBentoDeployment.Labels[k] = v
-			}
-		}
-		kubeBentoDeployment.Spec.Autoscaling = oldKubeBentoDeployment.Spec.Autoscaling
-		for idx, runner := range kubeBentoDeployment.Spec.Runners {
-			for _, oldRunner := range oldKubeBentoDeployment.Spec.Runners {
-				if runner.Name == oldRunner.Name {
-					kubeBentoDeployment.Spec.Runners[idx].Autoscaling = oldRunner.Autoscaling
-				}
-			}
-		}
 		kubeBentoDeployment, err = cli.Update(ctx, kubeBentoDeployment, metav1.UpdateOptions{})
 		if err != nil {
-			err = errors.Wrapf(err, "failed to update kube bento deployment %s", kubeBentoDeployment.Name)
+			err = errors.Wrapf(err, "failed to update kube bento deployment %s", deployment.Name)
 			return
 		}
 	}
@@ -231,67 +363,123 @@ func (s *kubeBentoDeploymentService) DeployV1alpha3(ctx context.Context, deploym
 		}()
 	}()
 
-	kubeBentoDeploymentV1alpha2, err := s.transformToBentoDeploymentV1alpha2(ctx, deploymentTarget)
+	kubeBentoDeployment, oldKubeBentoDeployment, err := s.prepareV1alpha3(ctx, cli, deploymentTarget)
 	if err != nil {
-		err = errors.Wrap(err, "failed to transform to kube bento deployment")
 		return
 	}
+	if deploymentTarget.Type == modelschemas.DeploymentTargetTypeCanary {
+		// the canary is garbage collected together with its revision
+		kubeBentoDeployment.OwnerReferences = deployOption.OwnerReferences
+	}
+	if oldKubeBentoDeployment == nil {
+		kubeBentoDeployment, err = cli.Create(ctx, kubeBentoDeployment, metav1.CreateOptions{})
+		if err != nil {
+			err = errors.Wrapf(err, "failed to create kube bento deployment %s", deployment.Name)
+			return
+		}
+	} else {
+		kubeBentoDeployment, err = cli.Update(ctx, kubeBentoDeployment, metav1.UpdateOptions{})
+		if err != nil {
+			err = errors.Wrapf(err, "failed to update kube bento deployment %s", deployment.Name)
+			return
+		}
+	}
+	return
+}
 
-	err = kubeBentoDeploymentV1alpha2.ConvertTo(kubeBentoDeployment)
-	if err != nil {
-		err = errors.Wrap(err, "failed to convert kube bento deployment v1alpha2 to v1alpha3")
-		return
+type KubeBentoDeploymentDryRunResult struct {
+	DeploymentTarget *models.DeploymentTarget
+	Rendered         interface{}
+	ValidationErrors []string
+	Diff             []utils.DiffItem
+}
+
+func getDryRunValidationErrors(err error) []string {
+	var statusErr *apierrors.StatusError
+	if !errors.As(err, &statusErr) {
+		return []string{err.Error()}
 	}
+	status := statusErr.Status()
+	if status.Details == nil || len(status.Details.Causes) == 0 {
+		return []string{status.Message}
+	}
+	res := make([]string, 0, len(status.Details.Causes))
+	for _, cause := range status.Details.Causes {
+		if cause.Field != "" {
+			res = append(res, fmt.Sprintf("%s: %s", cause.Field, cause.Message))
+		} else {
+			res = append(res, cause.Message)
+		}
+	}
+	return res
+}
 
-	var oldKubeBentoDeployment *servingv1alpha3.BentoDeployment
-	oldKubeBentoDeployment, err = cli.Get(ctx, kubeBentoDeployment.Name, metav1.GetOptions{})
-	isNotFound := apierrors.IsNotFound(err)
-	if err != nil && !isNotFound {
-		err = errors.Wrap(err, "failed to get kube bento deployment")
-		return
+// DryRun renders the BentoDeployment of the deployment target and submits it with server-side dry-run,
+// the rejections of the api server are returned as validation errors instead of an error.
+func (s *kubeBentoDeploymentService) DryRun(ctx context.Context, deploymentTarget *models.DeploymentTarget) (*KubeBentoDeploymentDryRunResult, error) {
+	deployment, err := DeploymentService.GetAssociatedDeployment(ctx, deploymentTarget)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get associated deployment")
 	}
-	if isNotFound {
-		kubeBentoDeployment, err = cli.Create(ctx, kubeBentoDeployment, metav1.CreateOptions{})
+	useV1alpha3, err := s.UseV1alpha3(ctx, deployment)
+	if err != nil {
+		return nil, err
+	}
+	res := &KubeBentoDeploymentDryRunResult{
+		DeploymentTarget: deploymentTarget,
+		ValidationErrors: make([]string, 0),
+	}
+	var oldSpec, newSpec interface{}
+	if useV1alpha3 {
+		cli, err := DeploymentService.GetKubeBentoDeploymentV1alpha3Cli(ctx, deployment)
 		if err != nil {
-			err = errors.Wrapf(err, "failed to create kube bento deployment %s", kubeBentoDeployment.Name)
-			return
+			return nil, errors.Wrap(err, "failed to get kube bento deployment cli")
+		}
+		kubeBentoDeployment, oldKubeBentoDeployment, err := s.prepareV1alpha3(ctx, cli, deploymentTarget)
+		if err != nil {
+			return nil, err
 		}
+		var dryRunKubeBentoDeployment *servingv1alpha3.BentoDeployment
+		if oldKubeBentoDeployment == nil {
+			dryRunKubeBentoDeployment, err = cli.Create(ctx, kubeBentoDeployment, metav1.CreateOptions{DryRun: []string{metav1.DryRunAll}})
+		} else {
+			oldSpec = oldKubeBentoDeployment.Spec
+			dryRunKubeBentoDeployment, err = cli.Update(ctx, kubeBentoDeployment, metav1.UpdateOptions{DryRun: []string{metav1.DryRunAll}})
+		}
+		if err != nil {
+			res.ValidationErrors = getDryRunValidationErrors(err)
+		} else {
+			kubeBentoDeployment = dryRunKubeBentoDeployment
+		}
+		res.Rendered = kubeBentoDeployment
+		newSpec = kubeBentoDeployment.Spec
 	} else {
-		kubeBentoDeployment.SetResourceVersion(oldKubeBentoDeployment.GetResourceVersion())
-		for k, v := range oldKubeBentoDeployment.Annotations {
-			if _, ok := kubeBentoDeployment.Annotations[k]; !ok {
-				kubeBentoDeployment.Annotations[k] = v
-			}
+		cli, err := DeploymentService.GetKubeBentoDeploymentV1alpha2Cli(ctx, deployment)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get kube bento deployment cli")
 		}
-		for k, v := range oldKubeBentoDeployment.Labels {
-			if _, ok := kubeBentoDeployment.Labels[k]; !ok {
-				kubeBentoDeployment.Labels[k] = v
-			}
+		kubeBentoDeployment, oldKubeBentoDeployment, err := s.prepareV1alpha2(ctx, cli, deploymentTarget)
+		if err != nil {
+			return nil, err
 		}
-		kubeBentoDeployment.Spec.Annotations = oldKubeBentoDeployment.Spec.Annotations
-		kubeBentoDeployment.Spec.Labels = oldKubeBentoDeployment.Spec.Labels
-		kubeBentoDeployment.Spec.ExtraPodMetadata = oldKubeBentoDeployment.Spec.ExtraPodMetadata
-		kubeBentoDeployment.Spec.ExtraPodSpec = oldKubeBentoDeployment.Spec.ExtraPodSpec
-		kubeBentoDeployment.Spec.Ingress.Annotations = oldKubeBentoDeployment.Spec.Ingress.Annotations
-		kubeBentoDeployment.Spec.Ingress.Labels = oldKubeBentoDeployment.Spec.Ingress.Labels
-		kubeBentoDeployment.Spec.Ingress.TLS = oldKubeBentoDeployment.Spec.Ingress.TLS
-		kubeBentoDeployment.Spec.Autoscaling = oldKubeBentoDeployment.Spec.Autoscaling
-		for idx, runner := range kubeBentoDeployment.Spec.Runners {
-			for _, oldRunner := range oldKubeBentoDeployment.Spec.Runners {
-				if runner.Name == oldRunner.Name {
-					kubeBentoDeployment.Spec.Runners[idx].Annotations = oldRunner.Annotations
-					kubeBentoDeployment.Spec.Runners[idx].Labels = oldRunner.Labels
-					kubeBentoDeployment.Spec.Runners[idx].ExtraPodMetadata = oldRunner.ExtraPodMetadata
-					kubeBentoDeployment.Spec.Runners[idx].ExtraPodSpec = oldRunner.ExtraPodSpec
-					kubeBentoDeployment.Spec.Runners[idx].Autoscaling = oldRunner.Autoscaling
-				}
-			}
+		var dryRunKubeBentoDeployment *servingv1alpha2.BentoDeployment
+		if oldKubeBentoDeployment == nil {
+			dryRunKubeBentoDeployment, err = cli.Create(ctx, kubeBentoDeployment, metav1.CreateOptions{DryRun: []string{metav1.DryRunAll}})
+		} else {
+			oldSpec = oldKubeBentoDeployment.Spec
+			dryRunKubeBentoDeployment, err = cli.Update(ctx, kubeBentoDeployment, metav1.UpdateOptions{DryRun: []string{metav1.DryRunAll}})
 		}
-		kubeBentoDeployment, err = cli.Update(ctx, kubeBentoDeployment, metav1.UpdateOptions{})
 		if err != nil {
-			err = errors.Wrapf(err, "failed to update kube bento deployment %s", kubeBentoDeployment.Name)
-			return
+			res.ValidationErrors = getDryRunValidationErrors(err)
+		} else {
+			kubeBentoDeployment = dryRunKubeBentoDeployment
 		}
+		res.Rendered = kubeBentoDeployment
+		newSpec = kubeBentoDeployment.Spec
 	}
-	return
+	res.Diff, err = utils.Diff(oldSpec, newSpec)
+	if err != nil {
+		return nil, errors.Wrap(err, "diff kube bento deployment")
+	}
+	return res, nil
 }
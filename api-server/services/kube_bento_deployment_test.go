@@ -0,0 +1,26 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/bentoml/yatai-schemas/modelschemas"
+)
+
+// the deploy, the terminate and the dry run share this selector, so the dry run renders the CR the deploy writes
+func TestIsV1alpha3Manifest(t *testing.T) {
+	cases := []struct {
+		name     string
+		manifest *modelschemas.YataiComponentManifestSchema
+		expected bool
+	}{
+		{"no manifest", nil, false},
+		{"no crd version", &modelschemas.YataiComponentManifestSchema{}, false},
+		{"v1alpha2", &modelschemas.YataiComponentManifestSchema{LatestCRDVersion: "v1alpha2"}, false},
+		{"v1alpha3", &modelschemas.YataiComponentManifestSchema{LatestCRDVersion: "v1alpha3"}, true},
+	}
+	for _, c := range cases {
+		if got := isV1alpha3Manifest(c.manifest); got != c.expected {
+			t.Errorf("%s: expected %v, got %v", c.name, c.expected, got)
+		}
+	}
+}
@@ -6,7 +6,12 @@ import (
 	"time"
 
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/viney-shih/go-lock"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
 	"k8s.io/client-go/informers"
 	informerAppsV1 "k8s.io/client-go/informers/apps/v1"
 	informerCoreV1 "k8s.io/client-go/informers/core/v1"
@@ -17,6 +22,7 @@ import (
 	"k8s.io/client-go/tools/cache"
 
 	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/common/metrics"
 )
 
 type CacheKey string
@@ -26,6 +32,17 @@ var (
 
 	informerFactoryCache   = make(map[CacheKey]informers.SharedInformerFactory)
 	informerFactoryCacheRW = lock.NewCASMutex()
+
+	informerFactoryCacheSize = promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "kube",
+		Name:      "informer_factory_cache_size",
+		Help:      "Number of the cached kubernetes shared informer factories.",
+	}, func() float64 {
+		informerFactoryCacheRW.RLock()
+		defer informerFactoryCacheRW.RUnlock()
+		return float64(len(informerFactoryCache))
+	})
 )
 
 type getSharedInformerFactoryOption struct {
@@ -242,3 +259,26 @@ func GetNodeInformer(ctx context.Context, kubeCluster *models.Cluster) (informer
 	}
 	return nodeInformer, nodeInformer.Lister(), nil
 }
+
+// GetBentoDeploymentInformer watches the BentoDeployment CRs of the given version, they are not served by the typed clientset.
+func GetBentoDeploymentInformer(ctx context.Context, kubeCluster *models.Cluster, namespace string, version string) (cache.SharedIndexInformer, error) {
+	_, restConfig, err := ClusterService.GetKubeCliSet(ctx, kubeCluster)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get kubernetes client set")
+	}
+	dynamicCli, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create kubernetes dynamic client")
+	}
+	factory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(dynamicCli, 0, namespace, nil)
+	bentoDeploymentInformer := factory.ForResource(schema.GroupVersionResource{
+		Group:    "serving.yatai.ai",
+		Version:  version,
+		Resource: "bentodeployments",
+	}).Informer()
+	err = startAndSyncInformer(ctx, bentoDeploymentInformer)
+	if err != nil {
+		return nil, err
+	}
+	return bentoDeploymentInformer, nil
+}
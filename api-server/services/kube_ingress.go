@@ -69,6 +69,10 @@ more_set_headers "X-Yatai-Bento: %s";
 			}
 		}
 	}
+	if deploymentTarget.Type == modelschemas.DeploymentTargetTypeCanary && deployOption.CanaryWeight != nil {
+		annotations["nginx.ingress.kubernetes.io/canary"] = "true"
+		annotations["nginx.ingress.kubernetes.io/canary-weight"] = strconv.Itoa(int(*deployOption.CanaryWeight))
+	}
 
 	annotations["nginx.ingress.kubernetes.io/ssl-redirect"] = "false"
 
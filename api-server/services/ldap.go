@@ -0,0 +1,219 @@
+package services
+
+import (
+	"context"
+	"crypto/tls"
+	"net/url"
+	"strings"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/bentoml/yatai-schemas/modelschemas"
+	"github.com/bentoml/yatai/api-server/config"
+	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/common/utils"
+)
+
+// ldapConn is the part of *ldap.Conn used by the authenticator, the tests replace it with a stand-in directory
+type ldapConn interface {
+	Bind(username, password string) error
+	Search(searchRequest *ldap.SearchRequest) (*ldap.SearchResult, error)
+	Close()
+}
+
+type ldapService struct {
+	dial func(cfg *config.YataiLDAPConfigYaml) (ldapConn, error)
+}
+
+var LDAPService = ldapService{
+	dial: dialLDAP,
+}
+
+// LDAPEntry is the yatai user read from the directory
+type LDAPEntry struct {
+	DN        string
+	Username  string
+	Email     string
+	FirstName string
+	LastName  string
+	Groups    []string
+}
+
+func dialLDAP(cfg *config.YataiLDAPConfigYaml) (ldapConn, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parse ldap url %s", cfg.URL)
+	}
+	tlsConfig := &tls.Config{
+		ServerName:         u.Hostname(),
+		InsecureSkipVerify: cfg.InsecureSkipVerify, // nolint:gosec
+	}
+	conn, err := ldap.DialURL(cfg.URL, ldap.DialWithTLSConfig(tlsConfig))
+	if err != nil {
+		return nil, errors.Wrapf(err, "dial ldap %s", cfg.URL)
+	}
+	if cfg.StartTLS {
+		err = conn.StartTLS(tlsConfig)
+		if err != nil {
+			conn.Close()
+			return nil, errors.Wrap(err, "ldap start tls")
+		}
+	}
+	return conn, nil
+}
+
+func (s *ldapService) Name() string {
+	return "ldap"
+}
+
+func (s *ldapService) Authenticate(ctx context.Context, nameOrEmail, password string) (*models.User, error) {
+	entry, err := s.Verify(nameOrEmail, password)
+	if err != nil {
+		return nil, err
+	}
+	return s.Login(ctx, entry)
+}
+
+func (s *ldapService) bindServiceAccount(conn ldapConn, cfg *config.YataiLDAPConfigYaml) error {
+	if cfg.BindDN == "" {
+		return nil
+	}
+	err := conn.Bind(cfg.BindDN, cfg.BindPassword)
+	return errors.Wrap(err, "ldap bind service account")
+}
+
+func replaceFilterPlaceholder(filter, value string) string {
+	return strings.ReplaceAll(filter, "%s", ldap.EscapeFilter(value))
+}
+
+// Verify binds as the user and returns the directory entry with the groups of the user
+func (s *ldapService) Verify(nameOrEmail, password string) (*LDAPEntry, error) {
+	// an empty password is an unauthenticated bind, which most servers accept
+	if nameOrEmail == "" || password == "" {
+		return nil, ErrInvalidCredentials
+	}
+	cfg := config.YataiConfig.LDAP
+	conn, err := s.dial(cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	err = s.bindServiceAccount(conn, cfg)
+	if err != nil {
+		return nil, err
+	}
+	result, err := conn.Search(ldap.NewSearchRequest(
+		cfg.BaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 2, 0, false,
+		replaceFilterPlaceholder(cfg.UserFilter, nameOrEmail),
+		[]string{cfg.UsernameAttribute, cfg.EmailAttribute, cfg.FirstNameAttribute, cfg.LastNameAttribute},
+		nil,
+	))
+	if err != nil {
+		return nil, errors.Wrap(err, "ldap search user")
+	}
+	if len(result.Entries) != 1 {
+		return nil, ErrInvalidCredentials
+	}
+	userEntry := result.Entries[0]
+
+	err = conn.Bind(userEntry.DN, password)
+	if err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, errors.Wrap(err, "ldap bind user")
+	}
+
+	entry := &LDAPEntry{
+		DN:        userEntry.DN,
+		Username:  userEntry.GetAttributeValue(cfg.UsernameAttribute),
+		Email:     userEntry.GetAttributeValue(cfg.EmailAttribute),
+		FirstName: userEntry.GetAttributeValue(cfg.FirstNameAttribute),
+		LastName:  userEntry.GetAttributeValue(cfg.LastNameAttribute),
+	}
+	if entry.Username == "" {
+		return nil, errors.Errorf("the attribute %s of the ldap entry %s is empty", cfg.UsernameAttribute, userEntry.DN)
+	}
+	if cfg.GroupBaseDN == "" {
+		return entry, nil
+	}
+
+	// the groups are searched as the service account again, the users often cannot read them
+	err = s.bindServiceAccount(conn, cfg)
+	if err != nil {
+		return nil, err
+	}
+	result, err = conn.Search(ldap.NewSearchRequest(
+		cfg.GroupBaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false,
+		replaceFilterPlaceholder(cfg.GroupFilter, userEntry.DN),
+		[]string{cfg.GroupNameAttribute},
+		nil,
+	))
+	if err != nil {
+		return nil, errors.Wrap(err, "ldap search groups")
+	}
+	for _, groupEntry := range result.Entries {
+		if name := groupEntry.GetAttributeValue(cfg.GroupNameAttribute); name != "" {
+			entry.Groups = append(entry.Groups, name)
+		}
+	}
+	return entry, nil
+}
+
+// MapGroupRoles returns the role of the user in every organization of the ldap group mappings
+func (s *ldapService) MapGroupRoles(groups []string) map[string]modelschemas.MemberRole {
+	return mapGroupRoles(config.YataiConfig.LDAP.GroupMappings, groups)
+}
+
+// Login returns the user of the directory entry, the user is created on the first login,
+// then the memberships of the mapped organizations are synced with the ldap groups.
+func (s *ldapService) Login(ctx context.Context, entry *LDAPEntry) (*models.User, error) {
+	user, err := UserService.GetByLdapDn(ctx, entry.DN)
+	if err != nil && !utils.IsNotFound(err) {
+		return nil, errors.Wrap(err, "get user by ldap dn")
+	}
+	if utils.IsNotFound(err) {
+		user, err = s.linkOrCreateUser(ctx, entry)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = syncGroupMemberships(ctx, user, config.YataiConfig.LDAP.GroupMappings, entry.Groups)
+	if err != nil {
+		return nil, errors.Wrap(err, "sync organization memberships")
+	}
+	return user, nil
+}
+
+func (s *ldapService) linkOrCreateUser(ctx context.Context, entry *LDAPEntry) (*models.User, error) {
+	user, err := UserService.GetByName(ctx, entry.Username)
+	if err != nil && !utils.IsNotFound(err) {
+		return nil, errors.Wrap(err, "get user by name")
+	}
+	if err == nil {
+		// otherwise anyone who can pick the username in the directory could take over a local user
+		if !config.YataiConfig.LDAP.LinkExistingUsers || user.LdapDn != nil {
+			return nil, errors.Errorf("the user %s already exists and is not linked to this ldap entry", entry.Username)
+		}
+		ldapDn := &entry.DN
+		return UserService.Update(ctx, user, UpdateUserOption{
+			LdapDn: &ldapDn,
+		})
+	}
+	user, err = UserService.Create(ctx, CreateUserOption{
+		Name:      entry.Username,
+		FirstName: entry.FirstName,
+		LastName:  entry.LastName,
+		Email:     utils.StringPtrWithoutEmpty(entry.Email),
+		Perm:      modelschemas.UserPermPtr(modelschemas.UserPermDefault),
+		LdapDn:    &entry.DN,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "create user")
+	}
+	logrus.Infof("created user %s by ldap login", user.Name)
+	return user, nil
+}
@@ -0,0 +1,206 @@
+package services
+
+import (
+	"encoding/hex"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/go-ldap/ldap/v3"
+
+	"github.com/bentoml/yatai-schemas/modelschemas"
+	"github.com/bentoml/yatai/api-server/config"
+)
+
+var equalityFilterRegexp = regexp.MustCompile(`^\(([A-Za-z]+)=(.*)\)$`)
+
+// fakeDirectory is an in-memory stand-in of an openldap server,
+// it only knows the simple binds and the searches with an equality filter.
+type fakeDirectory struct {
+	entries   []*ldap.Entry
+	passwords map[string]string
+	bound     string
+	binds     []string
+}
+
+func (d *fakeDirectory) addEntry(dn, password string, attributes map[string][]string) {
+	d.entries = append(d.entries, ldap.NewEntry(dn, attributes))
+	if password != "" {
+		d.passwords[dn] = password
+	}
+}
+
+func (d *fakeDirectory) Bind(username, password string) error {
+	d.binds = append(d.binds, username)
+	if expected, ok := d.passwords[username]; !ok || password == "" || expected != password {
+		return ldap.NewError(ldap.LDAPResultInvalidCredentials, errors.New("invalid credentials"))
+	}
+	d.bound = username
+	return nil
+}
+
+func (d *fakeDirectory) Search(searchRequest *ldap.SearchRequest) (*ldap.SearchResult, error) {
+	if d.bound == "" {
+		return nil, ldap.NewError(ldap.LDAPResultInsufficientAccessRights, errors.New("anonymous search is not allowed"))
+	}
+	matches := equalityFilterRegexp.FindStringSubmatch(searchRequest.Filter)
+	if matches == nil {
+		return nil, ldap.NewError(ldap.LDAPResultFilterError, errors.New("unsupported filter "+searchRequest.Filter))
+	}
+	value, err := unescapeFilterValue(matches[2])
+	if err != nil {
+		return nil, err
+	}
+	result := &ldap.SearchResult{}
+	for _, entry := range d.entries {
+		if !strings.HasSuffix(entry.DN, ","+searchRequest.BaseDN) {
+			continue
+		}
+		for _, v := range entry.GetAttributeValues(matches[1]) {
+			if v == value {
+				result.Entries = append(result.Entries, entry)
+				break
+			}
+		}
+	}
+	if searchRequest.SizeLimit > 0 && len(result.Entries) > searchRequest.SizeLimit {
+		return nil, ldap.NewError(ldap.LDAPResultSizeLimitExceeded, errors.New("size limit exceeded"))
+	}
+	return result, nil
+}
+
+func (d *fakeDirectory) Close() {}
+
+func unescapeFilterValue(value string) (string, error) {
+	var sb strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' {
+			sb.WriteByte(value[i])
+			continue
+		}
+		if i+2 >= len(value) {
+			return "", errors.New("invalid escaped filter value " + value)
+		}
+		b, err := hex.DecodeString(value[i+1 : i+3])
+		if err != nil {
+			return "", err
+		}
+		sb.Write(b)
+		i += 2
+	}
+	return sb.String(), nil
+}
+
+func setupLDAPTest(t *testing.T) *fakeDirectory {
+	directory := &fakeDirectory{passwords: make(map[string]string)}
+	directory.addEntry("cn=yatai,ou=services,dc=example,dc=com", "service-password", nil)
+	directory.addEntry("uid=alice,ou=people,dc=example,dc=com", "alice-password", map[string][]string{
+		"uid":       {"alice"},
+		"mail":      {"alice@example.com"},
+		"givenName": {"Alice"},
+		"sn":        {"Liddell"},
+	})
+	directory.addEntry("uid=bob,ou=people,dc=example,dc=com", "bob-password", map[string][]string{
+		"uid": {"bob"},
+	})
+	directory.addEntry("cn=ml,ou=groups,dc=example,dc=com", "", map[string][]string{
+		"cn":     {"ml"},
+		"member": {"uid=alice,ou=people,dc=example,dc=com", "uid=bob,ou=people,dc=example,dc=com"},
+	})
+	directory.addEntry("cn=ml-admins,ou=groups,dc=example,dc=com", "", map[string][]string{
+		"cn":     {"ml-admins"},
+		"member": {"uid=alice,ou=people,dc=example,dc=com"},
+	})
+
+	oldLDAP := config.YataiConfig.LDAP
+	oldDial := LDAPService.dial
+	config.YataiConfig.LDAP = &config.YataiLDAPConfigYaml{
+		URL:                "ldap://ldap.example.com",
+		BindDN:             "cn=yatai,ou=services,dc=example,dc=com",
+		BindPassword:       "service-password",
+		BaseDN:             "ou=people,dc=example,dc=com",
+		UserFilter:         "(uid=%s)",
+		UsernameAttribute:  "uid",
+		EmailAttribute:     "mail",
+		FirstNameAttribute: "givenName",
+		LastNameAttribute:  "sn",
+		GroupBaseDN:        "ou=groups,dc=example,dc=com",
+		GroupFilter:        "(member=%s)",
+		GroupNameAttribute: "cn",
+		GroupMappings: []config.YataiGroupMappingYaml{
+			{Group: "ml", Organization: "default", Role: modelschemas.MemberRoleDeveloper},
+			{Group: "ml-admins", Organization: "default", Role: modelschemas.MemberRoleAdmin},
+		},
+	}
+	LDAPService.dial = func(cfg *config.YataiLDAPConfigYaml) (ldapConn, error) {
+		directory.bound = ""
+		return directory, nil
+	}
+	t.Cleanup(func() {
+		config.YataiConfig.LDAP = oldLDAP
+		LDAPService.dial = oldDial
+	})
+	return directory
+}
+
+func TestLDAPVerify(t *testing.T) {
+	directory := setupLDAPTest(t)
+
+	entry, err := LDAPService.Verify("alice", "alice-password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.DN != "uid=alice,ou=people,dc=example,dc=com" || entry.Username != "alice" || entry.Email != "alice@example.com" || entry.FirstName != "Alice" || entry.LastName != "Liddell" {
+		t.Fatalf("unexpected entry %+v", entry)
+	}
+	if strings.Join(entry.Groups, ",") != "ml,ml-admins" {
+		t.Fatalf("unexpected groups %v", entry.Groups)
+	}
+	// the groups are searched after binding the service account again
+	if directory.bound != config.YataiConfig.LDAP.BindDN {
+		t.Fatalf("the service account should be bound at last, got %s", directory.bound)
+	}
+
+	roles := LDAPService.MapGroupRoles(entry.Groups)
+	if roles["default"] != modelschemas.MemberRoleAdmin {
+		t.Fatalf("unexpected roles %v", roles)
+	}
+}
+
+func TestLDAPVerifyRejectsInvalidCredentials(t *testing.T) {
+	directory := setupLDAPTest(t)
+
+	cases := []struct {
+		name     string
+		username string
+		password string
+	}{
+		{"wrong password", "alice", "bob-password"},
+		{"empty password", "alice", ""},
+		{"unknown user", "carol", "carol-password"},
+		{"filter injection", "*", "alice-password"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			directory.binds = nil
+			_, err := LDAPService.Verify(c.username, c.password)
+			if !errors.Is(err, ErrInvalidCredentials) {
+				t.Fatalf("expected the invalid credentials error, got %v", err)
+			}
+			if c.password == "" && len(directory.binds) != 0 {
+				t.Fatal("the empty password should be rejected before binding")
+			}
+		})
+	}
+}
+
+func TestLDAPVerifyServiceAccountFailure(t *testing.T) {
+	directory := setupLDAPTest(t)
+	directory.passwords[config.YataiConfig.LDAP.BindDN] = "rotated-password"
+
+	_, err := LDAPService.Verify("alice", "alice-password")
+	if err == nil || errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("a misconfigured service account should not look like invalid user credentials, got %v", err)
+	}
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"go.uber.org/atomic"
+)
+
+const (
+	// leaderElectionLockKey is the postgres advisory lock held by the leader replica
+	leaderElectionLockKey       int64 = 0x7961746169
+	leaderElectionRetryInterval       = 5 * time.Second
+)
+
+type leaderElectionService struct {
+	isLeader atomic.Bool
+}
+
+// LeaderElectionService makes sure the singleton background jobs run on exactly one
+// api server replica. The leader holds a session level postgres advisory lock on a
+// dedicated connection, the lock is released by postgres as soon as the connection dies.
+var LeaderElectionService = leaderElectionService{}
+
+func (s *leaderElectionService) IsLeader() bool {
+	return s.isLeader.Load()
+}
+
+// Run campaigns for the leadership until ctx is done. onStartedLeading is called every time
+// this replica becomes the leader, its ctx is cancelled once the leadership is lost.
+// The returned channel is closed once the campaign stopped and the background jobs returned.
+func (s *leaderElectionService) Run(ctx context.Context, onStartedLeading func(ctx context.Context)) <-chan struct{} {
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		for {
+			err := s.lead(ctx, onStartedLeading)
+			if err != nil {
+				logrus.Errorf("leader election: %s", err.Error())
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(leaderElectionRetryInterval):
+			}
+		}
+	}()
+	return stopped
+}
+
+func (s *leaderElectionService) lead(ctx context.Context, onStartedLeading func(ctx context.Context)) error {
+	db, err := getDB()
+	if err != nil {
+		return errors.Wrap(err, "get db")
+	}
+	rawDb, err := db.DB()
+	if err != nil {
+		return errors.Wrap(err, "get raw db")
+	}
+	conn, err := rawDb.Conn(ctx)
+	if err != nil {
+		return errors.Wrap(err, "get db connection")
+	}
+	defer conn.Close()
+
+	var acquired bool
+	err = conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", leaderElectionLockKey).Scan(&acquired)
+	if err != nil {
+		return errors.Wrap(err, "try advisory lock")
+	}
+	if !acquired {
+		return nil
+	}
+
+	logrus.Info("became the leader, starting background jobs")
+	leaderCtx, cancel := context.WithCancel(ctx)
+	s.isLeader.Store(true)
+	jobsDone := make(chan struct{})
+	go func() {
+		defer close(jobsDone)
+		onStartedLeading(leaderCtx)
+	}()
+
+	err = s.keepLeading(ctx, conn)
+	// the background jobs must be finished before another replica can take over
+	cancel()
+	<-jobsDone
+	s.isLeader.Store(false)
+	if ctx.Err() != nil {
+		unlockCtx, unlockCancel := context.WithTimeout(context.Background(), leaderElectionRetryInterval)
+		defer unlockCancel()
+		_, err = conn.ExecContext(unlockCtx, "SELECT pg_advisory_unlock($1)", leaderElectionLockKey)
+		if err != nil {
+			err = errors.Wrap(err, "advisory unlock")
+		}
+	}
+	return err
+}
+
+func (s *leaderElectionService) keepLeading(ctx context.Context, conn *sql.Conn) error {
+	ticker := time.NewTicker(leaderElectionRetryInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := conn.PingContext(ctx); err != nil {
+				return errors.Wrap(err, "lost the leadership")
+			}
+		}
+	}
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/bentoml/yatai-schemas/modelschemas"
 	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/common/utils"
 )
 
 type IMemberManager interface {
@@ -22,6 +23,47 @@ type memberService struct{}
 
 var MemberService = memberService{}
 
+// maxMemberRole returns the highest of the roles, the empty role when there is none
+func maxMemberRole(roles ...modelschemas.MemberRole) modelschemas.MemberRole {
+	var res modelschemas.MemberRole
+	for _, role := range roles {
+		if memberRoleRanks[role] > memberRoleRanks[res] {
+			res = role
+		}
+	}
+	return res
+}
+
+// getEffectiveRole resolves the effective role of the user on the resource, which is the highest
+// of the direct member role and the roles granted to the user groups of the user.
+func (s *memberService) getEffectiveRole(ctx context.Context, userId uint, resourceType modelschemas.ResourceType, resourceId uint, directRole modelschemas.MemberRole) (modelschemas.MemberRole, error) {
+	groupRoles, err := UserGroupGrantService.ListUserRoles(ctx, userId, resourceType, resourceId)
+	if err != nil {
+		return "", errors.Wrap(err, "list user group roles")
+	}
+	return maxMemberRole(append(groupRoles, directRole)...), nil
+}
+
+func (s *memberService) checkEffectiveRole(ctx context.Context, userId uint, resourceType modelschemas.ResourceType, resourceId uint, directRole modelschemas.MemberRole, roles []modelschemas.MemberRole) (bool, error) {
+	effectiveRole, err := s.getEffectiveRole(ctx, userId, resourceType, resourceId, directRole)
+	if err != nil {
+		return false, err
+	}
+	return containsMemberRole(roles, effectiveRole), nil
+}
+
+func containsMemberRole(roles []modelschemas.MemberRole, role modelschemas.MemberRole) bool {
+	if role == "" {
+		return false
+	}
+	for _, role_ := range roles {
+		if role_ == role {
+			return true
+		}
+	}
+	return false
+}
+
 func (s *memberService) checkApiToken(m IMemberManager, user *models.User, ops []modelschemas.ApiTokenScopeOp) error {
 	if user.ApiToken == nil {
 		return nil
@@ -30,6 +72,10 @@ func (s *memberService) checkApiToken(m IMemberManager, user *models.User, ops [
 		return nil
 	}
 	resourceType := m.GetResourceType()
+	// the repositories are accessed with the scopes of the organization which they belong to
+	if resourceType == modelschemas.ResourceTypeBentoRepository || resourceType == modelschemas.ResourceTypeModelRepository {
+		resourceType = modelschemas.ResourceTypeOrganization
+	}
 	scopeStrs := make([]string, 0, len(ops))
 	for _, op := range ops {
 		scopeStr := fmt.Sprintf("%s_%s", op, resourceType)
@@ -44,7 +90,99 @@ func (s *memberService) checkApiToken(m IMemberManager, user *models.User, ops [
 	return errors.Errorf("the api_token need the scopes: %s", strings.Join(scopeStrs, " or "))
 }
 
-func (s *memberService) CanView(ctx context.Context, m IMemberManager, user *models.User, resourceId uint) error {
+// checkApiTokenResources makes sure a resource-scoped api token only reaches the selected resources.
+// Without targets the organization, cluster or repository itself is accessed, which needs a selector
+// on it, so the scoped tokens cannot read the lists of the whole organization or cluster.
+func (s *memberService) checkApiTokenResources(ctx context.Context, user *models.User, resource models.IResource, targets []models.IResource) error {
+	if user.ApiToken == nil || user.ApiToken.ResourceSelectors == nil || len(*user.ApiToken.ResourceSelectors) == 0 {
+		return nil
+	}
+	if len(targets) == 0 {
+		targets = []models.IResource{resource}
+	}
+	for _, target := range targets {
+		matched := false
+		for _, selector := range *user.ApiToken.ResourceSelectors {
+			var err error
+			matched, err = s.matchApiTokenResourceSelector(ctx, selector, target)
+			if err != nil {
+				return err
+			}
+			if matched {
+				break
+			}
+		}
+		if !matched {
+			return jujuerrors.Unauthorizedf("the api token %s is not allowed to access this %s: %s", user.ApiToken.Name, target.GetResourceType(), target.GetName())
+		}
+	}
+	return nil
+}
+
+func (s *memberService) matchApiTokenResourceSelector(ctx context.Context, selector *models.ApiTokenResourceSelector, target models.IResource) (bool, error) {
+	if selector.ResourceType != target.GetResourceType() {
+		return false, nil
+	}
+	if len(selector.Names) > 0 {
+		nameMatched := false
+		for _, name := range selector.Names {
+			if name == target.GetName() {
+				nameMatched = true
+				break
+			}
+		}
+		if !nameMatched {
+			return false, nil
+		}
+	}
+	if selector.ClusterName != nil {
+		deployment, ok := target.(*models.Deployment)
+		if !ok {
+			return false, nil
+		}
+		cluster, err := ClusterService.GetAssociatedCluster(ctx, deployment)
+		if err != nil {
+			return false, errors.Wrap(err, "get associated cluster")
+		}
+		if cluster.Name != *selector.ClusterName {
+			return false, nil
+		}
+	}
+	if len(selector.Labels) > 0 {
+		resourceType := target.GetResourceType()
+		labels, _, err := LabelService.List(ctx, ListLabelOption{
+			ResourceType: &resourceType,
+			ResourceId:   utils.UintPtr(target.GetId()),
+		})
+		if err != nil {
+			return false, errors.Wrap(err, "list labels")
+		}
+		labelsMap := make(map[string]string, len(labels))
+		for _, label := range labels {
+			labelsMap[label.Key] = label.Value
+		}
+		for key, value := range selector.Labels {
+			if v, ok := labelsMap[key]; !ok || v != value {
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
+
+// CanView checks the member roles on the organization, cluster or repository, targets are the resources
+// inside of it which are accessed, resource-scoped api tokens must select them.
+func (s *memberService) CanView(ctx context.Context, m IMemberManager, user *models.User, resourceId uint, targets ...models.IResource) error {
+	return s.canView(ctx, m, user, resourceId, true, targets)
+}
+
+// CanVisit checks the member roles on the organization, cluster or repository like CanView, but nothing
+// inside of it is read, so the resource selectors of the api token are left to the later checks.
+func (s *memberService) CanVisit(ctx context.Context, m IMemberManager, user *models.User, resourceId uint) error {
+	return s.canView(ctx, m, user, resourceId, false, nil)
+}
+
+func (s *memberService) canView(ctx context.Context, m IMemberManager, user *models.User, resourceId uint, checkResources bool, targets []models.IResource) error {
 	if err := s.checkApiToken(m, user, []modelschemas.ApiTokenScopeOp{modelschemas.ApiTokenScopeOpRead, modelschemas.ApiTokenScopeOpWrite, modelschemas.ApiTokenScopeOpOperate}); err != nil {
 		return err
 	}
@@ -54,6 +192,11 @@ func (s *memberService) CanView(ctx context.Context, m IMemberManager, user *mod
 	if err != nil {
 		return errors.Wrap(err, "check can view")
 	}
+	if checkResources {
+		if err := s.checkApiTokenResources(ctx, user, resource, targets); err != nil {
+			return err
+		}
+	}
 	organization, err := m.GetOrganization(ctx, resourceId)
 	if err != nil {
 		return err
@@ -79,7 +222,9 @@ func (s *memberService) CanView(ctx context.Context, m IMemberManager, user *mod
 	return nil
 }
 
-func (s *memberService) CanUpdate(ctx context.Context, m IMemberManager, user *models.User, resourceId uint) error {
+// CanUpdate checks the member roles on the organization, cluster or repository, targets are the resources
+// inside of it which are accessed, resource-scoped api tokens must select them.
+func (s *memberService) CanUpdate(ctx context.Context, m IMemberManager, user *models.User, resourceId uint, targets ...models.IResource) error {
 	if err := s.checkApiToken(m, user, []modelschemas.ApiTokenScopeOp{modelschemas.ApiTokenScopeOpWrite, modelschemas.ApiTokenScopeOpOperate}); err != nil {
 		return err
 	}
@@ -89,6 +234,9 @@ func (s *memberService) CanUpdate(ctx context.Context, m IMemberManager, user *m
 	if err != nil {
 		return errors.Wrap(err, "check can update")
 	}
+	if err := s.checkApiTokenResources(ctx, user, resource, targets); err != nil {
+		return err
+	}
 	organization, err := m.GetOrganization(ctx, resourceId)
 	if err != nil {
 		return err
@@ -113,7 +261,9 @@ func (s *memberService) CanUpdate(ctx context.Context, m IMemberManager, user *m
 	return nil
 }
 
-func (s *memberService) CanOperate(ctx context.Context, m IMemberManager, user *models.User, resourceId uint) error {
+// CanOperate checks the member roles on the organization, cluster or repository, targets are the resources
+// inside of it which are accessed, resource-scoped api tokens must select them.
+func (s *memberService) CanOperate(ctx context.Context, m IMemberManager, user *models.User, resourceId uint, targets ...models.IResource) error {
 	if err := s.checkApiToken(m, user, []modelschemas.ApiTokenScopeOp{modelschemas.ApiTokenScopeOpOperate}); err != nil {
 		return err
 	}
@@ -123,6 +273,9 @@ func (s *memberService) CanOperate(ctx context.Context, m IMemberManager, user *
 	if err != nil {
 		return errors.Wrap(err, "check can operate")
 	}
+	if err := s.checkApiTokenResources(ctx, user, resource, targets); err != nil {
+		return err
+	}
 	organization, err := m.GetOrganization(ctx, resourceId)
 	if err != nil {
 		return err
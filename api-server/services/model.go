@@ -21,6 +21,8 @@ import (
 	"github.com/bentoml/yatai-schemas/modelschemas"
 	"github.com/bentoml/yatai/api-server/models"
 	"github.com/bentoml/yatai/common/consts"
+	"github.com/bentoml/yatai/common/metrics"
+	"github.com/bentoml/yatai/common/utils"
 )
 
 type modelService struct{}
@@ -65,6 +67,8 @@ type ListModelOption struct {
 	Order             *string
 	Names             *[]string
 	Modules           *[]string
+	// VisitorId filters out the models of the private repositories which the visitor cannot view
+	VisitorId *uint
 }
 
 func (s *modelService) Create(ctx context.Context, opt CreateModelOption) (model *models.Model, err error) {
@@ -147,7 +151,9 @@ func (s *modelService) StartMultipartUpload(ctx context.Context, model *models.M
 		return
 	}
 
+	start := time.Now()
 	uploadId, err = minioCore.NewMultipartUpload(ctx, bucketName, objectName, minio.PutObjectOptions{})
+	metrics.ObserveS3Operation("new_multipart_upload", start, err)
 	if err != nil {
 		err = errors.Wrap(err, "new multipart upload")
 		return
@@ -193,7 +199,9 @@ func (s *modelService) PreSignMultipartUploadUrl(ctx context.Context, model *mod
 	queryValues.Set("partNumber", strconv.Itoa(partNumber))
 	queryValues.Set("uploadId", uploadId)
 
+	start := time.Now()
 	url_, err = minioCore.Presign(ctx, http.MethodPut, bucketName, objectName, time.Hour, queryValues)
+	metrics.ObserveS3Operation("presign", start, err)
 	if err != nil {
 		err = errors.Wrap(err, "presigned put object")
 		return
@@ -238,7 +246,9 @@ func (s *modelService) CompleteMultipartUpload(ctx context.Context, model *model
 		return
 	}
 
+	start := time.Now()
 	_, err = minioCore.CompleteMultipartUpload(ctx, bucketName, objectName, uploadId, parts, minio.PutObjectOptions{})
+	metrics.ObserveS3Operation("complete_multipart_upload", start, err)
 	if err != nil {
 		err = errors.Wrap(err, "new multipart upload")
 		return
@@ -281,7 +291,9 @@ func (s *modelService) Upload(ctx context.Context, model *models.Model, reader i
 	}
 
 	logrus.Debugf("uploading to s3: %s/%s", bucketName, objectName)
+	start := time.Now()
 	_, err = minioClient.PutObject(ctx, bucketName, objectName, reader, objectSize, minio.PutObjectOptions{ContentType: "application/octet-stream"})
+	metrics.ObserveS3Operation("put_object", start, err)
 	if err != nil {
 		err = errors.Wrap(err, "put object")
 		return
@@ -325,7 +337,9 @@ func (s *modelService) PreSignUploadUrl(ctx context.Context, model *models.Model
 		return
 	}
 
+	start := time.Now()
 	url, err = minioClient.PresignedPutObject(ctx, bucketName, objectName, time.Hour)
+	metrics.ObserveS3Operation("presigned_put_object", start, err)
 	if err != nil {
 		err = errors.Wrap(err, "presigned put object")
 		return
@@ -370,7 +384,9 @@ func (s *modelService) Download(ctx context.Context, model *models.Model, writer
 		return
 	}
 
+	start := time.Now()
 	obj, err := minioClient.GetObject(ctx, bucketName, objectName, minio.GetObjectOptions{})
+	metrics.ObserveS3Operation("get_object", start, err)
 	if err != nil {
 		err = errors.Wrap(err, "get object")
 		return
@@ -418,7 +434,9 @@ func (s *modelService) PreSignDownloadUrl(ctx context.Context, model *models.Mod
 		return
 	}
 
+	start := time.Now()
 	url, err = minioClient.PresignedGetObject(ctx, bucketName, objectName, time.Hour, nil)
+	metrics.ObserveS3Operation("presigned_get_object", start, err)
 	if err != nil {
 		err = errors.Wrap(err, "presigned get object")
 		return
@@ -429,6 +447,112 @@ func (s *modelService) PreSignDownloadUrl(ctx context.Context, model *models.Mod
 	return
 }
 
+func (s *modelService) removeS3Object(ctx context.Context, model *models.Model) (err error) {
+	modelRepository, err := ModelRepositoryService.GetAssociatedModelRepository(ctx, model)
+	if err != nil {
+		return
+	}
+	org, err := OrganizationService.GetAssociatedOrganization(ctx, modelRepository)
+	if err != nil {
+		return
+	}
+	s3Config, err := OrganizationService.GetS3Config(ctx, org)
+	if err != nil {
+		return
+	}
+	minioClient, err := s3Config.GetMinioClient()
+	if err != nil {
+		err = errors.Wrap(err, "create s3 client")
+		return
+	}
+
+	bucketName, err := s.GetS3BucketName(ctx, model)
+	if err != nil {
+		return
+	}
+
+	objectName, err := s.getS3ObjectName(ctx, model)
+	if err != nil {
+		return
+	}
+
+	logrus.Debugf("removing from s3: %s/%s", bucketName, objectName)
+	start := time.Now()
+	err = minioClient.RemoveObject(ctx, bucketName, objectName, minio.RemoveObjectOptions{})
+	metrics.ObserveS3Operation("remove_object", start, err)
+	if err != nil {
+		err = errors.Wrap(err, "remove object")
+		return
+	}
+	return
+}
+
+func (s *modelService) Delete(ctx context.Context, model *models.Model) (*models.Model, error) {
+	modelRepository, err := ModelRepositoryService.GetAssociatedModelRepository(ctx, model)
+	if err != nil {
+		return nil, err
+	}
+	org, err := OrganizationService.GetAssociatedOrganization(ctx, modelRepository)
+	if err != nil {
+		return nil, err
+	}
+
+	_, total, err := BentoService.List(ctx, ListBentoOption{
+		ModelIds: &[]uint{model.ID},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "list bentos")
+	}
+	if total > 0 {
+		return nil, errors.Errorf("model %s:%s is still used by %d bentos", modelRepository.Name, model.Version, total)
+	}
+
+	modelAliases, err := ModelAliasService.List(ctx, ListModelAliasOption{
+		ModelIds: &[]uint{model.ID},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "list model aliases")
+	}
+	if len(modelAliases) > 0 {
+		return nil, errors.Errorf("model %s:%s is still referenced by alias %s", modelRepository.Name, model.Version, modelAliases[0].Name)
+	}
+
+	// nolint: ineffassign,staticcheck
+	db, ctx, df, err := startTransaction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { df(err) }()
+
+	err = LabelService.DeleteByResource(ctx, model)
+	if err != nil {
+		return nil, errors.Wrap(err, "delete labels")
+	}
+
+	_, err = EventService.CreateByCurrentUser(ctx, CreateEventOption{
+		OrganizationId: &org.ID,
+		ResourceType:   modelschemas.ResourceTypeModel,
+		ResourceId:     model.ID,
+		Status:         modelschemas.EventStatusSuccess,
+		OperationName:  "deleted",
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "create event")
+	}
+
+	err = db.Unscoped().Delete(model).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.removeS3Object(ctx, model)
+	if err != nil {
+		return nil, err
+	}
+
+	return model, nil
+}
+
 func (s *modelService) getS3ObjectName(ctx context.Context, model *models.Model) (string, error) {
 	modelRepository, err := ModelRepositoryService.GetAssociatedModelRepository(ctx, model)
 	if err != nil {
@@ -621,6 +745,22 @@ func (s *modelService) GetByVersion(ctx context.Context, modelRepositoryId uint,
 	return &model, nil
 }
 
+// GetByVersionOrAlias falls back to resolve the version as an alias of the repository
+func (s *modelService) GetByVersionOrAlias(ctx context.Context, modelRepositoryId uint, versionOrAlias string) (*models.Model, error) {
+	model, err := s.GetByVersion(ctx, modelRepositoryId, versionOrAlias)
+	if err == nil || !utils.IsNotFound(err) {
+		return model, err
+	}
+	modelAlias, aliasErr := ModelAliasService.GetByName(ctx, modelRepositoryId, versionOrAlias)
+	if aliasErr != nil {
+		if utils.IsNotFound(aliasErr) {
+			return nil, err
+		}
+		return nil, aliasErr
+	}
+	return s.GetAssociatedModel(ctx, modelAlias)
+}
+
 func (s *modelService) ListByUids(ctx context.Context, uids []string) ([]*models.Model, error) {
 	models_ := make([]*models.Model, 0, len(uids))
 	if len(uids) == 0 {
@@ -668,6 +808,13 @@ func (s *modelService) List(ctx context.Context, opt ListModelOption) ([]*models
 	if opt.Modules != nil {
 		query = query.Where("model.manifest->>'module' in (?)", *opt.Modules)
 	}
+	if opt.VisitorId != nil {
+		var err error
+		query, err = ModelRepositoryMemberService.BindVisibleQuery(ctx, query, "model_repository", *opt.VisitorId, opt.OrganizationId)
+		if err != nil {
+			return nil, 0, errors.Wrap(err, "bind visible query")
+		}
+	}
 	query = opt.BindQueryWithKeywords(query, "model_repository")
 	query = opt.BindQueryWithLabels(query, modelschemas.ResourceTypeModel)
 	query = query.Select("distinct(model.*)")
@@ -0,0 +1,216 @@
+package services
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/bentoml/yatai-schemas/modelschemas"
+	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/common/consts"
+	"github.com/bentoml/yatai/common/utils"
+)
+
+type modelAliasService struct{}
+
+var ModelAliasService = modelAliasService{}
+
+func (s *modelAliasService) getBaseDB(ctx context.Context) *gorm.DB {
+	return mustGetSession(ctx).Model(&models.ModelAlias{})
+}
+
+type SetModelAliasOption struct {
+	CreatorId         uint
+	ModelRepositoryId uint
+	ModelId           uint
+	Name              string
+}
+
+type ListModelAliasOption struct {
+	ModelRepositoryId *uint
+	ModelIds          *[]uint
+	Names             *[]string
+}
+
+// Set creates the alias or moves it to another model, every move is recorded as an event.
+func (s *modelAliasService) Set(ctx context.Context, opt SetModelAliasOption) (modelAlias *models.ModelAlias, err error) {
+	errs := validation.IsDNS1123Label(opt.Name)
+	if len(errs) > 0 {
+		err = errors.New(strings.Join(errs, ";"))
+		return
+	}
+
+	model, err := ModelService.Get(ctx, opt.ModelId)
+	if err != nil {
+		return
+	}
+	if model.ModelRepositoryId != opt.ModelRepositoryId {
+		err = errors.Errorf("model %s does not belong to this model repository", model.Version)
+		return
+	}
+	_, err = ModelService.GetByVersion(ctx, opt.ModelRepositoryId, opt.Name)
+	if err == nil {
+		err = errors.Errorf("alias %s conflicts with an existing model version", opt.Name)
+		return
+	}
+	if !utils.IsNotFound(err) {
+		return
+	}
+
+	// nolint: ineffassign,staticcheck
+	db, ctx, df, err := startTransaction(ctx)
+	if err != nil {
+		return
+	}
+	defer func() { df(err) }()
+
+	modelAlias, err = s.GetByName(ctx, opt.ModelRepositoryId, opt.Name)
+	isNotFound := utils.IsNotFound(err)
+	if err != nil && !isNotFound {
+		return
+	}
+	if isNotFound {
+		modelAlias = &models.ModelAlias{
+			ResourceMixin: models.ResourceMixin{
+				Name: opt.Name,
+			},
+			CreatorAssociate: models.CreatorAssociate{
+				CreatorId: opt.CreatorId,
+			},
+			ModelRepositoryAssociate: models.ModelRepositoryAssociate{
+				ModelRepositoryId: opt.ModelRepositoryId,
+			},
+			ModelAssociate: models.ModelAssociate{
+				ModelId: opt.ModelId,
+			},
+		}
+		err = db.Create(modelAlias).Error
+		if err != nil {
+			return
+		}
+	} else {
+		if modelAlias.ModelId == opt.ModelId {
+			return
+		}
+		err = db.Model(&models.ModelAlias{}).Where("id = ?", modelAlias.ID).Updates(map[string]interface{}{
+			"model_id":   opt.ModelId,
+			"creator_id": opt.CreatorId,
+		}).Error
+		if err != nil {
+			return
+		}
+		modelAlias.ModelId = opt.ModelId
+		modelAlias.CreatorId = opt.CreatorId
+		modelAlias.AssociatedCreatorCache = nil
+	}
+	modelAlias.AssociatedModelCache = model
+
+	org, err := s.getOrganization(ctx, model)
+	if err != nil {
+		return
+	}
+	_, err = EventService.CreateByCurrentUser(ctx, CreateEventOption{
+		Name:           opt.Name,
+		OrganizationId: &org.ID,
+		ResourceType:   modelschemas.ResourceTypeModel,
+		ResourceId:     model.ID,
+		Status:         modelschemas.EventStatusSuccess,
+		OperationName:  "aliased",
+	})
+	if err != nil {
+		err = errors.Wrap(err, "create event")
+	}
+	return
+}
+
+func (s *modelAliasService) Delete(ctx context.Context, modelAlias *models.ModelAlias) (*models.ModelAlias, error) {
+	model, err := ModelService.GetAssociatedModel(ctx, modelAlias)
+	if err != nil {
+		return nil, err
+	}
+	org, err := s.getOrganization(ctx, model)
+	if err != nil {
+		return nil, err
+	}
+
+	// nolint: ineffassign,staticcheck
+	db, ctx, df, err := startTransaction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { df(err) }()
+
+	_, err = EventService.CreateByCurrentUser(ctx, CreateEventOption{
+		Name:           modelAlias.Name,
+		OrganizationId: &org.ID,
+		ResourceType:   modelschemas.ResourceTypeModel,
+		ResourceId:     model.ID,
+		Status:         modelschemas.EventStatusSuccess,
+		OperationName:  "unaliased",
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "create event")
+	}
+
+	err = db.Unscoped().Delete(modelAlias).Error
+	if err != nil {
+		return nil, err
+	}
+	return modelAlias, nil
+}
+
+func (s *modelAliasService) getOrganization(ctx context.Context, model *models.Model) (*models.Organization, error) {
+	modelRepository, err := ModelRepositoryService.GetAssociatedModelRepository(ctx, model)
+	if err != nil {
+		return nil, err
+	}
+	return OrganizationService.GetAssociatedOrganization(ctx, modelRepository)
+}
+
+func (s *modelAliasService) GetByName(ctx context.Context, modelRepositoryId uint, name string) (*models.ModelAlias, error) {
+	var modelAlias models.ModelAlias
+	err := getBaseQuery(ctx, s).Where("model_repository_id = ?", modelRepositoryId).Where("name = ?", name).First(&modelAlias).Error
+	if err != nil {
+		return nil, errors.Wrapf(err, "get model alias %s", name)
+	}
+	if modelAlias.ID == 0 {
+		return nil, consts.ErrNotFound
+	}
+	return &modelAlias, nil
+}
+
+func (s *modelAliasService) List(ctx context.Context, opt ListModelAliasOption) ([]*models.ModelAlias, error) {
+	query := getBaseQuery(ctx, s)
+	if opt.ModelRepositoryId != nil {
+		query = query.Where("model_repository_id = ?", *opt.ModelRepositoryId)
+	}
+	if opt.ModelIds != nil {
+		query = query.Where("model_id in (?)", *opt.ModelIds)
+	}
+	if opt.Names != nil {
+		query = query.Where("name in (?)", *opt.Names)
+	}
+	modelAliases := make([]*models.ModelAlias, 0)
+	err := query.Order("name ASC").Find(&modelAliases).Error
+	return modelAliases, err
+}
+
+func (s *modelAliasService) GroupNamesByModelIds(ctx context.Context, modelIds []uint) (map[uint][]string, error) {
+	res := make(map[uint][]string, len(modelIds))
+	if len(modelIds) == 0 {
+		return res, nil
+	}
+	modelAliases, err := s.List(ctx, ListModelAliasOption{
+		ModelIds: &modelIds,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, modelAlias := range modelAliases {
+		res[modelAlias.ModelId] = append(res[modelAlias.ModelId], modelAlias.Name)
+	}
+	return res, nil
+}
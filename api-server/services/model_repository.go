@@ -24,26 +24,38 @@ type CreateModelRepositoryOption struct {
 	OrganizationId uint
 	Name           string
 	Labels         modelschemas.LabelItemsSchema
+	Visibility     models.RepositoryVisibility
 }
 
 type UpdateModelRepositoryOption struct {
-	Description *string
-	Labels      *modelschemas.LabelItemsSchema
+	Description     *string
+	Labels          *modelschemas.LabelItemsSchema
+	RetentionPolicy **models.RetentionPolicy
+	Visibility      *models.RepositoryVisibility
 }
 
 type ListModelRepositoryOption struct {
 	BaseListOption
 	BaseListByLabelsOption
-	OrganizationId *uint
-	CreatorId      *uint
-	CreatorIds     *[]uint
-	LastUpdaterIds *[]uint
-	Order          *string
-	Names          *[]string
-	Ids            *[]uint
+	OrganizationId     *uint
+	CreatorId          *uint
+	CreatorIds         *[]uint
+	LastUpdaterIds     *[]uint
+	Order              *string
+	Names              *[]string
+	Ids                *[]uint
+	HasRetentionPolicy *bool
+	// VisitorId filters out the private repositories which the visitor cannot view
+	VisitorId *uint
 }
 
 func (*modelRepositoryService) Create(ctx context.Context, opt CreateModelRepositoryOption) (*models.ModelRepository, error) {
+	if opt.Visibility == "" {
+		opt.Visibility = models.RepositoryVisibilityInternal
+	}
+	if !opt.Visibility.IsValid() {
+		return nil, errors.Errorf("invalid repository visibility %s", opt.Visibility)
+	}
 	modelRepository := models.ModelRepository{
 		ResourceMixin: models.ResourceMixin{
 			Name: opt.Name,
@@ -54,11 +66,24 @@ func (*modelRepositoryService) Create(ctx context.Context, opt CreateModelReposi
 		OrganizationAssociate: models.OrganizationAssociate{
 			OrganizationId: opt.OrganizationId,
 		},
+		Visibility: opt.Visibility,
 	}
 	err := mustGetSession(ctx).Create(&modelRepository).Error
 	if err != nil {
 		return nil, err
 	}
+	if opt.Visibility == models.RepositoryVisibilityPrivate {
+		// the creator must keep the access to the private repository
+		_, err = ModelRepositoryMemberService.Create(ctx, opt.CreatorId, CreateRepositoryMemberOption{
+			CreatorId:    opt.CreatorId,
+			UserId:       opt.CreatorId,
+			RepositoryId: modelRepository.ID,
+			Role:         modelschemas.MemberRoleAdmin,
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "create repository member")
+		}
+	}
 	err = LabelService.CreateOrUpdateLabelsFromLabelItemsSchema(ctx, opt.Labels, opt.CreatorId, opt.OrganizationId, &modelRepository)
 	return &modelRepository, err
 }
@@ -74,6 +99,25 @@ func (s *modelRepositoryService) Update(ctx context.Context, modelRepository *mo
 			}
 		}()
 	}
+	if opt.RetentionPolicy != nil {
+		updaters["retention_policy"] = *opt.RetentionPolicy
+		defer func() {
+			if err == nil {
+				modelRepository.RetentionPolicy = *opt.RetentionPolicy
+			}
+		}()
+	}
+	if opt.Visibility != nil {
+		if !opt.Visibility.IsValid() {
+			return nil, errors.Errorf("invalid repository visibility %s", *opt.Visibility)
+		}
+		updaters["visibility"] = *opt.Visibility
+		defer func() {
+			if err == nil {
+				modelRepository.Visibility = *opt.Visibility
+			}
+		}()
+	}
 	if len(updaters) == 0 {
 		return modelRepository, nil
 	}
@@ -100,6 +144,58 @@ func (s *modelRepositoryService) Update(ctx context.Context, modelRepository *mo
 	return modelRepository, err
 }
 
+func (s *modelRepositoryService) Delete(ctx context.Context, modelRepository *models.ModelRepository) (*models.ModelRepository, error) {
+	_, total, err := ModelService.List(ctx, ListModelOption{
+		ModelRepositoryId: &modelRepository.ID,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "list models")
+	}
+	if total > 0 {
+		return nil, errors.Errorf("model repository %s is not empty, it still has %d models", modelRepository.Name, total)
+	}
+
+	// nolint: ineffassign,staticcheck
+	db, ctx, df, err := startTransaction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { df(err) }()
+
+	err = LabelService.DeleteByResource(ctx, modelRepository)
+	if err != nil {
+		return nil, errors.Wrap(err, "delete labels")
+	}
+
+	err = UserGroupGrantService.DeleteByResource(ctx, modelRepository)
+	if err != nil {
+		return nil, errors.Wrap(err, "delete user group grants")
+	}
+
+	err = ModelRepositoryMemberService.DeleteByRepository(ctx, modelRepository)
+	if err != nil {
+		return nil, errors.Wrap(err, "delete repository members")
+	}
+
+	_, err = EventService.CreateByCurrentUser(ctx, CreateEventOption{
+		OrganizationId: &modelRepository.OrganizationId,
+		ResourceType:   modelschemas.ResourceTypeModelRepository,
+		ResourceId:     modelRepository.ID,
+		Status:         modelschemas.EventStatusSuccess,
+		OperationName:  "deleted",
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "create event")
+	}
+
+	err = db.Unscoped().Delete(modelRepository).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return modelRepository, nil
+}
+
 func (s *modelRepositoryService) Get(ctx context.Context, id uint) (*models.ModelRepository, error) {
 	var modelRepository models.ModelRepository
 	err := s.getBaseDB(ctx).Where("id = ?", id).First(&modelRepository).Error
@@ -150,6 +246,20 @@ func (s *modelRepositoryService) List(ctx context.Context, opt ListModelReposito
 	if opt.CreatorIds != nil {
 		query = query.Where("model_repository.creator_id in (?)", *opt.CreatorIds)
 	}
+	if opt.VisitorId != nil {
+		var err error
+		query, err = ModelRepositoryMemberService.BindVisibleQuery(ctx, query, "model_repository", *opt.VisitorId, opt.OrganizationId)
+		if err != nil {
+			return nil, 0, errors.Wrap(err, "bind visible query")
+		}
+	}
+	if opt.HasRetentionPolicy != nil {
+		if *opt.HasRetentionPolicy {
+			query = query.Where("model_repository.retention_policy IS NOT NULL")
+		} else {
+			query = query.Where("model_repository.retention_policy IS NULL")
+		}
+	}
 	query = query.Joins("LEFT JOIN model ON model.model_repository_id = model_repository.id")
 	query = query.Joins("LEFT OUTER JOIN model m2 ON m2.model_repository_id = model_repository.id AND model.id < m2.id")
 	query = query.Where("m2.id IS NULL")
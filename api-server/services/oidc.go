@@ -0,0 +1,205 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/oauth2"
+
+	"github.com/bentoml/yatai-schemas/modelschemas"
+	"github.com/bentoml/yatai/api-server/config"
+	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/common/utils"
+)
+
+type oidcService struct {
+	mu       sync.Mutex
+	provider *oidc.Provider
+}
+
+var OIDCService = oidcService{}
+
+// OIDCClaims is the yatai user read from the id token claims
+type OIDCClaims struct {
+	Issuer    string
+	Subject   string
+	Username  string
+	Email     string
+	FirstName string
+	LastName  string
+	Groups    []string
+}
+
+func (s *oidcService) IsEnabled() bool {
+	return config.YataiConfig.OIDC != nil
+}
+
+// getProvider discovers the idp on the first login, a failed discovery is retried on the next login
+func (s *oidcService) getProvider() (*oidc.Provider, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.provider != nil {
+		return s.provider, nil
+	}
+	// the provider keeps the ctx to fetch the rotated signing keys, so it must not be the request ctx
+	ctx := oidc.ClientContext(context.Background(), &http.Client{Timeout: 30 * time.Second})
+	provider, err := oidc.NewProvider(ctx, config.YataiConfig.OIDC.Issuer)
+	if err != nil {
+		return nil, errors.Wrapf(err, "discover oidc issuer %s", config.YataiConfig.OIDC.Issuer)
+	}
+	s.provider = provider
+	return provider, nil
+}
+
+func (s *oidcService) getOAuth2Config(provider *oidc.Provider) *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     config.YataiConfig.OIDC.ClientId,
+		ClientSecret: config.YataiConfig.OIDC.ClientSecret,
+		RedirectURL:  config.YataiConfig.OIDC.RedirectURL,
+		Endpoint:     provider.Endpoint(),
+		Scopes:       config.YataiConfig.OIDC.Scopes,
+	}
+}
+
+// NewCodeVerifier returns a pkce code verifier
+func (s *oidcService) NewCodeVerifier() string {
+	return utils.RandAlphanumString(64)
+}
+
+func (s *oidcService) AuthCodeURL(ctx context.Context, state, nonce, codeVerifier string) (string, error) {
+	provider, err := s.getProvider()
+	if err != nil {
+		return "", err
+	}
+	codeChallenge := sha256.Sum256([]byte(codeVerifier))
+	return s.getOAuth2Config(provider).AuthCodeURL(state,
+		oidc.Nonce(nonce),
+		oauth2.SetAuthURLParam("code_challenge", base64.RawURLEncoding.EncodeToString(codeChallenge[:])),
+		oauth2.SetAuthURLParam("code_challenge_method", "S256"),
+	), nil
+}
+
+// Exchange redeems the authorization code and reads the claims from the verified id token
+func (s *oidcService) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*OIDCClaims, error) {
+	provider, err := s.getProvider()
+	if err != nil {
+		return nil, err
+	}
+	token, err := s.getOAuth2Config(provider).Exchange(ctx, code, oauth2.SetAuthURLParam("code_verifier", codeVerifier))
+	if err != nil {
+		return nil, errors.Wrap(err, "exchange oidc authorization code")
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, errors.New("no id_token in the oidc token response")
+	}
+	idToken, err := provider.Verifier(&oidc.Config{ClientID: config.YataiConfig.OIDC.ClientId}).Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, errors.Wrap(err, "verify oidc id token")
+	}
+	if idToken.Nonce != nonce {
+		return nil, errors.New("the nonce of the oidc id token does not match")
+	}
+	rawClaims := make(map[string]interface{})
+	err = idToken.Claims(&rawClaims)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse oidc id token claims")
+	}
+	return s.parseClaims(idToken.Issuer, idToken.Subject, rawClaims)
+}
+
+func (s *oidcService) parseClaims(issuer, subject string, rawClaims map[string]interface{}) (*OIDCClaims, error) {
+	getString := func(name string) string {
+		v, _ := rawClaims[name].(string)
+		return v
+	}
+	claims := &OIDCClaims{
+		Issuer:    issuer,
+		Subject:   subject,
+		Username:  getString(config.YataiConfig.OIDC.UsernameClaim),
+		Email:     getString(config.YataiConfig.OIDC.EmailClaim),
+		FirstName: getString("given_name"),
+		LastName:  getString("family_name"),
+	}
+	if claims.Username == "" {
+		return nil, errors.Errorf("the claim %s of the oidc id token is empty", config.YataiConfig.OIDC.UsernameClaim)
+	}
+	// the idps send the groups as a list or as a single string
+	switch groups := rawClaims[config.YataiConfig.OIDC.GroupsClaim].(type) {
+	case []interface{}:
+		for _, group := range groups {
+			if group, ok := group.(string); ok {
+				claims.Groups = append(claims.Groups, group)
+			}
+		}
+	case string:
+		claims.Groups = strings.Fields(groups)
+	}
+	return claims, nil
+}
+
+// MapGroupRoles returns the role of the user in every organization of the oidc group mappings
+func (s *oidcService) MapGroupRoles(groups []string) map[string]modelschemas.MemberRole {
+	return mapGroupRoles(config.YataiConfig.OIDC.GroupMappings, groups)
+}
+
+// Login returns the user of the idp account, the user is created on the first login,
+// then the memberships of the mapped organizations are synced with the idp groups.
+func (s *oidcService) Login(ctx context.Context, claims *OIDCClaims) (*models.User, error) {
+	user, err := UserService.GetByOidcSubject(ctx, claims.Issuer, claims.Subject)
+	if err != nil && !utils.IsNotFound(err) {
+		return nil, errors.Wrap(err, "get user by oidc subject")
+	}
+	if utils.IsNotFound(err) {
+		user, err = s.linkOrCreateUser(ctx, claims)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = syncGroupMemberships(ctx, user, config.YataiConfig.OIDC.GroupMappings, claims.Groups)
+	if err != nil {
+		return nil, errors.Wrap(err, "sync organization memberships")
+	}
+	return user, nil
+}
+
+func (s *oidcService) linkOrCreateUser(ctx context.Context, claims *OIDCClaims) (*models.User, error) {
+	user, err := UserService.GetByName(ctx, claims.Username)
+	if err != nil && !utils.IsNotFound(err) {
+		return nil, errors.Wrap(err, "get user by name")
+	}
+	if err == nil {
+		// otherwise anyone who can pick the username in the idp could take over a local user
+		if !config.YataiConfig.OIDC.LinkExistingUsers || user.OidcSubject != nil {
+			return nil, errors.Errorf("the user %s already exists and is not linked to this sso account", claims.Username)
+		}
+		oidcIssuer := &claims.Issuer
+		oidcSubject := &claims.Subject
+		return UserService.Update(ctx, user, UpdateUserOption{
+			OidcIssuer:  &oidcIssuer,
+			OidcSubject: &oidcSubject,
+		})
+	}
+	user, err = UserService.Create(ctx, CreateUserOption{
+		Name:        claims.Username,
+		FirstName:   claims.FirstName,
+		LastName:    claims.LastName,
+		Email:       utils.StringPtrWithoutEmpty(claims.Email),
+		Perm:        modelschemas.UserPermPtr(modelschemas.UserPermDefault),
+		OidcIssuer:  &claims.Issuer,
+		OidcSubject: &claims.Subject,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "create user")
+	}
+	logrus.Infof("created user %s by oidc login", user.Name)
+	return user, nil
+}
@@ -0,0 +1,216 @@
+package services
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/bentoml/yatai-schemas/modelschemas"
+	"github.com/bentoml/yatai/api-server/config"
+)
+
+// mockIdP serves the discovery, the keys and the token endpoint of an oidc provider,
+// the authorization endpoint is skipped by handing the code to the token endpoint directly.
+type mockIdP struct {
+	t             *testing.T
+	server        *httptest.Server
+	key           *rsa.PrivateKey
+	codeChallenge string
+	nonce         string
+	claims        map[string]interface{}
+}
+
+func newMockIdP(t *testing.T) *mockIdP {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idp := &mockIdP{t: t, key: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", idp.discovery)
+	mux.HandleFunc("/keys", idp.keys)
+	mux.HandleFunc("/token", idp.token)
+	idp.server = httptest.NewServer(mux)
+	return idp
+}
+
+func (idp *mockIdP) writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func (idp *mockIdP) discovery(w http.ResponseWriter, r *http.Request) {
+	idp.writeJSON(w, map[string]interface{}{
+		"issuer":                                idp.server.URL,
+		"authorization_endpoint":                idp.server.URL + "/authorize",
+		"token_endpoint":                        idp.server.URL + "/token",
+		"jwks_uri":                              idp.server.URL + "/keys",
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+	})
+}
+
+func (idp *mockIdP) keys(w http.ResponseWriter, r *http.Request) {
+	idp.writeJSON(w, map[string]interface{}{
+		"keys": []map[string]interface{}{{
+			"kty": "RSA",
+			"alg": "RS256",
+			"use": "sig",
+			"kid": "test",
+			"n":   base64.RawURLEncoding.EncodeToString(idp.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(idp.key.E)).Bytes()),
+		}},
+	})
+}
+
+func (idp *mockIdP) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	codeChallenge := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if base64.RawURLEncoding.EncodeToString(codeChallenge[:]) != idp.codeChallenge {
+		http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+		return
+	}
+	claims := map[string]interface{}{
+		"iss":   idp.server.URL,
+		"sub":   "user-1",
+		"aud":   config.YataiConfig.OIDC.ClientId,
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"iat":   time.Now().Unix(),
+		"nonce": idp.nonce,
+	}
+	for k, v := range idp.claims {
+		claims[k] = v
+	}
+	idp.writeJSON(w, map[string]interface{}{
+		"access_token": "access-token",
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     idp.sign(claims),
+	})
+}
+
+func (idp *mockIdP) sign(claims map[string]interface{}) string {
+	encode := func(v interface{}) string {
+		content, err := json.Marshal(v)
+		if err != nil {
+			idp.t.Fatal(err)
+		}
+		return base64.RawURLEncoding.EncodeToString(content)
+	}
+	signingInput := encode(map[string]string{"alg": "RS256", "typ": "JWT", "kid": "test"}) + "." + encode(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, idp.key, crypto.SHA256, digest[:])
+	if err != nil {
+		idp.t.Fatal(err)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func setupOIDCTest(t *testing.T) *mockIdP {
+	idp := newMockIdP(t)
+	t.Cleanup(idp.server.Close)
+	oldOIDC := config.YataiConfig.OIDC
+	config.YataiConfig.OIDC = &config.YataiOIDCConfigYaml{
+		Issuer:        idp.server.URL,
+		ClientId:      "yatai",
+		ClientSecret:  "secret",
+		RedirectURL:   "http://localhost:7777/oauth/oidc/callback",
+		Scopes:        []string{"openid", "profile", "email", "groups"},
+		UsernameClaim: "preferred_username",
+		EmailClaim:    "email",
+		GroupsClaim:   "groups",
+		GroupMappings: []config.YataiGroupMappingYaml{
+			{Group: "ml", Organization: "default", Role: modelschemas.MemberRoleDeveloper},
+			{Group: "ml-admins", Organization: "default", Role: modelschemas.MemberRoleAdmin},
+			{Group: "ml", Organization: "research", Role: modelschemas.MemberRoleGuest},
+		},
+	}
+	OIDCService.provider = nil
+	t.Cleanup(func() {
+		config.YataiConfig.OIDC = oldOIDC
+		OIDCService.provider = nil
+	})
+	return idp
+}
+
+func TestOIDCAuthorizationCodeFlow(t *testing.T) {
+	idp := setupOIDCTest(t)
+	idp.claims = map[string]interface{}{
+		"preferred_username": "alice",
+		"email":              "alice@example.com",
+		"given_name":         "Alice",
+		"groups":             []string{"ml", "ml-admins"},
+	}
+	ctx := context.Background()
+
+	codeVerifier := OIDCService.NewCodeVerifier()
+	authCodeURL, err := OIDCService.AuthCodeURL(ctx, "state", "nonce", codeVerifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(authCodeURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := u.Query()
+	if query.Get("state") != "state" || query.Get("nonce") != "nonce" || query.Get("code_challenge_method") != "S256" {
+		t.Fatalf("unexpected auth code url %s", authCodeURL)
+	}
+	idp.codeChallenge = query.Get("code_challenge")
+	idp.nonce = query.Get("nonce")
+
+	claims, err := OIDCService.Exchange(ctx, "code", codeVerifier, "nonce")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.Issuer != idp.server.URL || claims.Subject != "user-1" || claims.Username != "alice" || claims.Email != "alice@example.com" || claims.FirstName != "Alice" {
+		t.Fatalf("unexpected claims %+v", claims)
+	}
+
+	roles := OIDCService.MapGroupRoles(claims.Groups)
+	if roles["default"] != modelschemas.MemberRoleAdmin || roles["research"] != modelschemas.MemberRoleGuest {
+		t.Fatalf("unexpected roles %v", roles)
+	}
+}
+
+func TestOIDCExchangeRejectsWrongVerifierAndNonce(t *testing.T) {
+	idp := setupOIDCTest(t)
+	idp.claims = map[string]interface{}{"preferred_username": "alice"}
+	ctx := context.Background()
+
+	codeVerifier := OIDCService.NewCodeVerifier()
+	authCodeURL, err := OIDCService.AuthCodeURL(ctx, "state", "nonce", codeVerifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, _ := url.Parse(authCodeURL)
+	idp.codeChallenge = u.Query().Get("code_challenge")
+	idp.nonce = "nonce"
+
+	if _, err := OIDCService.Exchange(ctx, "code", OIDCService.NewCodeVerifier(), "nonce"); err == nil {
+		t.Fatal("the exchange with a wrong code verifier should fail")
+	}
+	if _, err := OIDCService.Exchange(ctx, "code", codeVerifier, "another-nonce"); err == nil {
+		t.Fatal("the exchange with a wrong nonce should fail")
+	}
+}
+
+func TestOIDCMapGroupRolesWithoutGroups(t *testing.T) {
+	setupOIDCTest(t)
+	roles := OIDCService.MapGroupRoles(nil)
+	if len(roles) != 2 || roles["default"] != "" || roles["research"] != "" {
+		t.Fatalf("the mapped organizations should be mapped to the empty role, got %v", roles)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/minio/minio-go/v7"
@@ -17,6 +18,7 @@ import (
 	"github.com/bentoml/yatai/api-server/config"
 	"github.com/bentoml/yatai/api-server/models"
 	"github.com/bentoml/yatai/common/consts"
+	"github.com/bentoml/yatai/common/metrics"
 	"github.com/bentoml/yatai/common/utils"
 )
 
@@ -38,6 +40,8 @@ type CreateOrganizationOption struct {
 type UpdateOrganizationOption struct {
 	Description *string
 	Config      **modelschemas.OrganizationConfigSchema
+	// TwoFactorRequiredRole is the lowest role which must enable the two-factor authentication, empty means optional
+	TwoFactorRequiredRole *modelschemas.MemberRole
 }
 
 type ListOrganizationOption struct {
@@ -89,6 +93,19 @@ func (s *organizationService) Update(ctx context.Context, o *models.Organization
 			}
 		}()
 	}
+	if opt.TwoFactorRequiredRole != nil {
+		switch *opt.TwoFactorRequiredRole {
+		case "", modelschemas.MemberRoleDeveloper, modelschemas.MemberRoleAdmin:
+		default:
+			return nil, errors.Errorf("the two-factor authentication cannot be required for the role %s", *opt.TwoFactorRequiredRole)
+		}
+		updaters["two_factor_required_role"] = *opt.TwoFactorRequiredRole
+		defer func() {
+			if err == nil {
+				o.TwoFactorRequiredRole = *opt.TwoFactorRequiredRole
+			}
+		}()
+	}
 	if len(updaters) == 0 {
 		return o, nil
 	}
@@ -181,6 +198,11 @@ func (s *organizationService) List(ctx context.Context, opt ListOrganizationOpti
 		if err != nil {
 			return nil, 0, errors.Wrap(err, "list organization ids")
 		}
+		groupOrgIds, err := UserGroupGrantService.ListUserResourceIds(ctx, *opt.VisitorId, modelschemas.ResourceTypeOrganization)
+		if err != nil {
+			return nil, 0, errors.Wrap(err, "list user group organization ids")
+		}
+		orgIds = append(orgIds, groupOrgIds...)
 		// postgresql `in` clause cannot be empty, so push 0 to avoid it empty
 		orgIds = append(orgIds, 0)
 		query = query.Where("(creator_id = ? or id in (?))", *opt.VisitorId, orgIds)
@@ -320,16 +342,32 @@ func (c *S3Config) GetMinioCore() (*minio.Core, error) {
 }
 
 func (c *S3Config) MakeSureBucket(ctx context.Context, bucketName string) error {
+	return c.makeSureBucket(ctx, bucketName, false)
+}
+
+// CheckBucket is the read-only MakeSureBucket, it fails instead of creating a missing bucket.
+func (c *S3Config) CheckBucket(ctx context.Context, bucketName string) error {
+	return c.makeSureBucket(ctx, bucketName, true)
+}
+
+func (c *S3Config) makeSureBucket(ctx context.Context, bucketName string, readOnly bool) error {
 	minioClient, err := c.GetMinioClient()
 	if err != nil {
 		return err
 	}
+	start := time.Now()
 	exists, err := minioClient.BucketExists(ctx, bucketName)
+	metrics.ObserveS3Operation("bucket_exists", start, err)
 	if err != nil {
 		return errors.Wrapf(err, "get bucket %s exist", bucketName)
 	}
+	if !exists && readOnly {
+		return errors.Errorf("bucket %s does not exist", bucketName)
+	}
 	if !exists {
+		start = time.Now()
 		err = minioClient.MakeBucket(ctx, bucketName, minio.MakeBucketOptions{Region: c.Region})
+		metrics.ObserveS3Operation("make_bucket", start, err)
 		if err != nil {
 			exists_, err_ := minioClient.BucketExists(ctx, bucketName)
 			if err_ != nil {
@@ -121,14 +121,25 @@ func (s *organizationMemberService) GetOrganization(ctx context.Context, resourc
 	return OrganizationService.Get(ctx, resourceId)
 }
 
+// GetEffectiveRole returns the role of the user in the organization, granted directly or by the user groups
+func (s *organizationMemberService) GetEffectiveRole(ctx context.Context, userId, organizationId uint) (modelschemas.MemberRole, error) {
+	var directRole modelschemas.MemberRole
+	member, err := s.GetBy(ctx, userId, organizationId)
+	if err != nil && !utils.IsNotFound(err) {
+		return "", err
+	}
+	if err == nil {
+		directRole = member.Role
+	}
+	return MemberService.getEffectiveRole(ctx, userId, s.GetResourceType(), organizationId, directRole)
+}
+
 func (s *organizationMemberService) CheckRoles(ctx context.Context, userId, resourceId uint, roles []modelschemas.MemberRole) (bool, error) {
-	q := s.getBaseDB(ctx).
-		Where("user_id = ?", userId).
-		Where("organization_id = ?", resourceId).
-		Where("role in (?)", roles)
-	var total int64
-	err := q.Count(&total).Error
-	return total > 0, err
+	effectiveRole, err := s.GetEffectiveRole(ctx, userId, resourceId)
+	if err != nil {
+		return false, err
+	}
+	return containsMemberRole(roles, effectiveRole), nil
 }
 
 func (s *organizationMemberService) Update(ctx context.Context, m *models.OrganizationMember, operatorId uint, opt UpdateOrganizationMemberOption) (*models.OrganizationMember, error) {
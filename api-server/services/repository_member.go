@@ -0,0 +1,199 @@
+package services
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+
+	"github.com/bentoml/yatai-schemas/modelschemas"
+	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/common/consts"
+	"github.com/bentoml/yatai/common/utils"
+)
+
+// repositoryMemberService is the member manager of the bento repositories or the model repositories,
+// the members of the internal repositories inherit their roles in the organization.
+type repositoryMemberService struct {
+	resourceType modelschemas.ResourceType
+}
+
+var BentoRepositoryMemberService = repositoryMemberService{
+	resourceType: modelschemas.ResourceTypeBentoRepository,
+}
+
+var ModelRepositoryMemberService = repositoryMemberService{
+	resourceType: modelschemas.ResourceTypeModelRepository,
+}
+
+func (s *repositoryMemberService) getBaseDB(ctx context.Context) *gorm.DB {
+	return mustGetSession(ctx).Model(&models.RepositoryMember{}).Where("resource_type = ?", s.resourceType)
+}
+
+type CreateRepositoryMemberOption struct {
+	CreatorId    uint
+	UserId       uint
+	RepositoryId uint
+	Role         modelschemas.MemberRole
+}
+
+type UpdateRepositoryMemberOption struct {
+	Role modelschemas.MemberRole
+}
+
+type ListRepositoryMemberOption struct {
+	UserId       *uint
+	RepositoryId *uint
+}
+
+func (s *repositoryMemberService) GetResourceType() modelschemas.ResourceType {
+	return s.resourceType
+}
+
+func (s *repositoryMemberService) getRepository(ctx context.Context, repositoryId uint) (models.IRepository, error) {
+	switch s.resourceType {
+	case modelschemas.ResourceTypeBentoRepository:
+		return BentoRepositoryService.Get(ctx, repositoryId)
+	case modelschemas.ResourceTypeModelRepository:
+		return ModelRepositoryService.Get(ctx, repositoryId)
+	}
+	return nil, errors.Errorf("%s is not a repository", s.resourceType)
+}
+
+func (s *repositoryMemberService) Create(ctx context.Context, operatorId uint, opt CreateRepositoryMemberOption) (*models.RepositoryMember, error) {
+	oldMember, err := s.GetBy(ctx, opt.UserId, opt.RepositoryId)
+	if err != nil && !utils.IsNotFound(err) {
+		return nil, err
+	}
+
+	if err == nil {
+		return s.Update(ctx, oldMember, operatorId, UpdateRepositoryMemberOption{Role: opt.Role})
+	}
+
+	member := &models.RepositoryMember{
+		CreatorAssociate: models.CreatorAssociate{
+			CreatorId: opt.CreatorId,
+		},
+		UserAssociate: models.UserAssociate{
+			UserId: opt.UserId,
+		},
+		ResourceType: s.resourceType,
+		RepositoryId: opt.RepositoryId,
+		Role:         opt.Role,
+	}
+	err = mustGetSession(ctx).Create(member).Error
+	if err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+func (s *repositoryMemberService) Update(ctx context.Context, m *models.RepositoryMember, operatorId uint, opt UpdateRepositoryMemberOption) (*models.RepositoryMember, error) {
+	err := s.getBaseDB(ctx).Where("id = ?", m.ID).Updates(map[string]interface{}{
+		"role": opt.Role,
+	}).Error
+	if err == nil {
+		m.Role = opt.Role
+	}
+	return m, err
+}
+
+func (s *repositoryMemberService) GetBy(ctx context.Context, userId, repositoryId uint) (*models.RepositoryMember, error) {
+	var member models.RepositoryMember
+	err := getBaseQuery(ctx, s).Where("repository_id = ?", repositoryId).Where("user_id = ?", userId).First(&member).Error
+	if err != nil {
+		return nil, err
+	}
+	if member.ID == 0 {
+		return nil, consts.ErrNotFound
+	}
+	return &member, nil
+}
+
+func (s *repositoryMemberService) List(ctx context.Context, opt ListRepositoryMemberOption) ([]*models.RepositoryMember, error) {
+	members := make([]*models.RepositoryMember, 0)
+	query := getBaseQuery(ctx, s)
+	if opt.RepositoryId != nil {
+		query = query.Where("repository_id = ?", *opt.RepositoryId)
+	}
+	if opt.UserId != nil {
+		query = query.Where("user_id = ?", *opt.UserId)
+	}
+	err := query.Order("id DESC").Find(&members).Error
+	return members, err
+}
+
+func (s *repositoryMemberService) Delete(ctx context.Context, m *models.RepositoryMember, operatorId uint) (*models.RepositoryMember, error) {
+	err := mustGetSession(ctx).Unscoped().Delete(m).Error
+	return m, err
+}
+
+func (s *repositoryMemberService) DeleteByRepository(ctx context.Context, repository models.IRepository) error {
+	return mustGetSession(ctx).Unscoped().Where("resource_type = ?", s.resourceType).Where("repository_id = ?", repository.GetId()).Delete(&models.RepositoryMember{}).Error
+}
+
+func (s *repositoryMemberService) GetOrganization(ctx context.Context, resourceId uint) (*models.Organization, error) {
+	repository, err := s.getRepository(ctx, resourceId)
+	if err != nil {
+		return nil, errors.Wrap(err, "get organization")
+	}
+	return OrganizationService.Get(ctx, repository.GetAssociatedOrganizationId())
+}
+
+func (s *repositoryMemberService) CheckRoles(ctx context.Context, userId, resourceId uint, roles []modelschemas.MemberRole) (bool, error) {
+	repository, err := s.getRepository(ctx, resourceId)
+	if err != nil {
+		return false, err
+	}
+	var directRole modelschemas.MemberRole
+	member, err := s.GetBy(ctx, userId, resourceId)
+	if err != nil && !utils.IsNotFound(err) {
+		return false, err
+	}
+	if err == nil {
+		directRole = member.Role
+	}
+	if repository.GetVisibility() != models.RepositoryVisibilityPrivate {
+		orgRole, err := OrganizationMemberService.GetEffectiveRole(ctx, userId, repository.GetAssociatedOrganizationId())
+		if err != nil {
+			return false, errors.Wrap(err, "get organization role")
+		}
+		directRole = maxMemberRole(directRole, orgRole)
+	}
+	return MemberService.checkEffectiveRole(ctx, userId, s.resourceType, resourceId, directRole, roles)
+}
+
+// BindVisibleQuery filters out the private repositories which the visitor is not a member of,
+// the organization admins can see all the repositories of the organization.
+func (s *repositoryMemberService) BindVisibleQuery(ctx context.Context, query *gorm.DB, tableName string, visitorId uint, organizationId *uint) (*gorm.DB, error) {
+	visitor, err := UserService.Get(ctx, visitorId)
+	if err != nil {
+		return nil, errors.Wrapf(err, "get user %d", visitorId)
+	}
+	if visitor.IsSuperAdmin() {
+		return query, nil
+	}
+	if organizationId != nil {
+		org, err := OrganizationService.Get(ctx, *organizationId)
+		if err != nil {
+			return nil, errors.Wrap(err, "get organization")
+		}
+		if org.CreatorId == visitorId || UserService.IsAdmin(ctx, visitor, org) {
+			return query, nil
+		}
+	}
+	repositoryIds := make([]uint, 0)
+	err = s.getBaseDB(ctx).Where("user_id = ?", visitorId).Pluck("repository_id", &repositoryIds).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "list member repository ids")
+	}
+	groupRepositoryIds, err := UserGroupGrantService.ListUserResourceIds(ctx, visitorId, s.resourceType)
+	if err != nil {
+		return nil, errors.Wrap(err, "list user group repository ids")
+	}
+	repositoryIds = append(repositoryIds, groupRepositoryIds...)
+	// postgresql `in` clause cannot be empty, so push 0 to avoid it empty
+	repositoryIds = append(repositoryIds, 0)
+	tableName = query.Statement.Quote(tableName)
+	return query.Where("("+tableName+".visibility != ? OR "+tableName+".id in (?))", models.RepositoryVisibilityPrivate, repositoryIds), nil
+}
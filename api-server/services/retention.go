@@ -0,0 +1,236 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/bentoml/yatai-schemas/modelschemas"
+	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/common/utils"
+)
+
+type retentionService struct{}
+
+var RetentionService = retentionService{}
+
+func (s *retentionService) listLabelledResourceIds(ctx context.Context, resourceType modelschemas.ResourceType, resourceIds []uint) (map[uint]struct{}, error) {
+	labels, _, err := LabelService.List(ctx, ListLabelOption{
+		ResourceType: resourceType.Ptr(),
+		ResourceIds:  &resourceIds,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "list labels")
+	}
+	res := make(map[uint]struct{}, len(labels))
+	for _, label := range labels {
+		res[label.ResourceId] = struct{}{}
+	}
+	return res, nil
+}
+
+func (s *retentionService) ListPrunableBentos(ctx context.Context, bentoRepository *models.BentoRepository) ([]*models.Bento, error) {
+	res := make([]*models.Bento, 0)
+	if bentoRepository.RetentionPolicy.IsEmpty() {
+		return res, nil
+	}
+	bentos, _, err := BentoService.List(ctx, ListBentoOption{
+		BentoRepositoryId: &bentoRepository.ID,
+		Order:             utils.StringPtr("bento.created_at DESC"),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "list bentos")
+	}
+	if len(bentos) == 0 {
+		return res, nil
+	}
+	bentoIds := make([]uint, 0, len(bentos))
+	for _, bento := range bentos {
+		bentoIds = append(bentoIds, bento.ID)
+	}
+	labelledIds, err := s.listLabelledResourceIds(ctx, modelschemas.ResourceTypeBento, bentoIds)
+	if err != nil {
+		return nil, err
+	}
+	aliasNames, err := BentoAliasService.GroupNamesByBentoIds(ctx, bentoIds)
+	if err != nil {
+		return nil, errors.Wrap(err, "list bento aliases")
+	}
+	// the bentos in the revision history of a deployment cannot be deleted either
+	deploymentTargets, _, err := DeploymentTargetService.List(ctx, ListDeploymentTargetOption{
+		BentoIds: &bentoIds,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "list deployment targets")
+	}
+	deployedIds := make(map[uint]struct{}, len(deploymentTargets))
+	for _, deploymentTarget := range deploymentTargets {
+		deployedIds[deploymentTarget.BentoId] = struct{}{}
+	}
+	now := time.Now()
+	for idx, bento := range bentos {
+		if bentoRepository.RetentionPolicy.ShouldRetain(idx, bento.CreatedAt, now) {
+			continue
+		}
+		if _, ok := labelledIds[bento.ID]; ok {
+			continue
+		}
+		if _, ok := aliasNames[bento.ID]; ok {
+			continue
+		}
+		if _, ok := deployedIds[bento.ID]; ok {
+			continue
+		}
+		res = append(res, bento)
+	}
+	return res, nil
+}
+
+func (s *retentionService) ListPrunableModels(ctx context.Context, modelRepository *models.ModelRepository) ([]*models.Model, error) {
+	res := make([]*models.Model, 0)
+	if modelRepository.RetentionPolicy.IsEmpty() {
+		return res, nil
+	}
+	models_, _, err := ModelService.List(ctx, ListModelOption{
+		ModelRepositoryId: &modelRepository.ID,
+		Order:             utils.StringPtr("model.created_at DESC"),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "list models")
+	}
+	if len(models_) == 0 {
+		return res, nil
+	}
+	modelIds := make([]uint, 0, len(models_))
+	for _, model := range models_ {
+		modelIds = append(modelIds, model.ID)
+	}
+	labelledIds, err := s.listLabelledResourceIds(ctx, modelschemas.ResourceTypeModel, modelIds)
+	if err != nil {
+		return nil, err
+	}
+	aliasNames, err := ModelAliasService.GroupNamesByModelIds(ctx, modelIds)
+	if err != nil {
+		return nil, errors.Wrap(err, "list model aliases")
+	}
+	// models referenced by any bento are treated as deployed
+	usedIds := make([]uint, 0)
+	err = mustGetSession(ctx).Model(&models.BentoModelRel{}).Where("model_id in (?)", modelIds).Distinct().Pluck("model_id", &usedIds).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "list bento model rels")
+	}
+	deployedIds := make(map[uint]struct{}, len(usedIds))
+	for _, id := range usedIds {
+		deployedIds[id] = struct{}{}
+	}
+	now := time.Now()
+	for idx, model := range models_ {
+		if modelRepository.RetentionPolicy.ShouldRetain(idx, model.CreatedAt, now) {
+			continue
+		}
+		if _, ok := labelledIds[model.ID]; ok {
+			continue
+		}
+		if _, ok := aliasNames[model.ID]; ok {
+			continue
+		}
+		if _, ok := deployedIds[model.ID]; ok {
+			continue
+		}
+		res = append(res, model)
+	}
+	return res, nil
+}
+
+// withRetentionOperator makes sure there is a current user in ctx, the
+// repository creator is used when pruning is triggered by cron.
+func (s *retentionService) withRetentionOperator(ctx context.Context, creatorId uint) (context.Context, error) {
+	if _, err := GetCurrentUser(ctx); err == nil {
+		return ctx, nil
+	}
+	user, err := UserService.Get(ctx, creatorId)
+	if err != nil {
+		return nil, errors.Wrap(err, "get repository creator")
+	}
+	return context.WithValue(ctx, CurrentUserKey, user), nil // nolint: staticcheck
+}
+
+func (s *retentionService) PruneBentoRepository(ctx context.Context, bentoRepository *models.BentoRepository) ([]*models.Bento, error) {
+	bentos, err := s.ListPrunableBentos(ctx, bentoRepository)
+	if err != nil {
+		return nil, err
+	}
+	ctx, err = s.withRetentionOperator(ctx, bentoRepository.CreatorId)
+	if err != nil {
+		return nil, err
+	}
+	deleted := make([]*models.Bento, 0, len(bentos))
+	for _, bento := range bentos {
+		_, err = BentoService.Delete(ctx, bento)
+		if err != nil {
+			logrus.Errorf("prune bento %s:%s: %s", bentoRepository.Name, bento.Version, err.Error())
+			continue
+		}
+		deleted = append(deleted, bento)
+	}
+	return deleted, nil
+}
+
+func (s *retentionService) PruneModelRepository(ctx context.Context, modelRepository *models.ModelRepository) ([]*models.Model, error) {
+	models_, err := s.ListPrunableModels(ctx, modelRepository)
+	if err != nil {
+		return nil, err
+	}
+	ctx, err = s.withRetentionOperator(ctx, modelRepository.CreatorId)
+	if err != nil {
+		return nil, err
+	}
+	deleted := make([]*models.Model, 0, len(models_))
+	for _, model := range models_ {
+		_, err = ModelService.Delete(ctx, model)
+		if err != nil {
+			logrus.Errorf("prune model %s:%s: %s", modelRepository.Name, model.Version, err.Error())
+			continue
+		}
+		deleted = append(deleted, model)
+	}
+	return deleted, nil
+}
+
+func (s *retentionService) PruneAll(ctx context.Context) error {
+	bentoRepositories, _, err := BentoRepositoryService.List(ctx, ListBentoRepositoryOption{
+		HasRetentionPolicy: utils.BoolPtr(true),
+	})
+	if err != nil {
+		return errors.Wrap(err, "list bento repositories")
+	}
+	for _, bentoRepository := range bentoRepositories {
+		bentos, err := s.PruneBentoRepository(ctx, bentoRepository)
+		if err != nil {
+			logrus.Errorf("prune bento repository %s: %s", bentoRepository.Name, err.Error())
+			continue
+		}
+		if len(bentos) > 0 {
+			logrus.Infof("pruned %d bentos from bento repository %s", len(bentos), bentoRepository.Name)
+		}
+	}
+	modelRepositories, _, err := ModelRepositoryService.List(ctx, ListModelRepositoryOption{
+		HasRetentionPolicy: utils.BoolPtr(true),
+	})
+	if err != nil {
+		return errors.Wrap(err, "list model repositories")
+	}
+	for _, modelRepository := range modelRepositories {
+		models_, err := s.PruneModelRepository(ctx, modelRepository)
+		if err != nil {
+			logrus.Errorf("prune model repository %s: %s", modelRepository.Name, err.Error())
+			continue
+		}
+		if len(models_) > 0 {
+			logrus.Infof("pruned %d models from model repository %s", len(models_), modelRepository.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1" // nolint: gosec
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	totpPeriod       = 30
+	totpDigits       = 6
+	totpSecretLength = 20
+	// the codes of the adjacent periods are accepted for the clock drift of the authenticator apps
+	totpSkew = 1
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// totpService implements the time-based one-time passwords of RFC 6238 with the defaults of
+// the authenticator apps: HMAC-SHA1, 6 digits and 30 seconds periods.
+type totpService struct {
+	now func() time.Time
+}
+
+var TotpService = totpService{now: time.Now}
+
+func (s *totpService) GenerateSecret() (string, error) {
+	secret := make([]byte, totpSecretLength)
+	if _, err := rand.Read(secret); err != nil {
+		return "", errors.Wrap(err, "generate totp secret")
+	}
+	return totpEncoding.EncodeToString(secret), nil
+}
+
+// GetProvisioningUri returns the otpauth uri which the authenticator apps scan as a qr code
+func (s *totpService) GetProvisioningUri(issuer, accountName, secret string) string {
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprintf("%d", totpDigits))
+	query.Set("period", fmt.Sprintf("%d", totpPeriod))
+	label := url.PathEscape(fmt.Sprintf("%s:%s", issuer, accountName))
+	return fmt.Sprintf("otpauth://totp/%s?%s", label, query.Encode())
+}
+
+func (s *totpService) generateCode(secret string, step int64) (string, error) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(strings.TrimRight(secret, "=")))
+	if err != nil {
+		return "", errors.Wrap(err, "decode totp secret")
+	}
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg)
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0xf
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	mod := uint32(1)
+	for i := 0; i < totpDigits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", totpDigits, value%mod), nil
+}
+
+// Validate checks the code against the periods around now, the step of the matched period is returned,
+// the steps which are not after lastUsedStep are rejected so a code cannot be replayed.
+func (s *totpService) Validate(secret, code string, lastUsedStep int64) (int64, bool, error) {
+	code = strings.TrimSpace(code)
+	if len(code) != totpDigits {
+		return 0, false, nil
+	}
+	currentStep := s.now().Unix() / totpPeriod
+	for step := currentStep - totpSkew; step <= currentStep+totpSkew; step++ {
+		if step <= lastUsedStep {
+			continue
+		}
+		expected, err := s.generateCode(secret, step)
+		if err != nil {
+			return 0, false, err
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step, true, nil
+		}
+	}
+	return 0, false, nil
+}
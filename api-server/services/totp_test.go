@@ -0,0 +1,86 @@
+package services
+
+import (
+	"encoding/base32"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// the sha1 test vectors of RFC 6238, truncated to 6 digits
+var totpTestVectors = []struct {
+	unix int64
+	code string
+}{
+	{59, "287082"},
+	{1111111109, "081804"},
+	{1111111111, "050471"},
+	{1234567890, "005924"},
+	{2000000000, "279037"},
+}
+
+var totpTestSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestTotpGenerateCode(t *testing.T) {
+	s := totpService{now: time.Now}
+	for _, v := range totpTestVectors {
+		code, err := s.generateCode(totpTestSecret, v.unix/totpPeriod)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if code != v.code {
+			t.Errorf("code at %d: expected %s, got %s", v.unix, v.code, code)
+		}
+	}
+}
+
+func TestTotpValidate(t *testing.T) {
+	for _, v := range totpTestVectors {
+		s := totpService{now: func() time.Time { return time.Unix(v.unix, 0) }}
+		step, ok, err := s.Validate(totpTestSecret, v.code, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok || step != v.unix/totpPeriod {
+			t.Errorf("code %s at %d is not accepted", v.code, v.unix)
+		}
+		// the code cannot be replayed
+		if _, ok, _ = s.Validate(totpTestSecret, v.code, step); ok {
+			t.Errorf("code %s at %d is replayed", v.code, v.unix)
+		}
+	}
+
+	// the adjacent period is accepted for the clock drift, the further ones are not
+	s := totpService{now: func() time.Time { return time.Unix(1111111109+totpPeriod, 0) }}
+	if _, ok, _ := s.Validate(totpTestSecret, "081804", 0); !ok {
+		t.Error("code of the previous period is not accepted")
+	}
+	s = totpService{now: func() time.Time { return time.Unix(1111111109+3*totpPeriod, 0) }}
+	if _, ok, _ := s.Validate(totpTestSecret, "081804", 0); ok {
+		t.Error("code of an expired period is accepted")
+	}
+
+	for _, code := range []string{"", "12345", "1234567", "abcdef"} {
+		if _, ok, _ := s.Validate(totpTestSecret, code, 0); ok {
+			t.Errorf("invalid code %q is accepted", code)
+		}
+	}
+}
+
+func TestTotpProvisioningUri(t *testing.T) {
+	s := totpService{now: time.Now}
+	secret, err := s.GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	uri, err := url.Parse(s.GetProvisioningUri("Yatai", "alice@example.com", secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uri.Scheme != "otpauth" || uri.Host != "totp" || uri.Path != "/Yatai:alice@example.com" {
+		t.Errorf("unexpected provisioning uri %s", uri)
+	}
+	if uri.Query().Get("secret") != secret || uri.Query().Get("issuer") != "Yatai" {
+		t.Errorf("unexpected provisioning uri query %s", uri.RawQuery)
+	}
+}
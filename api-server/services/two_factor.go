@@ -0,0 +1,286 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	jujuerrors "github.com/juju/errors"
+	"github.com/pkg/errors"
+	"gorm.io/gorm/clause"
+
+	"github.com/bentoml/yatai-schemas/modelschemas"
+	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/common/utils"
+)
+
+const (
+	twoFactorIssuer            = "Yatai"
+	twoFactorRecoveryCodeCount = 10
+	twoFactorRecoveryCodeSize  = 10
+	// the verification is locked for a while after too many wrong codes, so the codes cannot be guessed
+	twoFactorMaxFailedAttempts = 5
+	twoFactorLockDuration      = 5 * time.Minute
+)
+
+var ErrInvalidTwoFactorCode = errors.New("invalid two-factor authentication code")
+
+type twoFactorService struct{}
+
+var TwoFactorService = twoFactorService{}
+
+type TwoFactorEnrollment struct {
+	Secret          string
+	ProvisioningUri string
+}
+
+// Enroll generates a new secret for the user, it is enabled after the first code is verified by Enable
+func (s *twoFactorService) Enroll(ctx context.Context, user *models.User) (*TwoFactorEnrollment, error) {
+	if user.TotpEnabled {
+		return nil, errors.New("the two-factor authentication is already enabled")
+	}
+	secret, err := TotpService.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	err = UserService.getBaseDB(ctx).Where("id = ?", user.ID).Updates(map[string]interface{}{
+		"totp_secret": secret,
+	}).Error
+	if err != nil {
+		return nil, err
+	}
+	user.TotpSecret = &secret
+	accountName := user.Name
+	if user.Email != nil && *user.Email != "" {
+		accountName = *user.Email
+	}
+	return &TwoFactorEnrollment{
+		Secret:          secret,
+		ProvisioningUri: TotpService.GetProvisioningUri(twoFactorIssuer, accountName, secret),
+	}, nil
+}
+
+// Enable turns on the two-factor authentication with the first code of the enrolled secret,
+// the plaintext recovery codes are only returned here.
+func (s *twoFactorService) Enable(ctx context.Context, user *models.User, code string) ([]string, error) {
+	if user.TotpEnabled {
+		return nil, errors.New("the two-factor authentication is already enabled")
+	}
+	if user.TotpSecret == nil {
+		return nil, errors.New("the two-factor authentication is not enrolled")
+	}
+	step, ok, err := TotpService.Validate(*user.TotpSecret, code, user.TotpLastUsedStep)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+	recoveryCodes, recoveryCodeHashes := s.generateRecoveryCodes()
+	// the secret is checked again by the update, so a parallel enroll or enable cannot be overwritten
+	db := UserService.getBaseDB(ctx).Where("id = ?", user.ID).Where("totp_enabled = ?", false).Where("totp_secret = ?", *user.TotpSecret).Updates(map[string]interface{}{
+		"totp_enabled":         true,
+		"totp_recovery_codes":  &recoveryCodeHashes,
+		"totp_last_used_step":  step,
+		"totp_failed_attempts": 0,
+	})
+	if db.Error != nil {
+		return nil, db.Error
+	}
+	if db.RowsAffected == 0 {
+		return nil, errors.New("the two-factor authentication is changed by another request, please enroll again")
+	}
+	user.TotpEnabled = true
+	user.TotpRecoveryCodes = &recoveryCodeHashes
+	user.TotpLastUsedStep = step
+	user.TotpFailedAttempts = 0
+	return recoveryCodes, nil
+}
+
+// Disable turns off the two-factor authentication, a valid code is required
+func (s *twoFactorService) Disable(ctx context.Context, user *models.User, code string) error {
+	if err := s.Verify(ctx, user, code); err != nil {
+		return err
+	}
+	err := UserService.getBaseDB(ctx).Where("id = ?", user.ID).Updates(map[string]interface{}{
+		"totp_secret":         nil,
+		"totp_enabled":        false,
+		"totp_recovery_codes": nil,
+		"totp_last_used_step": 0,
+	}).Error
+	if err != nil {
+		return err
+	}
+	user.TotpSecret = nil
+	user.TotpEnabled = false
+	user.TotpRecoveryCodes = nil
+	user.TotpLastUsedStep = 0
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes of the user, a valid code is required
+func (s *twoFactorService) RegenerateRecoveryCodes(ctx context.Context, user *models.User, code string) ([]string, error) {
+	if err := s.Verify(ctx, user, code); err != nil {
+		return nil, err
+	}
+	recoveryCodes, recoveryCodeHashes := s.generateRecoveryCodes()
+	err := UserService.getBaseDB(ctx).Where("id = ?", user.ID).Updates(map[string]interface{}{
+		"totp_recovery_codes": &recoveryCodeHashes,
+	}).Error
+	if err != nil {
+		return nil, err
+	}
+	user.TotpRecoveryCodes = &recoveryCodeHashes
+	return recoveryCodes, nil
+}
+
+// Verify accepts a totp code or an unused recovery code of the user, the recovery code is consumed
+func (s *twoFactorService) Verify(ctx context.Context, user *models.User, code string) error {
+	ok, err := s.verify(ctx, user, code)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidTwoFactorCode
+	}
+	return nil
+}
+
+// verify runs with the user row locked, so the parallel requests cannot share the failed attempts
+// or replay the same code, the failed attempt is committed with the transaction as well.
+func (s *twoFactorService) verify(ctx context.Context, user *models.User, code string) (ok bool, err error) {
+	_, ctx, df, err := startTransaction(ctx)
+	if err != nil {
+		return
+	}
+	defer func() { df(err) }()
+
+	var lockedUser models.User
+	err = mustGetSession(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", user.ID).First(&lockedUser).Error
+	if err != nil {
+		err = errors.Wrap(err, "lock user")
+		return
+	}
+	defer func() {
+		user.TotpSecret = lockedUser.TotpSecret
+		user.TotpEnabled = lockedUser.TotpEnabled
+		user.TotpRecoveryCodes = lockedUser.TotpRecoveryCodes
+		user.TotpLastUsedStep = lockedUser.TotpLastUsedStep
+		user.TotpFailedAttempts = lockedUser.TotpFailedAttempts
+		user.TotpLastFailedAt = lockedUser.TotpLastFailedAt
+	}()
+
+	if !lockedUser.TotpEnabled || lockedUser.TotpSecret == nil {
+		err = errors.New("the two-factor authentication is not enabled")
+		return
+	}
+	if lockedUser.TotpFailedAttempts >= twoFactorMaxFailedAttempts && lockedUser.TotpLastFailedAt != nil && time.Since(*lockedUser.TotpLastFailedAt) < twoFactorLockDuration {
+		err = jujuerrors.Forbiddenf("too many invalid two-factor authentication codes, please try again later")
+		return
+	}
+
+	updaters := make(map[string]interface{})
+	step, ok, err := TotpService.Validate(*lockedUser.TotpSecret, code, lockedUser.TotpLastUsedStep)
+	if err != nil {
+		return
+	}
+	var recoveryCodeHashes models.UserTotpRecoveryCodes
+	if ok {
+		updaters["totp_last_used_step"] = step
+	} else {
+		recoveryCodeHashes, ok = s.consumeRecoveryCode(&lockedUser, code)
+		if ok {
+			updaters["totp_recovery_codes"] = &recoveryCodeHashes
+		}
+	}
+
+	if !ok {
+		now := time.Now()
+		err = UserService.getBaseDB(ctx).Where("id = ?", lockedUser.ID).Updates(map[string]interface{}{
+			"totp_failed_attempts": lockedUser.TotpFailedAttempts + 1,
+			"totp_last_failed_at":  now,
+		}).Error
+		if err != nil {
+			return
+		}
+		lockedUser.TotpFailedAttempts++
+		lockedUser.TotpLastFailedAt = &now
+		return
+	}
+
+	updaters["totp_failed_attempts"] = 0
+	err = UserService.getBaseDB(ctx).Where("id = ?", lockedUser.ID).Updates(updaters).Error
+	if err != nil {
+		return
+	}
+	if _, ok_ := updaters["totp_last_used_step"]; ok_ {
+		lockedUser.TotpLastUsedStep = step
+	} else {
+		lockedUser.TotpRecoveryCodes = &recoveryCodeHashes
+	}
+	lockedUser.TotpFailedAttempts = 0
+	return
+}
+
+// IsRequired tells whether the organization requires the two-factor authentication for the role of the user
+func (s *twoFactorService) IsRequired(ctx context.Context, user *models.User, org *models.Organization) (bool, error) {
+	if org.TwoFactorRequiredRole == "" {
+		return false, nil
+	}
+	role := modelschemas.MemberRoleAdmin
+	if org.CreatorId != user.ID {
+		var err error
+		role, err = OrganizationMemberService.GetEffectiveRole(ctx, user.ID, org.ID)
+		if err != nil {
+			return false, errors.Wrap(err, "get organization role")
+		}
+	}
+	if role == "" {
+		return false, nil
+	}
+	return memberRoleRanks[role] >= memberRoleRanks[org.TwoFactorRequiredRole], nil
+}
+
+func (s *twoFactorService) normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.ReplaceAll(code, "-", "")
+}
+
+func (s *twoFactorService) hashRecoveryCode(code string) string {
+	sum := sha256.Sum256([]byte(s.normalizeRecoveryCode(code)))
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *twoFactorService) generateRecoveryCodes() ([]string, models.UserTotpRecoveryCodes) {
+	codes := make([]string, 0, twoFactorRecoveryCodeCount)
+	hashes := make(models.UserTotpRecoveryCodes, 0, twoFactorRecoveryCodeCount)
+	for i := 0; i < twoFactorRecoveryCodeCount; i++ {
+		code := strings.ToLower(utils.RandAlphanumString(twoFactorRecoveryCodeSize))
+		code = fmt.Sprintf("%s-%s", code[:twoFactorRecoveryCodeSize/2], code[twoFactorRecoveryCodeSize/2:])
+		codes = append(codes, code)
+		hashes = append(hashes, s.hashRecoveryCode(code))
+	}
+	return codes, hashes
+}
+
+// consumeRecoveryCode returns the remaining recovery codes if the code is one of them
+func (s *twoFactorService) consumeRecoveryCode(user *models.User, code string) (models.UserTotpRecoveryCodes, bool) {
+	if user.TotpRecoveryCodes == nil || s.normalizeRecoveryCode(code) == "" {
+		return nil, false
+	}
+	hash := s.hashRecoveryCode(code)
+	remaining := make(models.UserTotpRecoveryCodes, 0, len(*user.TotpRecoveryCodes))
+	found := false
+	for _, recoveryCodeHash := range *user.TotpRecoveryCodes {
+		if !found && subtle.ConstantTimeCompare([]byte(recoveryCodeHash), []byte(hash)) == 1 {
+			found = true
+			continue
+		}
+		remaining = append(remaining, recoveryCodeHash)
+	}
+	return remaining, found
+}
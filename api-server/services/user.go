@@ -34,19 +34,28 @@ type CreateUserOption struct {
 	Email     *string
 	Password  string
 	Perm      *modelschemas.UserPerm
+	// the idp account of the users created by sso
+	OidcIssuer  *string
+	OidcSubject *string
+	// the directory entry of the users created by ldap
+	LdapDn *string
 }
 
 type UpdateUserOption struct {
-	Config    **models.UserConfig
-	Email     **string
-	Name      *string
-	FirstName *string
-	LastName  *string
+	Config      **models.UserConfig
+	Email       **string
+	Name        *string
+	FirstName   *string
+	LastName    *string
+	OidcIssuer  **string
+	OidcSubject **string
+	LdapDn      **string
 }
 
 type ListUserOption struct {
 	BaseListOption
 	Perm  *modelschemas.UserPerm
+	Ids   *[]uint
 	Order *string
 }
 
@@ -59,11 +68,14 @@ func (s *userService) Create(ctx context.Context, opt CreateUserOption) (*models
 		ResourceMixin: models.ResourceMixin{
 			Name: opt.Name,
 		},
-		FirstName: opt.FirstName,
-		LastName:  opt.LastName,
-		Email:     opt.Email,
-		Password:  string(hashedPassword),
-		Perm:      modelschemas.UserPermDefault,
+		FirstName:   opt.FirstName,
+		LastName:    opt.LastName,
+		Email:       opt.Email,
+		Password:    string(hashedPassword),
+		Perm:        modelschemas.UserPermDefault,
+		OidcIssuer:  opt.OidcIssuer,
+		OidcSubject: opt.OidcSubject,
+		LdapDn:      opt.LdapDn,
 	}
 	if opt.Perm != nil {
 		user.Perm = *opt.Perm
@@ -129,6 +141,30 @@ func (s *userService) Update(ctx context.Context, u *models.User, opt UpdateUser
 			}
 		}()
 	}
+	if opt.OidcIssuer != nil {
+		updaters["oidc_issuer"] = *opt.OidcIssuer
+		defer func() {
+			if err == nil {
+				u.OidcIssuer = *opt.OidcIssuer
+			}
+		}()
+	}
+	if opt.OidcSubject != nil {
+		updaters["oidc_subject"] = *opt.OidcSubject
+		defer func() {
+			if err == nil {
+				u.OidcSubject = *opt.OidcSubject
+			}
+		}()
+	}
+	if opt.LdapDn != nil {
+		updaters["ldap_dn"] = *opt.LdapDn
+		defer func() {
+			if err == nil {
+				u.LdapDn = *opt.LdapDn
+			}
+		}()
+	}
 	if len(updaters) == 0 {
 		return u, nil
 	}
@@ -152,7 +188,15 @@ func (s *userService) ForceUpdatePassword(ctx context.Context, u *models.User, n
 	err = s.getBaseDB(ctx).Where("id = ?", u.ID).Updates(map[string]interface{}{
 		"password": hashedPassword,
 	}).Error
-	return u, err
+	if err != nil {
+		return nil, err
+	}
+	// the sessions which were logged in with the old password are expired
+	err = UserSessionService.DeleteByUser(ctx, u.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "delete user sessions")
+	}
+	return u, nil
 }
 
 func (s *userService) CheckPassword(ctx context.Context, u *models.User, password string) error {
@@ -210,6 +254,24 @@ func (*userService) GetByName(ctx context.Context, name string) (*models.User, e
 	return &user, nil
 }
 
+func (*userService) GetByOidcSubject(ctx context.Context, issuer, subject string) (*models.User, error) {
+	var user models.User
+	err := mustGetSession(ctx).Where("oidc_issuer = ?", issuer).Where("oidc_subject = ?", subject).First(&user).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (*userService) GetByLdapDn(ctx context.Context, dn string) (*models.User, error) {
+	var user models.User
+	err := mustGetSession(ctx).Where("ldap_dn = ?", dn).First(&user).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
 func (*userService) GetByEmail(ctx context.Context, email string) (*models.User, error) {
 	var user models.User
 	err := mustGetSession(ctx).Where("email = ?", email).First(&user).Error
@@ -257,6 +319,12 @@ func (s *userService) List(ctx context.Context, opt ListUserOption) ([]*models.U
 	if opt.Perm != nil {
 		query = query.Where("perm = ?", *opt.Perm)
 	}
+	if opt.Ids != nil {
+		if len(*opt.Ids) == 0 {
+			return make([]*models.User, 0), 0, nil
+		}
+		query = query.Where("id in (?)", *opt.Ids)
+	}
 	var total int64
 	err := query.Count(&total).Error
 	if err != nil {
@@ -0,0 +1,246 @@
+package services
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/common/consts"
+)
+
+type userGroupService struct{}
+
+var UserGroupService = userGroupService{}
+
+func (*userGroupService) getBaseDB(ctx context.Context) *gorm.DB {
+	return mustGetSession(ctx).Model(&models.UserGroup{})
+}
+
+type CreateUserGroupOption struct {
+	CreatorId      uint
+	OrganizationId uint
+	Name           string
+	Description    string
+}
+
+type UpdateUserGroupOption struct {
+	Name        *string
+	Description *string
+}
+
+type ListUserGroupOption struct {
+	BaseListOption
+	OrganizationId *uint
+	UserId         *uint
+	Ids            *[]uint
+	Order          *string
+}
+
+func (s *userGroupService) Create(ctx context.Context, opt CreateUserGroupOption) (*models.UserGroup, error) {
+	errs := validation.IsDNS1035Label(opt.Name)
+	if len(errs) > 0 {
+		return nil, errors.New(strings.Join(errs, ";"))
+	}
+
+	userGroup := models.UserGroup{
+		ResourceMixin: models.ResourceMixin{
+			Name: opt.Name,
+		},
+		OrganizationAssociate: models.OrganizationAssociate{
+			OrganizationId: opt.OrganizationId,
+		},
+		CreatorAssociate: models.CreatorAssociate{
+			CreatorId: opt.CreatorId,
+		},
+		Description: opt.Description,
+	}
+	err := mustGetSession(ctx).Create(&userGroup).Error
+	if err != nil {
+		return nil, err
+	}
+	return &userGroup, nil
+}
+
+func (s *userGroupService) Update(ctx context.Context, g *models.UserGroup, opt UpdateUserGroupOption) (*models.UserGroup, error) {
+	var err error
+	updaters := make(map[string]interface{})
+	if opt.Name != nil {
+		errs := validation.IsDNS1035Label(*opt.Name)
+		if len(errs) > 0 {
+			return nil, errors.New(strings.Join(errs, ";"))
+		}
+		updaters["name"] = *opt.Name
+		defer func() {
+			if err == nil {
+				g.Name = *opt.Name
+			}
+		}()
+	}
+	if opt.Description != nil {
+		updaters["description"] = *opt.Description
+		defer func() {
+			if err == nil {
+				g.Description = *opt.Description
+			}
+		}()
+	}
+
+	if len(updaters) == 0 {
+		return g, nil
+	}
+
+	err = s.getBaseDB(ctx).Where("id = ?", g.ID).Updates(updaters).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return g, err
+}
+
+func (s *userGroupService) Get(ctx context.Context, id uint) (*models.UserGroup, error) {
+	var userGroup models.UserGroup
+	err := getBaseQuery(ctx, s).Where("id = ?", id).First(&userGroup).Error
+	if err != nil {
+		return nil, err
+	}
+	if userGroup.ID == 0 {
+		return nil, consts.ErrNotFound
+	}
+	return &userGroup, nil
+}
+
+func (s *userGroupService) GetByUid(ctx context.Context, uid string) (*models.UserGroup, error) {
+	var userGroup models.UserGroup
+	err := getBaseQuery(ctx, s).Where("uid = ?", uid).First(&userGroup).Error
+	if err != nil {
+		return nil, err
+	}
+	if userGroup.ID == 0 {
+		return nil, consts.ErrNotFound
+	}
+	return &userGroup, nil
+}
+
+func (s *userGroupService) GetByName(ctx context.Context, organizationId uint, name string) (*models.UserGroup, error) {
+	var userGroup models.UserGroup
+	err := getBaseQuery(ctx, s).Where("organization_id = ?", organizationId).Where("name = ?", name).First(&userGroup).Error
+	if err != nil {
+		return nil, errors.Wrapf(err, "get user group %s", name)
+	}
+	if userGroup.ID == 0 {
+		return nil, consts.ErrNotFound
+	}
+	return &userGroup, nil
+}
+
+func (s *userGroupService) List(ctx context.Context, opt ListUserGroupOption) ([]*models.UserGroup, uint, error) {
+	userGroups := make([]*models.UserGroup, 0)
+	query := getBaseQuery(ctx, s)
+	if opt.OrganizationId != nil {
+		query = query.Where("organization_id = ?", *opt.OrganizationId)
+	}
+	if opt.UserId != nil {
+		userGroupIds, err := s.ListUserGroupIds(ctx, *opt.UserId)
+		if err != nil {
+			return nil, 0, errors.Wrap(err, "list user group ids")
+		}
+		if len(userGroupIds) == 0 {
+			return userGroups, 0, nil
+		}
+		query = query.Where("id in (?)", userGroupIds)
+	}
+	if opt.Ids != nil {
+		if len(*opt.Ids) == 0 {
+			return userGroups, 0, nil
+		}
+		query = query.Where("id in (?)", *opt.Ids)
+	}
+	query = opt.BindQueryWithKeywords(query, "user_group")
+	var total int64
+	err := query.Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	query = opt.BindQueryWithLimit(query)
+	if opt.Order == nil {
+		query = query.Order("id DESC")
+	} else {
+		query = query.Order(*opt.Order)
+	}
+	err = query.Find(&userGroups).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return userGroups, uint(total), err
+}
+
+func (s *userGroupService) Delete(ctx context.Context, g *models.UserGroup) (*models.UserGroup, error) {
+	// the hard delete cascades to the users and the grants of the group
+	err := s.getBaseDB(ctx).Unscoped().Delete(g).Error
+	return g, err
+}
+
+func (s *userGroupService) AddUser(ctx context.Context, g *models.UserGroup, userId, creatorId uint) error {
+	relation := &models.UserGroupUserRelation{
+		UserGroupAssociate: models.UserGroupAssociate{
+			UserGroupId: g.ID,
+		},
+		UserAssociate: models.UserAssociate{
+			UserId: userId,
+		},
+		CreatorAssociate: models.CreatorAssociate{
+			CreatorId: creatorId,
+		},
+	}
+	return mustGetSession(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_group_id"}, {Name: "user_id"}},
+		DoNothing: true,
+	}).Create(relation).Error
+}
+
+func (s *userGroupService) RemoveUser(ctx context.Context, g *models.UserGroup, userId uint) error {
+	return mustGetSession(ctx).Unscoped().Where("user_group_id = ?", g.ID).Where("user_id = ?", userId).Delete(&models.UserGroupUserRelation{}).Error
+}
+
+func (s *userGroupService) ListUsers(ctx context.Context, g *models.UserGroup) ([]*models.User, error) {
+	userIds := make([]uint, 0)
+	err := mustGetSession(ctx).Model(&models.UserGroupUserRelation{}).Where("user_group_id = ?", g.ID).Pluck("user_id", &userIds).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(userIds) == 0 {
+		return make([]*models.User, 0), nil
+	}
+	users, _, err := UserService.List(ctx, ListUserOption{
+		Ids: &userIds,
+	})
+	return users, err
+}
+
+// ListUserGroupIds returns the ids of the groups which the user belongs to
+func (s *userGroupService) ListUserGroupIds(ctx context.Context, userId uint) ([]uint, error) {
+	res := make([]uint, 0)
+	err := mustGetSession(ctx).Model(&models.UserGroupUserRelation{}).Where("user_id = ?", userId).Pluck("user_group_id", &res).Error
+	return res, err
+}
+
+type IUserGroupAssociate interface {
+	GetAssociatedUserGroupId() uint
+	GetAssociatedUserGroupCache() *models.UserGroup
+	SetAssociatedUserGroupCache(userGroup *models.UserGroup)
+}
+
+func (s *userGroupService) GetAssociatedUserGroup(ctx context.Context, associate IUserGroupAssociate) (*models.UserGroup, error) {
+	cache := associate.GetAssociatedUserGroupCache()
+	if cache != nil {
+		return cache, nil
+	}
+	userGroup, err := s.Get(ctx, associate.GetAssociatedUserGroupId())
+	associate.SetAssociatedUserGroupCache(userGroup)
+	return userGroup, err
+}
@@ -0,0 +1,145 @@
+package services
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+
+	"github.com/bentoml/yatai-schemas/modelschemas"
+	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/common/consts"
+	"github.com/bentoml/yatai/common/utils"
+)
+
+type userGroupGrantService struct{}
+
+var UserGroupGrantService = userGroupGrantService{}
+
+func (*userGroupGrantService) getBaseDB(ctx context.Context) *gorm.DB {
+	return mustGetSession(ctx).Model(&models.UserGroupGrant{})
+}
+
+type CreateUserGroupGrantOption struct {
+	CreatorId   uint
+	UserGroupId uint
+	Resource    models.IResource
+	Role        modelschemas.MemberRole
+}
+
+type ListUserGroupGrantOption struct {
+	UserGroupId  *uint
+	ResourceType *modelschemas.ResourceType
+	ResourceId   *uint
+}
+
+// Create grants the role on the resource to the group, the role of an existing grant is replaced
+func (s *userGroupGrantService) Create(ctx context.Context, opt CreateUserGroupGrantOption) (*models.UserGroupGrant, error) {
+	if _, ok := memberRoleRanks[opt.Role]; !ok {
+		return nil, errors.Errorf("invalid member role %s", opt.Role)
+	}
+	switch opt.Resource.GetResourceType() {
+	case modelschemas.ResourceTypeOrganization, modelschemas.ResourceTypeCluster, modelschemas.ResourceTypeBentoRepository, modelschemas.ResourceTypeModelRepository:
+	default:
+		return nil, errors.Errorf("cannot grant a role on the %s", opt.Resource.GetResourceType())
+	}
+	oldGrant, err := s.GetBy(ctx, opt.UserGroupId, opt.Resource.GetResourceType(), opt.Resource.GetId())
+	if err != nil && !utils.IsNotFound(err) {
+		return nil, err
+	}
+	if err == nil {
+		err = s.getBaseDB(ctx).Where("id = ?", oldGrant.ID).Updates(map[string]interface{}{
+			"role": opt.Role,
+		}).Error
+		if err != nil {
+			return nil, err
+		}
+		oldGrant.Role = opt.Role
+		return oldGrant, nil
+	}
+	grant := &models.UserGroupGrant{
+		CreatorAssociate: models.CreatorAssociate{
+			CreatorId: opt.CreatorId,
+		},
+		UserGroupAssociate: models.UserGroupAssociate{
+			UserGroupId: opt.UserGroupId,
+		},
+		ResourceType: opt.Resource.GetResourceType(),
+		ResourceId:   opt.Resource.GetId(),
+		Role:         opt.Role,
+	}
+	err = mustGetSession(ctx).Create(grant).Error
+	if err != nil {
+		return nil, err
+	}
+	return grant, nil
+}
+
+func (s *userGroupGrantService) GetByUid(ctx context.Context, uid string) (*models.UserGroupGrant, error) {
+	var grant models.UserGroupGrant
+	err := getBaseQuery(ctx, s).Where("uid = ?", uid).First(&grant).Error
+	if err != nil {
+		return nil, err
+	}
+	if grant.ID == 0 {
+		return nil, consts.ErrNotFound
+	}
+	return &grant, nil
+}
+
+func (s *userGroupGrantService) GetBy(ctx context.Context, userGroupId uint, resourceType modelschemas.ResourceType, resourceId uint) (*models.UserGroupGrant, error) {
+	var grant models.UserGroupGrant
+	err := getBaseQuery(ctx, s).Where("user_group_id = ?", userGroupId).Where("resource_type = ?", resourceType).Where("resource_id = ?", resourceId).First(&grant).Error
+	if err != nil {
+		return nil, err
+	}
+	if grant.ID == 0 {
+		return nil, consts.ErrNotFound
+	}
+	return &grant, nil
+}
+
+func (s *userGroupGrantService) List(ctx context.Context, opt ListUserGroupGrantOption) ([]*models.UserGroupGrant, error) {
+	grants := make([]*models.UserGroupGrant, 0)
+	query := getBaseQuery(ctx, s)
+	if opt.UserGroupId != nil {
+		query = query.Where("user_group_id = ?", *opt.UserGroupId)
+	}
+	if opt.ResourceType != nil {
+		query = query.Where("resource_type = ?", *opt.ResourceType)
+	}
+	if opt.ResourceId != nil {
+		query = query.Where("resource_id = ?", *opt.ResourceId)
+	}
+	err := query.Order("id DESC").Find(&grants).Error
+	return grants, err
+}
+
+func (s *userGroupGrantService) Delete(ctx context.Context, grant *models.UserGroupGrant) (*models.UserGroupGrant, error) {
+	err := s.getBaseDB(ctx).Unscoped().Delete(grant).Error
+	return grant, err
+}
+
+func (s *userGroupGrantService) DeleteByResource(ctx context.Context, resource models.IResource) error {
+	return s.getBaseDB(ctx).Unscoped().Where("resource_type = ?", resource.GetResourceType()).Where("resource_id = ?", resource.GetId()).Delete(&models.UserGroupGrant{}).Error
+}
+
+// getUserGrantsQuery selects the grants of all the groups which the user belongs to
+func (s *userGroupGrantService) getUserGrantsQuery(ctx context.Context, userId uint, resourceType modelschemas.ResourceType) *gorm.DB {
+	userGroupIds := mustGetSession(ctx).Model(&models.UserGroupUserRelation{}).Select("user_group_id").Where("user_id = ?", userId)
+	return s.getBaseDB(ctx).Where("resource_type = ?", resourceType).Where("user_group_id in (?)", userGroupIds)
+}
+
+// ListUserRoles returns the roles on the resource which the groups of the user are granted
+func (s *userGroupGrantService) ListUserRoles(ctx context.Context, userId uint, resourceType modelschemas.ResourceType, resourceId uint) ([]modelschemas.MemberRole, error) {
+	res := make([]modelschemas.MemberRole, 0)
+	err := s.getUserGrantsQuery(ctx, userId, resourceType).Where("resource_id = ?", resourceId).Pluck("role", &res).Error
+	return res, err
+}
+
+// ListUserResourceIds returns the ids of the resources which the groups of the user are granted a role on
+func (s *userGroupGrantService) ListUserResourceIds(ctx context.Context, userId uint, resourceType modelschemas.ResourceType) ([]uint, error) {
+	res := make([]uint, 0)
+	err := s.getUserGrantsQuery(ctx, userId, resourceType).Distinct("resource_id").Pluck("resource_id", &res).Error
+	return res, err
+}
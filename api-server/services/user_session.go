@@ -0,0 +1,196 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+
+	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/common/consts"
+	"github.com/bentoml/yatai/common/scookie"
+	"github.com/bentoml/yatai/common/utils"
+	"github.com/bentoml/yatai/common/yataicontext"
+)
+
+const CurrentUserSessionKey = "currentUserSession"
+
+const (
+	userSessionSecretLength = 32
+	// the sessions which are not used for this long are expired
+	userSessionIdleTimeout = time.Hour * 24 * 30
+	// the last seen time is refreshed at most once in this interval, so not every request writes the session
+	userSessionTouchInterval = time.Minute
+)
+
+type userSessionService struct{}
+
+var UserSessionService = userSessionService{}
+
+func (*userSessionService) getBaseDB(ctx context.Context) *gorm.DB {
+	return mustGetSession(ctx).Model(&models.UserSession{})
+}
+
+type CreateUserSessionOption struct {
+	UserId    uint
+	ClientIp  string
+	UserAgent string
+}
+
+type ListUserSessionOption struct {
+	UserId *uint
+}
+
+func (s *userSessionService) hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// Create returns the session and its key, the key is only known by the cookie
+func (s *userSessionService) Create(ctx context.Context, opt CreateUserSessionOption) (*models.UserSession, string, error) {
+	secret := utils.RandAlphanumString(userSessionSecretLength)
+	session := models.UserSession{
+		UserAssociate: models.UserAssociate{
+			UserId: opt.UserId,
+		},
+		SecretHash: s.hashSecret(secret),
+		ClientIp:   opt.ClientIp,
+		UserAgent:  opt.UserAgent,
+		LastSeenAt: time.Now(),
+	}
+	err := mustGetSession(ctx).Create(&session).Error
+	if err != nil {
+		return nil, "", err
+	}
+	return &session, fmt.Sprintf("%s.%s", session.Uid, secret), nil
+}
+
+func (s *userSessionService) GetByUid(ctx context.Context, uid string) (*models.UserSession, error) {
+	var session models.UserSession
+	err := getBaseQuery(ctx, s).Where("uid = ?", uid).First(&session).Error
+	if err != nil {
+		return nil, err
+	}
+	if session.ID == 0 {
+		return nil, consts.ErrNotFound
+	}
+	return &session, nil
+}
+
+// GetByKey returns the session of the key in the cookie, the expired sessions are rejected
+func (s *userSessionService) GetByKey(ctx context.Context, key string) (*models.UserSession, error) {
+	uid, secret, ok := strings.Cut(key, ".")
+	if !ok {
+		return nil, errors.New("invalid session key")
+	}
+	session, err := s.GetByUid(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(session.SecretHash), []byte(s.hashSecret(secret))) != 1 {
+		return nil, errors.New("invalid session key")
+	}
+	if s.IsExpired(session) {
+		return nil, errors.New("the session is expired")
+	}
+	return session, nil
+}
+
+func (s *userSessionService) IsExpired(session *models.UserSession) bool {
+	return time.Since(session.LastSeenAt) > userSessionIdleTimeout
+}
+
+// Touch records the last time and the client which the session was seen from
+func (s *userSessionService) Touch(ctx context.Context, session *models.UserSession, clientIp, userAgent string) error {
+	if time.Since(session.LastSeenAt) < userSessionTouchInterval && session.ClientIp == clientIp && session.UserAgent == userAgent {
+		return nil
+	}
+	now := time.Now()
+	err := s.getBaseDB(ctx).Where("id = ?", session.ID).Updates(map[string]interface{}{
+		"last_seen_at": now,
+		"client_ip":    clientIp,
+		"user_agent":   userAgent,
+	}).Error
+	if err != nil {
+		return err
+	}
+	session.LastSeenAt = now
+	session.ClientIp = clientIp
+	session.UserAgent = userAgent
+	return nil
+}
+
+// List returns the sessions which are not expired
+func (s *userSessionService) List(ctx context.Context, opt ListUserSessionOption) ([]*models.UserSession, error) {
+	sessions := make([]*models.UserSession, 0)
+	query := getBaseQuery(ctx, s).Where("last_seen_at > ?", time.Now().Add(-userSessionIdleTimeout))
+	if opt.UserId != nil {
+		query = query.Where("user_id = ?", *opt.UserId)
+	}
+	err := query.Order("last_seen_at DESC").Find(&sessions).Error
+	return sessions, err
+}
+
+func (s *userSessionService) Delete(ctx context.Context, session *models.UserSession) (*models.UserSession, error) {
+	err := s.getBaseDB(ctx).Unscoped().Delete(session).Error
+	return session, err
+}
+
+// DeleteByUser revokes all sessions of the user
+func (s *userSessionService) DeleteByUser(ctx context.Context, userId uint) error {
+	return s.getBaseDB(ctx).Unscoped().Where("user_id = ?", userId).Delete(&models.UserSession{}).Error
+}
+
+// Login starts a new session of the user and stores its key in the cookie
+func (s *userSessionService) Login(ctx *gin.Context, user *models.User) error {
+	_, key, err := s.Create(ctx, CreateUserSessionOption{
+		UserId:    user.ID,
+		ClientIp:  yataicontext.GetClientIp(ctx),
+		UserAgent: ctx.Request.UserAgent(),
+	})
+	if err != nil {
+		return errors.Wrap(err, "create user session")
+	}
+	return scookie.SetSessionKeyToCookie(ctx, key)
+}
+
+// Logout revokes the session in the cookie and clears the cookie
+func (s *userSessionService) Logout(ctx *gin.Context) error {
+	key := scookie.GetSessionKeyFromCookie(ctx)
+	if key != "" {
+		session, err := s.GetByKey(ctx, key)
+		if err == nil {
+			_, err = s.Delete(ctx, session)
+			if err != nil {
+				return errors.Wrap(err, "delete user session")
+			}
+		}
+	}
+	return scookie.DeleteSessionKeyFromCookie(ctx)
+}
+
+func SetCurrentUserSession(ctx *gin.Context, session *models.UserSession) {
+	if session == nil {
+		return
+	}
+	ctx.Set(CurrentUserSessionKey, session)
+}
+
+func GetCurrentUserSession(ctx context.Context) (*models.UserSession, error) {
+	session_ := ctx.Value(CurrentUserSessionKey)
+	if session_ == nil {
+		return nil, errors.Wrap(consts.ErrNotFound, "cannot find current user session")
+	}
+	session, ok := session_.(*models.UserSession)
+	if !ok {
+		return nil, errors.New("get current user session err, the type is not *models.UserSession")
+	}
+	return session, nil
+}
@@ -2,6 +2,7 @@ package transformersv1
 
 import (
 	"context"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -59,16 +60,64 @@ func ToApiTokenSchemas(ctx context.Context, apiTokens []*models.ApiToken) ([]*sc
 	return res, nil
 }
 
-func ToApiTokenFullSchema(ctx context.Context, apiToken *models.ApiToken) (*schemasv1.ApiTokenFullSchema, error) {
+func ToApiTokenFullSchema(ctx context.Context, apiToken *models.ApiToken) (*ApiTokenWithPrefixFullSchema, error) {
 	if apiToken == nil {
 		return nil, nil
 	}
-	s, err := ToApiTokenSchema(ctx, apiToken)
+	s, err := ToApiTokenWithPrefixSchema(ctx, apiToken)
 	if err != nil {
-		return nil, errors.Wrap(err, "ToApiTokenSchema")
+		return nil, errors.Wrap(err, "ToApiTokenWithPrefixSchema")
 	}
-	return &schemasv1.ApiTokenFullSchema{
-		ApiTokenSchema: *s,
-		Token:          apiToken.Token,
+	return &ApiTokenWithPrefixFullSchema{
+		ApiTokenWithPrefixSchema: *s,
+		Token:                    apiToken.RawToken,
 	}, nil
 }
+
+// ApiTokenWithPrefixSchema shows the visible prefix of the token, the secret is only returned once
+type ApiTokenWithPrefixSchema struct {
+	schemasv1.ApiTokenSchema
+	TokenPrefix            string                            `json:"token_prefix"`
+	PreviousTokenExpiredAt *time.Time                        `json:"previous_token_expired_at"`
+	ResourceSelectors      *models.ApiTokenResourceSelectors `json:"resource_selectors"`
+	AllowedCidrs           *models.ApiTokenAllowedCidrs      `json:"allowed_cidrs"`
+}
+
+type ApiTokenWithPrefixFullSchema struct {
+	ApiTokenWithPrefixSchema
+	Token string `json:"token"`
+}
+
+type ApiTokenWithPrefixListSchema struct {
+	schemasv1.BaseListSchema
+	Items []*ApiTokenWithPrefixSchema `json:"items"`
+}
+
+func ToApiTokenWithPrefixSchema(ctx context.Context, apiToken *models.ApiToken) (*ApiTokenWithPrefixSchema, error) {
+	if apiToken == nil {
+		return nil, nil
+	}
+	ss, err := ToApiTokenWithPrefixSchemas(ctx, []*models.ApiToken{apiToken})
+	if err != nil {
+		return nil, errors.Wrap(err, "ToApiTokenWithPrefixSchemas")
+	}
+	return ss[0], nil
+}
+
+func ToApiTokenWithPrefixSchemas(ctx context.Context, apiTokens []*models.ApiToken) ([]*ApiTokenWithPrefixSchema, error) {
+	ss, err := ToApiTokenSchemas(ctx, apiTokens)
+	if err != nil {
+		return nil, errors.Wrap(err, "ToApiTokenSchemas")
+	}
+	res := make([]*ApiTokenWithPrefixSchema, 0, len(apiTokens))
+	for i, apiToken := range apiTokens {
+		res = append(res, &ApiTokenWithPrefixSchema{
+			ApiTokenSchema:         *ss[i],
+			TokenPrefix:            apiToken.TokenPrefix,
+			PreviousTokenExpiredAt: apiToken.PreviousTokenExpiredAt,
+			ResourceSelectors:      apiToken.ResourceSelectors,
+			AllowedCidrs:           apiToken.AllowedCidrs,
+		})
+	}
+	return res, nil
+}
@@ -0,0 +1,38 @@
+package transformersv1
+
+import (
+	"context"
+	"time"
+
+	"github.com/bentoml/yatai-schemas/schemasv1"
+	"github.com/bentoml/yatai/api-server/models"
+)
+
+type ApiTokenUsageSchema struct {
+	schemasv1.BaseSchema
+	Minute      time.Time `json:"minute"`
+	ClientIp    string    `json:"client_ip"`
+	UserAgent   string    `json:"user_agent"`
+	OperationId string    `json:"operation_id"`
+	Count       uint      `json:"count"`
+}
+
+type ApiTokenUsageListSchema struct {
+	schemasv1.BaseListSchema
+	Items []*ApiTokenUsageSchema `json:"items"`
+}
+
+func ToApiTokenUsageSchemas(ctx context.Context, apiTokenUsages []*models.ApiTokenUsage) ([]*ApiTokenUsageSchema, error) {
+	res := make([]*ApiTokenUsageSchema, 0, len(apiTokenUsages))
+	for _, apiTokenUsage := range apiTokenUsages {
+		res = append(res, &ApiTokenUsageSchema{
+			BaseSchema:  ToBaseSchema(apiTokenUsage),
+			Minute:      apiTokenUsage.Minute,
+			ClientIp:    apiTokenUsage.ClientIp,
+			UserAgent:   apiTokenUsage.UserAgent,
+			OperationId: apiTokenUsage.OperationId,
+			Count:       apiTokenUsage.Count,
+		})
+	}
+	return res, nil
+}
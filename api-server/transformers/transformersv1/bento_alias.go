@@ -0,0 +1,81 @@
+package transformersv1
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/bentoml/yatai-schemas/schemasv1"
+	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/api-server/services"
+)
+
+type BentoAliasSchema struct {
+	schemasv1.BaseSchema
+	Name    string                `json:"name"`
+	Version string                `json:"version"`
+	Creator *schemasv1.UserSchema `json:"creator"`
+}
+
+type BentoFullWithAliasesSchema struct {
+	schemasv1.BentoFullSchema
+	Aliases []string `json:"aliases"`
+}
+
+func ToBentoAliasSchema(ctx context.Context, bentoAlias *models.BentoAlias) (*BentoAliasSchema, error) {
+	if bentoAlias == nil {
+		return nil, nil
+	}
+	ss, err := ToBentoAliasSchemas(ctx, []*models.BentoAlias{bentoAlias})
+	if err != nil {
+		return nil, errors.Wrap(err, "ToBentoAliasSchemas")
+	}
+	return ss[0], nil
+}
+
+func ToBentoAliasSchemas(ctx context.Context, bentoAliases []*models.BentoAlias) ([]*BentoAliasSchema, error) {
+	res := make([]*BentoAliasSchema, 0, len(bentoAliases))
+	for _, bentoAlias := range bentoAliases {
+		creator, err := services.UserService.GetAssociatedCreator(ctx, bentoAlias)
+		if err != nil {
+			return nil, errors.Wrap(err, "GetAssociatedCreator")
+		}
+		creatorSchema, err := ToUserSchema(ctx, creator)
+		if err != nil {
+			return nil, errors.Wrap(err, "ToUserSchema")
+		}
+		bento, err := services.BentoService.GetAssociatedBento(ctx, bentoAlias)
+		if err != nil {
+			return nil, errors.Wrap(err, "GetAssociatedBento")
+		}
+		res = append(res, &BentoAliasSchema{
+			BaseSchema: ToBaseSchema(bentoAlias),
+			Name:       bentoAlias.Name,
+			Version:    bento.Version,
+			Creator:    creatorSchema,
+		})
+	}
+	return res, nil
+}
+
+func ToBentoFullWithAliasesSchema(ctx context.Context, bento *models.Bento) (*BentoFullWithAliasesSchema, error) {
+	if bento == nil {
+		return nil, nil
+	}
+	bentoFullSchema, err := ToBentoFullSchema(ctx, bento)
+	if err != nil {
+		return nil, err
+	}
+	aliasNames, err := services.BentoAliasService.GroupNamesByBentoIds(ctx, []uint{bento.ID})
+	if err != nil {
+		return nil, errors.Wrap(err, "GroupNamesByBentoIds")
+	}
+	aliases := aliasNames[bento.ID]
+	if aliases == nil {
+		aliases = make([]string, 0)
+	}
+	return &BentoFullWithAliasesSchema{
+		BentoFullSchema: *bentoFullSchema,
+		Aliases:         aliases,
+	}, nil
+}
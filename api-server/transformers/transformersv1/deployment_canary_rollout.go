@@ -0,0 +1,17 @@
+package transformersv1
+
+import (
+	"github.com/bentoml/yatai/api-server/models"
+)
+
+type DeploymentCanaryRolloutSchema struct {
+	RevisionUid string `json:"revision_uid"`
+	models.DeploymentCanaryRollout
+}
+
+func ToDeploymentCanaryRolloutSchema(deploymentRevision *models.DeploymentRevision, rollout *models.DeploymentCanaryRollout) *DeploymentCanaryRolloutSchema {
+	return &DeploymentCanaryRolloutSchema{
+		RevisionUid:             deploymentRevision.Uid,
+		DeploymentCanaryRollout: *rollout,
+	}
+}
@@ -0,0 +1,56 @@
+package transformersv1
+
+import (
+	"context"
+
+	"github.com/bentoml/yatai-schemas/modelschemas"
+	"github.com/bentoml/yatai-schemas/schemasv1"
+	"github.com/bentoml/yatai/api-server/services"
+	"github.com/bentoml/yatai/common/utils"
+)
+
+type DeploymentTargetDryRunSchema struct {
+	Type             modelschemas.DeploymentTargetType `json:"type"`
+	Bento            string                            `json:"bento"`
+	Rendered         interface{}                       `json:"rendered"`
+	ValidationErrors []string                          `json:"validation_errors"`
+	Diff             []utils.DiffItem                  `json:"diff"`
+}
+
+type DeploymentDryRunSchema struct {
+	Valid   bool                            `json:"valid"`
+	Targets []*DeploymentTargetDryRunSchema `json:"targets"`
+}
+
+type DeploymentWithDryRunSchema struct {
+	schemasv1.DeploymentSchema
+	DryRun *DeploymentDryRunSchema `json:"dry_run,omitempty"`
+}
+
+func ToDeploymentDryRunSchema(ctx context.Context, results []*services.KubeBentoDeploymentDryRunResult) (*DeploymentDryRunSchema, error) {
+	res := &DeploymentDryRunSchema{
+		Valid:   true,
+		Targets: make([]*DeploymentTargetDryRunSchema, 0, len(results)),
+	}
+	for _, result := range results {
+		bento, err := services.BentoService.GetAssociatedBento(ctx, result.DeploymentTarget)
+		if err != nil {
+			return nil, err
+		}
+		tag, err := services.BentoService.GetTag(ctx, bento)
+		if err != nil {
+			return nil, err
+		}
+		if len(result.ValidationErrors) > 0 {
+			res.Valid = false
+		}
+		res.Targets = append(res.Targets, &DeploymentTargetDryRunSchema{
+			Type:             result.DeploymentTarget.Type,
+			Bento:            string(tag),
+			Rendered:         result.Rendered,
+			ValidationErrors: result.ValidationErrors,
+			Diff:             result.Diff,
+		})
+	}
+	return res, nil
+}
@@ -0,0 +1,24 @@
+package transformersv1
+
+import (
+	"time"
+
+	"github.com/bentoml/yatai/api-server/models"
+)
+
+type DeploymentFollowPolicySchema struct {
+	Enabled bool `json:"enabled"`
+	models.DeploymentFollowPolicy
+	FollowDeployedAt *time.Time `json:"follow_deployed_at"`
+}
+
+func ToDeploymentFollowPolicySchema(deployment *models.Deployment) *DeploymentFollowPolicySchema {
+	res := &DeploymentFollowPolicySchema{
+		FollowDeployedAt: deployment.FollowDeployedAt,
+	}
+	if deployment.FollowPolicy != nil {
+		res.Enabled = true
+		res.DeploymentFollowPolicy = *deployment.FollowPolicy
+	}
+	return res
+}
@@ -11,6 +11,36 @@ import (
 	"github.com/bentoml/yatai/common/utils"
 )
 
+type DeploymentRevisionWithInfoSchema struct {
+	schemasv1.DeploymentRevisionSchema
+	Info *models.DeploymentRevisionInfo `json:"info"`
+}
+
+func ToDeploymentRevisionWithInfoSchema(ctx context.Context, deploymentRevision *models.DeploymentRevision) (*DeploymentRevisionWithInfoSchema, error) {
+	s, err := ToDeploymentRevisionSchema(ctx, deploymentRevision)
+	if err != nil {
+		return nil, err
+	}
+	return &DeploymentRevisionWithInfoSchema{
+		DeploymentRevisionSchema: *s,
+		Info:                     deploymentRevision.Info,
+	}, nil
+}
+
+type DeploymentRevisionDiffSchema struct {
+	BaseRevisionUid string           `json:"base_revision_uid"`
+	RevisionUid     string           `json:"revision_uid"`
+	Items           []utils.DiffItem `json:"items"`
+}
+
+func ToDeploymentRevisionDiffSchema(baseDeploymentRevision, deploymentRevision *models.DeploymentRevision, items []utils.DiffItem) *DeploymentRevisionDiffSchema {
+	return &DeploymentRevisionDiffSchema{
+		BaseRevisionUid: baseDeploymentRevision.Uid,
+		RevisionUid:     deploymentRevision.Uid,
+		Items:           items,
+	}
+}
+
 func ToDeploymentRevisionSchema(ctx context.Context, deploymentRevision *models.DeploymentRevision) (*schemasv1.DeploymentRevisionSchema, error) {
 	if deploymentRevision == nil {
 		return nil, nil
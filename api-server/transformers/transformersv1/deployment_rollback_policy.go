@@ -0,0 +1,20 @@
+package transformersv1
+
+import (
+	"github.com/bentoml/yatai/api-server/models"
+)
+
+type DeploymentRollbackPolicySchema struct {
+	Enabled            bool `json:"enabled"`
+	GracePeriodSeconds uint `json:"grace_period_seconds"`
+	MaxAttempts        uint `json:"max_attempts"`
+}
+
+func ToDeploymentRollbackPolicySchema(deployment *models.Deployment) *DeploymentRollbackPolicySchema {
+	policy := deployment.RollbackPolicy
+	return &DeploymentRollbackPolicySchema{
+		Enabled:            policy != nil && policy.Enabled,
+		GracePeriodSeconds: uint(policy.GetGracePeriod().Seconds()),
+		MaxAttempts:        policy.GetMaxAttempts(),
+	}
+}
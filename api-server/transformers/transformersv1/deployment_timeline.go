@@ -0,0 +1,77 @@
+package transformersv1
+
+import (
+	"context"
+	"time"
+
+	"github.com/bentoml/yatai-schemas/modelschemas"
+	"github.com/bentoml/yatai-schemas/schemasv1"
+	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/api-server/services"
+)
+
+type DeploymentStatusHistorySchema struct {
+	schemasv1.BaseSchema
+	FromStatus *modelschemas.DeploymentStatus `json:"from_status"`
+	Status     modelschemas.DeploymentStatus  `json:"status"`
+	Reason     *models.DeploymentStatusReason `json:"reason"`
+}
+
+type DeploymentTimelineItemSchema struct {
+	Type          services.DeploymentTimelineItemType `json:"type"`
+	CreatedAt     time.Time                           `json:"created_at"`
+	StatusHistory *DeploymentStatusHistorySchema      `json:"status_history,omitempty"`
+	Revision      *DeploymentRevisionWithInfoSchema   `json:"revision,omitempty"`
+	Event         *schemasv1.EventSchema              `json:"event,omitempty"`
+}
+
+type DeploymentTimelineSchema struct {
+	schemasv1.BaseListSchema
+	Items []*DeploymentTimelineItemSchema `json:"items"`
+}
+
+func ToDeploymentStatusHistorySchema(deploymentStatusHistory *models.DeploymentStatusHistory) *DeploymentStatusHistorySchema {
+	return &DeploymentStatusHistorySchema{
+		BaseSchema: ToBaseSchema(deploymentStatusHistory),
+		FromStatus: deploymentStatusHistory.FromStatus,
+		Status:     deploymentStatusHistory.Status,
+		Reason:     deploymentStatusHistory.Reason,
+	}
+}
+
+func ToDeploymentTimelineItemSchemas(ctx context.Context, items []*services.DeploymentTimelineItem) ([]*DeploymentTimelineItemSchema, error) {
+	events := make([]*models.Event, 0)
+	for _, item := range items {
+		if item.Event != nil {
+			events = append(events, item.Event)
+		}
+	}
+	eventSchemas, err := ToEventSchemas(ctx, events)
+	if err != nil {
+		return nil, err
+	}
+	eventSchemasMap := make(map[string]*schemasv1.EventSchema, len(eventSchemas))
+	for _, eventSchema := range eventSchemas {
+		eventSchemasMap[eventSchema.Uid] = eventSchema
+	}
+	res := make([]*DeploymentTimelineItemSchema, 0, len(items))
+	for _, item := range items {
+		itemSchema := &DeploymentTimelineItemSchema{
+			Type:      item.Type,
+			CreatedAt: item.CreatedAt,
+		}
+		switch {
+		case item.StatusHistory != nil:
+			itemSchema.StatusHistory = ToDeploymentStatusHistorySchema(item.StatusHistory)
+		case item.Revision != nil:
+			itemSchema.Revision, err = ToDeploymentRevisionWithInfoSchema(ctx, item.Revision)
+			if err != nil {
+				return nil, err
+			}
+		case item.Event != nil:
+			itemSchema.Event = eventSchemasMap[item.Event.Uid]
+		}
+		res = append(res, itemSchema)
+	}
+	return res, nil
+}
@@ -0,0 +1,81 @@
+package transformersv1
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/bentoml/yatai-schemas/schemasv1"
+	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/api-server/services"
+)
+
+type ModelAliasSchema struct {
+	schemasv1.BaseSchema
+	Name    string                `json:"name"`
+	Version string                `json:"version"`
+	Creator *schemasv1.UserSchema `json:"creator"`
+}
+
+type ModelFullWithAliasesSchema struct {
+	schemasv1.ModelFullSchema
+	Aliases []string `json:"aliases"`
+}
+
+func ToModelAliasSchema(ctx context.Context, modelAlias *models.ModelAlias) (*ModelAliasSchema, error) {
+	if modelAlias == nil {
+		return nil, nil
+	}
+	ss, err := ToModelAliasSchemas(ctx, []*models.ModelAlias{modelAlias})
+	if err != nil {
+		return nil, errors.Wrap(err, "ToModelAliasSchemas")
+	}
+	return ss[0], nil
+}
+
+func ToModelAliasSchemas(ctx context.Context, modelAliases []*models.ModelAlias) ([]*ModelAliasSchema, error) {
+	res := make([]*ModelAliasSchema, 0, len(modelAliases))
+	for _, modelAlias := range modelAliases {
+		creator, err := services.UserService.GetAssociatedCreator(ctx, modelAlias)
+		if err != nil {
+			return nil, errors.Wrap(err, "GetAssociatedCreator")
+		}
+		creatorSchema, err := ToUserSchema(ctx, creator)
+		if err != nil {
+			return nil, errors.Wrap(err, "ToUserSchema")
+		}
+		model, err := services.ModelService.GetAssociatedModel(ctx, modelAlias)
+		if err != nil {
+			return nil, errors.Wrap(err, "GetAssociatedModel")
+		}
+		res = append(res, &ModelAliasSchema{
+			BaseSchema: ToBaseSchema(modelAlias),
+			Name:       modelAlias.Name,
+			Version:    model.Version,
+			Creator:    creatorSchema,
+		})
+	}
+	return res, nil
+}
+
+func ToModelFullWithAliasesSchema(ctx context.Context, model *models.Model) (*ModelFullWithAliasesSchema, error) {
+	if model == nil {
+		return nil, nil
+	}
+	modelFullSchema, err := ToModelFullSchema(ctx, model)
+	if err != nil {
+		return nil, err
+	}
+	aliasNames, err := services.ModelAliasService.GroupNamesByModelIds(ctx, []uint{model.ID})
+	if err != nil {
+		return nil, errors.Wrap(err, "GroupNamesByModelIds")
+	}
+	aliases := aliasNames[model.ID]
+	if aliases == nil {
+		aliases = make([]string, 0)
+	}
+	return &ModelFullWithAliasesSchema{
+		ModelFullSchema: *modelFullSchema,
+		Aliases:         aliases,
+	}, nil
+}
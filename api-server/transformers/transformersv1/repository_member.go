@@ -0,0 +1,99 @@
+package transformersv1
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/bentoml/yatai-schemas/modelschemas"
+	"github.com/bentoml/yatai-schemas/schemasv1"
+	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/api-server/services"
+)
+
+type RepositoryMemberSchema struct {
+	schemasv1.BaseSchema
+	Creator *schemasv1.UserSchema   `json:"creator"`
+	User    schemasv1.UserSchema    `json:"user"`
+	Role    modelschemas.MemberRole `json:"role"`
+}
+
+type BentoRepositoryWithVisibilitySchema struct {
+	schemasv1.BentoRepositorySchema
+	Visibility models.RepositoryVisibility `json:"visibility"`
+}
+
+type ModelRepositoryWithVisibilitySchema struct {
+	schemasv1.ModelRepositorySchema
+	Visibility models.RepositoryVisibility `json:"visibility"`
+}
+
+func ToRepositoryMemberSchema(ctx context.Context, member *models.RepositoryMember) (*RepositoryMemberSchema, error) {
+	if member == nil {
+		return nil, nil
+	}
+	ss, err := ToRepositoryMemberSchemas(ctx, []*models.RepositoryMember{member})
+	if err != nil {
+		return nil, errors.Wrap(err, "ToRepositoryMemberSchemas")
+	}
+	return ss[0], nil
+}
+
+func ToRepositoryMemberSchemas(ctx context.Context, members []*models.RepositoryMember) ([]*RepositoryMemberSchema, error) {
+	res := make([]*RepositoryMemberSchema, 0, len(members))
+	for _, member := range members {
+		creator, err := services.UserService.GetAssociatedCreator(ctx, member)
+		if err != nil {
+			return nil, errors.Wrap(err, "get repository member associated creator")
+		}
+		creatorSchema, err := ToUserSchema(ctx, creator)
+		if err != nil {
+			return nil, errors.Wrap(err, "ToUserSchema")
+		}
+
+		user, err := services.UserService.GetAssociatedUser(ctx, member)
+		if err != nil {
+			return nil, errors.Wrap(err, "get repository member associated user")
+		}
+		userSchema, err := ToUserSchema(ctx, user)
+		if err != nil {
+			return nil, errors.Wrap(err, "ToUserSchema")
+		}
+
+		res = append(res, &RepositoryMemberSchema{
+			BaseSchema: ToBaseSchema(member),
+			Creator:    creatorSchema,
+			User:       *userSchema,
+			Role:       member.Role,
+		})
+	}
+	return res, nil
+}
+
+func ToBentoRepositoryWithVisibilitySchema(ctx context.Context, bentoRepository *models.BentoRepository) (*BentoRepositoryWithVisibilitySchema, error) {
+	if bentoRepository == nil {
+		return nil, nil
+	}
+	bentoRepositorySchema, err := ToBentoRepositorySchema(ctx, bentoRepository)
+	if err != nil {
+		return nil, err
+	}
+	return &BentoRepositoryWithVisibilitySchema{
+		BentoRepositorySchema: *bentoRepositorySchema,
+		Visibility:            bentoRepository.Visibility,
+	}, nil
+}
+
+func ToModelRepositoryWithVisibilitySchema(ctx context.Context, modelRepository *models.ModelRepository) (*ModelRepositoryWithVisibilitySchema, error) {
+	if modelRepository == nil {
+		return nil, nil
+	}
+	modelRepositorySchema, err := ToModelRepositorySchema(ctx, modelRepository)
+	if err != nil {
+		return nil, err
+	}
+	return &ModelRepositoryWithVisibilitySchema{
+		ModelRepositorySchema: *modelRepositorySchema,
+		Visibility:            modelRepository.Visibility,
+	}, nil
+}
@@ -0,0 +1,20 @@
+package transformersv1
+
+import (
+	"github.com/bentoml/yatai/api-server/models"
+)
+
+type RetentionPolicySchema struct {
+	KeepLast       *uint `json:"keep_last,omitempty"`
+	KeepWithinDays *uint `json:"keep_within_days,omitempty"`
+}
+
+func ToRetentionPolicySchema(retentionPolicy *models.RetentionPolicy) *RetentionPolicySchema {
+	if retentionPolicy == nil {
+		return &RetentionPolicySchema{}
+	}
+	return &RetentionPolicySchema{
+		KeepLast:       retentionPolicy.KeepLast,
+		KeepWithinDays: retentionPolicy.KeepWithinDays,
+	}
+}
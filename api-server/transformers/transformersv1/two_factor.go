@@ -0,0 +1,90 @@
+package transformersv1
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+
+	"github.com/bentoml/yatai-schemas/modelschemas"
+	"github.com/bentoml/yatai-schemas/schemasv1"
+	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/api-server/services"
+	"github.com/bentoml/yatai/common/utils"
+)
+
+// LoginResultSchema carries the user only when the login is finished, nothing about the user is returned
+// before the two-factor code is verified.
+type LoginResultSchema struct {
+	schemasv1.UserSchema
+	// TwoFactorRequired is true when the password is accepted and the login has to be finished with a two-factor code
+	TwoFactorRequired bool `json:"two_factor_required"`
+}
+
+func (s LoginResultSchema) MarshalJSON() ([]byte, error) {
+	if s.TwoFactorRequired {
+		return json.Marshal(map[string]interface{}{
+			"two_factor_required": true,
+		})
+	}
+	type loginResultSchema LoginResultSchema
+	return json.Marshal(loginResultSchema(s))
+}
+
+type TwoFactorSchema struct {
+	Enabled bool `json:"enabled"`
+	// Required is true when the current organization requires the two-factor authentication for the user
+	Required               bool `json:"required"`
+	RecoveryCodesRemaining int  `json:"recovery_codes_remaining"`
+}
+
+type TwoFactorEnrollmentSchema struct {
+	Secret          string `json:"secret"`
+	ProvisioningUri string `json:"provisioning_uri"`
+}
+
+type TwoFactorRecoveryCodesSchema struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type OrganizationFullWithTwoFactorSchema struct {
+	schemasv1.OrganizationFullSchema
+	TwoFactorRequiredRole modelschemas.MemberRole `json:"two_factor_required_role"`
+}
+
+func ToTwoFactorSchema(ctx context.Context, user *models.User) (*TwoFactorSchema, error) {
+	required := false
+	org, err := services.GetCurrentOrganization(ctx)
+	if err != nil && !utils.IsNotFound(err) {
+		return nil, errors.Wrap(err, "get current organization")
+	}
+	if org != nil {
+		required, err = services.TwoFactorService.IsRequired(ctx, user, org)
+		if err != nil {
+			return nil, errors.Wrap(err, "check two-factor requirement")
+		}
+	}
+	recoveryCodesRemaining := 0
+	if user.TotpEnabled && user.TotpRecoveryCodes != nil {
+		recoveryCodesRemaining = len(*user.TotpRecoveryCodes)
+	}
+	return &TwoFactorSchema{
+		Enabled:                user.TotpEnabled,
+		Required:               required,
+		RecoveryCodesRemaining: recoveryCodesRemaining,
+	}, nil
+}
+
+func ToOrganizationFullWithTwoFactorSchema(ctx context.Context, organization *models.Organization) (*OrganizationFullWithTwoFactorSchema, error) {
+	if organization == nil {
+		return nil, nil
+	}
+	organizationSchema, err := ToOrganizationFullSchema(ctx, organization)
+	if err != nil {
+		return nil, err
+	}
+	return &OrganizationFullWithTwoFactorSchema{
+		OrganizationFullSchema: *organizationSchema,
+		TwoFactorRequiredRole:  organization.TwoFactorRequiredRole,
+	}, nil
+}
@@ -0,0 +1,113 @@
+package transformersv1
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/bentoml/yatai-schemas/modelschemas"
+	"github.com/bentoml/yatai-schemas/schemasv1"
+	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/api-server/services"
+)
+
+type UserGroupSchema struct {
+	schemasv1.BaseSchema
+	Name         string                        `json:"name"`
+	Description  string                        `json:"description"`
+	Creator      *schemasv1.UserSchema         `json:"creator"`
+	Organization *schemasv1.OrganizationSchema `json:"organization"`
+}
+
+type UserGroupListSchema struct {
+	schemasv1.BaseListSchema
+	Items []*UserGroupSchema `json:"items"`
+}
+
+type UserGroupGrantSchema struct {
+	schemasv1.BaseSchema
+	Creator      *schemasv1.UserSchema     `json:"creator"`
+	ResourceType modelschemas.ResourceType `json:"resource_type"`
+	ResourceUid  string                    `json:"resource_uid"`
+	ResourceName string                    `json:"resource_name"`
+	Role         modelschemas.MemberRole   `json:"role"`
+}
+
+func ToUserGroupSchema(ctx context.Context, userGroup *models.UserGroup) (*UserGroupSchema, error) {
+	if userGroup == nil {
+		return nil, nil
+	}
+	ss, err := ToUserGroupSchemas(ctx, []*models.UserGroup{userGroup})
+	if err != nil {
+		return nil, errors.Wrap(err, "ToUserGroupSchemas")
+	}
+	return ss[0], nil
+}
+
+func ToUserGroupSchemas(ctx context.Context, userGroups []*models.UserGroup) ([]*UserGroupSchema, error) {
+	res := make([]*UserGroupSchema, 0, len(userGroups))
+	for _, userGroup := range userGroups {
+		creator, err := services.UserService.GetAssociatedCreator(ctx, userGroup)
+		if err != nil {
+			return nil, errors.Wrap(err, "get user group associated creator")
+		}
+		creatorSchema, err := ToUserSchema(ctx, creator)
+		if err != nil {
+			return nil, errors.Wrap(err, "ToUserSchema")
+		}
+		org, err := services.OrganizationService.GetAssociatedOrganization(ctx, userGroup)
+		if err != nil {
+			return nil, errors.Wrap(err, "get user group associated organization")
+		}
+		organizationSchema, err := ToOrganizationSchema(ctx, org)
+		if err != nil {
+			return nil, errors.Wrap(err, "ToOrganizationSchema")
+		}
+		res = append(res, &UserGroupSchema{
+			BaseSchema:   ToBaseSchema(userGroup),
+			Name:         userGroup.Name,
+			Description:  userGroup.Description,
+			Creator:      creatorSchema,
+			Organization: organizationSchema,
+		})
+	}
+	return res, nil
+}
+
+func ToUserGroupGrantSchema(ctx context.Context, grant *models.UserGroupGrant) (*UserGroupGrantSchema, error) {
+	if grant == nil {
+		return nil, nil
+	}
+	ss, err := ToUserGroupGrantSchemas(ctx, []*models.UserGroupGrant{grant})
+	if err != nil {
+		return nil, errors.Wrap(err, "ToUserGroupGrantSchemas")
+	}
+	return ss[0], nil
+}
+
+func ToUserGroupGrantSchemas(ctx context.Context, grants []*models.UserGroupGrant) ([]*UserGroupGrantSchema, error) {
+	res := make([]*UserGroupGrantSchema, 0, len(grants))
+	for _, grant := range grants {
+		creator, err := services.UserService.GetAssociatedCreator(ctx, grant)
+		if err != nil {
+			return nil, errors.Wrap(err, "get user group grant associated creator")
+		}
+		creatorSchema, err := ToUserSchema(ctx, creator)
+		if err != nil {
+			return nil, errors.Wrap(err, "ToUserSchema")
+		}
+		resource, err := services.ResourceService.Get(ctx, grant.ResourceType, grant.ResourceId)
+		if err != nil {
+			return nil, errors.Wrapf(err, "get %s %d", grant.ResourceType, grant.ResourceId)
+		}
+		res = append(res, &UserGroupGrantSchema{
+			BaseSchema:   ToBaseSchema(grant),
+			Creator:      creatorSchema,
+			ResourceType: grant.ResourceType,
+			ResourceUid:  resource.GetUid(),
+			ResourceName: resource.GetName(),
+			Role:         grant.Role,
+		})
+	}
+	return res, nil
+}
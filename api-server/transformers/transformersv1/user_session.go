@@ -0,0 +1,50 @@
+package transformersv1
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/bentoml/yatai-schemas/schemasv1"
+	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/api-server/services"
+)
+
+type UserSessionSchema struct {
+	schemasv1.BaseSchema
+	ClientIp   string    `json:"client_ip"`
+	UserAgent  string    `json:"user_agent"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	// IsCurrent is true for the session which the request is sent with
+	IsCurrent bool `json:"is_current"`
+}
+
+func ToUserSessionSchema(ctx context.Context, session *models.UserSession) (*UserSessionSchema, error) {
+	if session == nil {
+		return nil, nil
+	}
+	ss, err := ToUserSessionSchemas(ctx, []*models.UserSession{session})
+	if err != nil {
+		return nil, errors.Wrap(err, "ToUserSessionSchemas")
+	}
+	return ss[0], nil
+}
+
+func ToUserSessionSchemas(ctx context.Context, sessions []*models.UserSession) ([]*UserSessionSchema, error) {
+	var currentSessionId uint
+	if currentSession, err := services.GetCurrentUserSession(ctx); err == nil {
+		currentSessionId = currentSession.ID
+	}
+	res := make([]*UserSessionSchema, 0, len(sessions))
+	for _, session := range sessions {
+		res = append(res, &UserSessionSchema{
+			BaseSchema: ToBaseSchema(session),
+			ClientIp:   session.ClientIp,
+			UserAgent:  session.UserAgent,
+			LastSeenAt: session.LastSeenAt,
+			IsCurrent:  currentSessionId != 0 && session.ID == currentSessionId,
+		})
+	}
+	return res, nil
+}
@@ -38,6 +38,21 @@ const (
 	EnvDockerImageBuilderPrivileged = "DOCKER_IMAGE_BUILDER_PRIVILEGED"
 
 	EnvReadHeaderTimeout = "READ_HEADER_TIMEOUT"
+	EnvShutdownTimeout   = "SHUTDOWN_TIMEOUT"
+
+	EnvTLSCertFile     = "TLS_CERT_FILE"
+	EnvTLSKeyFile      = "TLS_KEY_FILE"
+	EnvTLSClientCAFile = "TLS_CLIENT_CA_FILE"
+
+	EnvTrustedProxies = "TRUSTED_PROXIES"
+
+	EnvOIDCIssuer   = "OIDC_ISSUER"
+	EnvOIDCClientId = "OIDC_CLIENT_ID"
+	// nolint:gosec
+	EnvOIDCClientSecret = "OIDC_CLIENT_SECRET"
+	// nolint:gosec
+	EnvLDAPBindPassword  = "LDAP_BIND_PASSWORD"
+	EnvDisableLocalLogin = "DISABLE_LOCAL_LOGIN"
 
 	EnvTransmissionStrategy = "TRANSMISSION_STRATEGY"
 )
@@ -0,0 +1,109 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/wI2L/fizz"
+)
+
+const Namespace = "yatai"
+
+type WebsocketType string
+
+const (
+	WebsocketTypeTail         WebsocketType = "tail"
+	WebsocketTypeTerminal     WebsocketType = "terminal"
+	WebsocketTypeSubscription WebsocketType = "subscription"
+)
+
+type DeploymentSyncSource string
+
+const (
+	DeploymentSyncSourceCron    DeploymentSyncSource = "cron"
+	DeploymentSyncSourceWatcher DeploymentSyncSource = "watcher"
+)
+
+var (
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "Latency of the http requests by fizz operation id.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operation_id", "method", "status"})
+
+	websocketConnections = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "websocket",
+		Name:      "connections",
+		Help:      "Number of the open websocket connections by type.",
+	}, []string{"type"})
+
+	deploymentSyncDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Subsystem: "deployment",
+		Name:      "sync_duration_seconds",
+		Help:      "Duration of the deployment status syncs.",
+		Buckets:   []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300, 600},
+	}, []string{"source"})
+
+	deploymentSyncErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "deployment",
+		Name:      "sync_errors_total",
+		Help:      "Number of the failed deployment status syncs.",
+	}, []string{"source"})
+
+	s3OperationDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Subsystem: "s3",
+		Name:      "operation_duration_seconds",
+		Help:      "Latency of the s3 operations.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operation", "success"})
+)
+
+// OperationId returns the fizz operation id of the request, it is only known once the fizz
+// handler started, requests outside of fizz fall back to the route path.
+func OperationId(c *gin.Context) string {
+	operationId := c.FullPath()
+	if operation, err := fizz.OperationFromContext(c); err == nil {
+		operationId = operation.ID
+	}
+	if operationId == "" {
+		operationId = "unknown"
+	}
+	return operationId
+}
+
+// HTTPMiddleware observes the latency of every request, labeled by the fizz operation id
+// so the cardinality stays bounded.
+func HTTPMiddleware(c *gin.Context) {
+	start := time.Now()
+	c.Next()
+
+	operationId := OperationId(c)
+	httpRequestDuration.WithLabelValues(operationId, c.Request.Method, strconv.Itoa(c.Writer.Status())).Observe(time.Since(start).Seconds())
+}
+
+// TrackWebsocketConnection counts an open websocket connection, call the returned func when it is closed.
+func TrackWebsocketConnection(type_ WebsocketType) func() {
+	gauge := websocketConnections.WithLabelValues(string(type_))
+	gauge.Inc()
+	return gauge.Dec
+}
+
+func ObserveDeploymentSync(source DeploymentSyncSource, start time.Time, err error) {
+	deploymentSyncDuration.WithLabelValues(string(source)).Observe(time.Since(start).Seconds())
+	if err != nil {
+		deploymentSyncErrors.WithLabelValues(string(source)).Inc()
+	}
+}
+
+func ObserveS3Operation(operation string, start time.Time, err error) {
+	s3OperationDuration.WithLabelValues(operation, strconv.FormatBool(err == nil)).Observe(time.Since(start).Seconds())
+}
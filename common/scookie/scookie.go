@@ -1,31 +1,69 @@
 package scookie
 
 import (
+	"time"
+
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-gonic/gin"
 )
 
 const (
-	UserNameKey = "username"
+	// SessionKeyKey is the key of the server-side user session, the cookie carries nothing else about the user
+	SessionKeyKey = "session_key"
 )
 
-func SetUsernameToCookie(ctx *gin.Context, username string) error {
+func SetSessionKeyToCookie(ctx *gin.Context, sessionKey string) error {
 	session := sessions.Default(ctx)
-	session.Set(UserNameKey, username)
+	session.Set(SessionKeyKey, sessionKey)
 	return session.Save()
 }
 
-func GetUsernameFromCookie(ctx *gin.Context) string {
+func GetSessionKeyFromCookie(ctx *gin.Context) string {
 	session := sessions.Default(ctx)
-	username, ok := session.Get(UserNameKey).(string)
+	sessionKey, ok := session.Get(SessionKeyKey).(string)
 	if !ok {
 		return ""
 	}
-	return username
+	return sessionKey
+}
+
+func DeleteSessionKeyFromCookie(ctx *gin.Context) error {
+	session := sessions.Default(ctx)
+	session.Delete(SessionKeyKey)
+	return session.Save()
+}
+
+const (
+	// TwoFactorPendingUserIdKey is the user who passed the password check and still has to enter the two-factor code
+	TwoFactorPendingUserIdKey    = "two_factor_pending_user_id"
+	TwoFactorPendingExpiresAtKey = "two_factor_pending_expires_at"
+	twoFactorPendingTimeout      = 5 * time.Minute
+)
+
+func SetTwoFactorPendingUserIdToCookie(ctx *gin.Context, userId uint) error {
+	session := sessions.Default(ctx)
+	session.Set(TwoFactorPendingUserIdKey, userId)
+	session.Set(TwoFactorPendingExpiresAtKey, time.Now().Add(twoFactorPendingTimeout).Unix())
+	return session.Save()
+}
+
+// GetTwoFactorPendingUserIdFromCookie returns 0 if there is no pending login or it is expired
+func GetTwoFactorPendingUserIdFromCookie(ctx *gin.Context) uint {
+	session := sessions.Default(ctx)
+	userId, ok := session.Get(TwoFactorPendingUserIdKey).(uint)
+	if !ok {
+		return 0
+	}
+	expiresAt, ok := session.Get(TwoFactorPendingExpiresAtKey).(int64)
+	if !ok || time.Now().Unix() > expiresAt {
+		return 0
+	}
+	return userId
 }
 
-func DeleteUsernameFromCookie(ctx *gin.Context) error {
+func DeleteTwoFactorPendingUserIdFromCookie(ctx *gin.Context) error {
 	session := sessions.Default(ctx)
-	session.Delete(UserNameKey)
+	session.Delete(TwoFactorPendingUserIdKey)
+	session.Delete(TwoFactorPendingExpiresAtKey)
 	return session.Save()
 }
@@ -0,0 +1,154 @@
+package tlsreloader
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// Reloader serves the certificate and the client CAs from files which are reloaded once they change,
+// e.g. when cert-manager rotates the mounted secret.
+type Reloader struct {
+	certFile     string
+	keyFile      string
+	clientCAFile string
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	modTimes  map[string]time.Time
+}
+
+func New(certFile, keyFile, clientCAFile string) (*Reloader, error) {
+	r := &Reloader{
+		certFile:     certFile,
+		keyFile:      keyFile,
+		clientCAFile: clientCAFile,
+		modTimes:     make(map[string]time.Time),
+	}
+	_, err := r.reload()
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *Reloader) files() []string {
+	files := []string{r.certFile, r.keyFile}
+	if r.clientCAFile != "" {
+		files = append(files, r.clientCAFile)
+	}
+	return files
+}
+
+// reload loads the files again if any of them changed since the last load.
+func (r *Reloader) reload() (bool, error) {
+	modTimes := make(map[string]time.Time)
+	changed := false
+	for _, file := range r.files() {
+		info, err := os.Stat(file)
+		if err != nil {
+			return false, errors.Wrapf(err, "stat %s", file)
+		}
+		modTimes[file] = info.ModTime()
+		r.mu.RLock()
+		modTime, ok := r.modTimes[file]
+		r.mu.RUnlock()
+		if !ok || !modTime.Equal(info.ModTime()) {
+			changed = true
+		}
+	}
+	if !changed {
+		return false, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return false, errors.Wrap(err, "load x509 key pair")
+	}
+	var clientCAs *x509.CertPool
+	if r.clientCAFile != "" {
+		content, err := os.ReadFile(r.clientCAFile)
+		if err != nil {
+			return false, errors.Wrapf(err, "read client ca file %s", r.clientCAFile)
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(content) {
+			return false, errors.Errorf("no certificate found in client ca file %s", r.clientCAFile)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cert = &cert
+	r.clientCAs = clientCAs
+	r.modTimes = modTimes
+	return true, nil
+}
+
+// Run checks the files at every interval until ctx is done, a broken rotation keeps the last good certificate.
+func (r *Reloader) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		reloaded, err := r.reload()
+		if err != nil {
+			logrus.Errorf("reload tls certificate: %s", err.Error())
+			continue
+		}
+		if reloaded {
+			logrus.Infof("reloaded tls certificate %s", r.certFile)
+		}
+	}
+}
+
+// TLSConfig returns a config serving the current certificate, the client certificates are verified
+// against the current client CAs when they are given.
+func (r *Reloader) TLSConfig(minVersion uint16) *tls.Config {
+	config := &tls.Config{
+		MinVersion: minVersion,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			r.mu.RLock()
+			defer r.mu.RUnlock()
+			return r.cert, nil
+		},
+	}
+	if r.clientCAFile == "" {
+		return config
+	}
+	config.ClientAuth = tls.VerifyClientCertIfGiven
+	config.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		config_ := config.Clone()
+		config_.GetConfigForClient = nil
+		r.mu.RLock()
+		config_.ClientCAs = r.clientCAs
+		r.mu.RUnlock()
+		return config_, nil
+	}
+	return config
+}
+
+func ParseVersion(version string) (uint16, error) {
+	switch version {
+	case "", "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	case "1.1":
+		return tls.VersionTLS11, nil
+	case "1.0":
+		return tls.VersionTLS10, nil
+	}
+	return 0, errors.Errorf("unknown tls version %s", version)
+}
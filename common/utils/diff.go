@@ -0,0 +1,94 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+type DiffItem struct {
+	Path string      `json:"path"`
+	Old  interface{} `json:"old"`
+	New  interface{} `json:"new"`
+}
+
+// Diff compares the json representations of two values and returns the
+// changed leaf fields, paths look like `spec.runners[0].envs`.
+func Diff(old, new interface{}) ([]DiffItem, error) {
+	oldValue, err := toJSONValue(old)
+	if err != nil {
+		return nil, err
+	}
+	newValue, err := toJSONValue(new)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]DiffItem, 0)
+	diffJSONValue("", oldValue, newValue, &res)
+	return res, nil
+}
+
+func toJSONValue(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var res interface{}
+	err = json.Unmarshal(data, &res)
+	return res, err
+}
+
+func joinDiffPath(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+func diffJSONValue(path string, old, new interface{}, res *[]DiffItem) {
+	oldMap, oldIsMap := old.(map[string]interface{})
+	newMap, newIsMap := new.(map[string]interface{})
+	if oldIsMap && newIsMap {
+		keys := make([]string, 0, len(oldMap)+len(newMap))
+		for k := range oldMap {
+			keys = append(keys, k)
+		}
+		for k := range newMap {
+			if _, ok := oldMap[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			diffJSONValue(joinDiffPath(path, k), oldMap[k], newMap[k], res)
+		}
+		return
+	}
+	oldSlice, oldIsSlice := old.([]interface{})
+	newSlice, newIsSlice := new.([]interface{})
+	if oldIsSlice && newIsSlice {
+		size := len(oldSlice)
+		if len(newSlice) > size {
+			size = len(newSlice)
+		}
+		for i := 0; i < size; i++ {
+			var oldItem, newItem interface{}
+			if i < len(oldSlice) {
+				oldItem = oldSlice[i]
+			}
+			if i < len(newSlice) {
+				newItem = newSlice[i]
+			}
+			diffJSONValue(fmt.Sprintf("%s[%d]", path, i), oldItem, newItem, res)
+		}
+		return
+	}
+	if !reflect.DeepEqual(old, new) {
+		*res = append(*res, DiffItem{
+			Path: path,
+			Old:  old,
+			New:  new,
+		})
+	}
+}
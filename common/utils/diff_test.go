@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	type item struct {
+		Name  string            `json:"name"`
+		Envs  []string          `json:"envs"`
+		Attrs map[string]string `json:"attrs,omitempty"`
+	}
+	old := item{
+		Name: "a",
+		Envs: []string{"x"},
+	}
+	new := item{
+		Name:  "a",
+		Envs:  []string{"x", "y"},
+		Attrs: map[string]string{"k": "v"},
+	}
+	r, err := Diff(old, new)
+	if err != nil {
+		t.Fatalf("diff error: %v", err)
+	}
+	if len(r) != 2 {
+		t.Fatalf("diff items: %v", r)
+	}
+	if r[0].Path != "attrs" || r[0].Old != nil {
+		t.Fatalf("r[0] is %v", r[0])
+	}
+	if r[1].Path != "envs[1]" || r[1].Old != nil || r[1].New != "y" {
+		t.Fatalf("r[1] is %v", r[1])
+	}
+	r, err = Diff(old, old)
+	if err != nil || len(r) != 0 {
+		t.Fatalf("diff of equal values: %v", r)
+	}
+}
@@ -23,3 +23,15 @@ func RandString(length int) string {
 
 	return string(r)
 }
+
+var alphanumLetters = []rune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ1234567890")
+
+func RandAlphanumString(length int) string {
+	r := make([]rune, length)
+	for i := range r {
+		pos, _ := rand.Int(rand.Reader, big.NewInt(int64(len(alphanumLetters))))
+		r[i] = alphanumLetters[pos.Int64()]
+	}
+
+	return string(r)
+}
@@ -7,6 +7,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
 var (
@@ -139,6 +140,18 @@ func FormatCommitId(commitId string) string {
 	return commitId[:7]
 }
 
+// TruncateString cuts s to at most maxLength bytes without splitting a multi-byte character
+func TruncateString(s string, maxLength int) string {
+	if len(s) <= maxLength {
+		return s
+	}
+	end := maxLength
+	for end > 0 && !utf8.RuneStart(s[end]) {
+		end--
+	}
+	return s[:end]
+}
+
 func StringPtr(s string) *string {
 	return &s
 }
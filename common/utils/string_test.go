@@ -96,3 +96,23 @@ func TestRenderTemplate(t *testing.T) {
 		t.Fatalf("dont render tmpl, %s", val)
 	}
 }
+
+func TestTruncateString(t *testing.T) {
+	cases := []struct {
+		s        string
+		max      int
+		expected string
+	}{
+		{"hello", 10, "hello"},
+		{"hello", 3, "hel"},
+		{"héllo", 2, "h"},
+		{"héllo", 3, "hé"},
+		{"日本語", 4, "日"},
+		{"日本語", 0, ""},
+	}
+	for _, c := range cases {
+		if v := TruncateString(c.s, c.max); v != c.expected {
+			t.Errorf("TruncateString(%q, %d): expected %q, got %q", c.s, c.max, c.expected, v)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package yataicontext
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+)
+
+const clientIpContextKey = "clientIp"
+
+func GetClientIp(ctx context.Context) string {
+	v := ctx.Value(clientIpContextKey)
+	if v == nil {
+		return ""
+	}
+	if clientIp, ok := v.(string); ok {
+		return clientIp
+	}
+	return ""
+}
+
+func SetClientIp(ctx *gin.Context, clientIp string) {
+	ctx.Set(clientIpContextKey, clientIp)
+}
@@ -248,6 +248,30 @@ const locales0 = {
         'ko': '로그인',
         'vi': 'Đăng nhập',
     },
+    'login with sso': {
+        'en': 'Login with SSO',
+        'zh-CN': '使用 SSO 登录',
+        'zh-TW': '使用 SSO 登錄',
+        'ja': 'SSO でログイン',
+        'ko': 'SSO로 로그인',
+        'vi': 'Đăng nhập bằng SSO',
+    },
+    'two-factor code': {
+        'en': 'Authenticator code or recovery code',
+        'zh-CN': '验证器验证码或恢复码',
+        'zh-TW': '驗證器驗證碼或恢復碼',
+        'ja': '認証アプリのコードまたはリカバリーコード',
+        'ko': '인증 앱 코드 또는 복구 코드',
+        'vi': 'Mã xác thực hoặc mã khôi phục',
+    },
+    'verify': {
+        'en': 'Verify',
+        'zh-CN': '验证',
+        'zh-TW': '驗證',
+        'ja': '確認',
+        'ko': '확인',
+        'vi': 'Xác minh',
+    },
     'logout': {
         'en': 'Logout',
         'zh-CN': '登出',
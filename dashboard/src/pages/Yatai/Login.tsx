@@ -5,6 +5,8 @@ import { useCurrentThemeType } from '@/hooks/useCurrentThemeType'
 import useTranslation from '@/hooks/useTranslation'
 import { ILoginUserSchema } from '@/schemas/user'
 import { loginUser } from '@/services/user'
+import { ITwoFactorCodeSchema } from '@/schemas/two_factor'
+import { loginUserTwoFactor } from '@/services/two_factor'
 import { Button } from 'baseui/button'
 import { Input } from 'baseui/input'
 import qs from 'qs'
@@ -14,8 +16,10 @@ import logoDark from '@/assets/logo-dark.svg'
 import Text from '@/components/Text'
 import { useLocation } from 'react-router-dom'
 import { useStyletron } from 'baseui'
+import { useFetchInfo } from '@/hooks/useFetchInfo'
 
 const { Form, FormItem } = createForm<ILoginUserSchema>()
+const { Form: TwoFactorForm, FormItem: TwoFactorFormItem } = createForm<ITwoFactorCodeSchema>()
 
 export default function Login() {
     const currentThemeType = useCurrentThemeType()
@@ -23,25 +27,59 @@ export default function Login() {
     const [t] = useTranslation()
     const location = useLocation()
     const [isLoading, setIsLoading] = useState(false)
+    // the sso callback sends the users with two-factor authentication back here to enter the code
+    const [twoFactorRequired, setTwoFactorRequired] = useState(
+        () => qs.parse(location.search, { ignoreQueryPrefix: true }).two_factor === '1'
+    )
+    const infoInfo = useFetchInfo()
+
+    const handleSSOLogin = useCallback(() => {
+        const search = qs.parse(location.search, { ignoreQueryPrefix: true })
+        const { redirect } = search
+        window.location.href = `/oauth/oidc/login?${qs.stringify({
+            redirect: redirect && typeof redirect === 'string' ? decodeURI(redirect) : '/',
+        })}`
+    }, [location.search])
+
+    const redirectAfterLogin = useCallback(() => {
+        const search = qs.parse(location.search, { ignoreQueryPrefix: true })
+        let { redirect } = search
+        if (redirect && typeof redirect === 'string') {
+            redirect = decodeURI(redirect)
+        } else {
+            redirect = '/'
+        }
+        window.location.pathname = redirect
+    }, [location.search])
 
     const handleFinish = useCallback(
         async (data: ILoginUserSchema) => {
             setIsLoading(true)
             try {
-                await loginUser(data)
-                const search = qs.parse(location.search, { ignoreQueryPrefix: true })
-                let { redirect } = search
-                if (redirect && typeof redirect === 'string') {
-                    redirect = decodeURI(redirect)
-                } else {
-                    redirect = '/'
+                const resp = await loginUser(data)
+                if (resp.two_factor_required) {
+                    setTwoFactorRequired(true)
+                    return
                 }
-                window.location.pathname = redirect
+                redirectAfterLogin()
+            } finally {
+                setIsLoading(false)
+            }
+        },
+        [redirectAfterLogin]
+    )
+
+    const handleTwoFactorFinish = useCallback(
+        async (data: ITwoFactorCodeSchema) => {
+            setIsLoading(true)
+            try {
+                await loginUserTwoFactor(data)
+                redirectAfterLogin()
             } finally {
                 setIsLoading(false)
             }
         },
-        [location.search]
+        [redirectAfterLogin]
     )
 
     return (
@@ -100,22 +138,43 @@ export default function Login() {
                                 YATAI
                             </Text>
                         </div>
-                        <Form onFinish={handleFinish}>
-                            <FormItem name='name_or_email' label={t('email')}>
-                                <Input />
-                            </FormItem>
-                            <FormItem name='password' label={t('password')}>
-                                <Input type='password' />
-                            </FormItem>
-                            <FormItem>
-                                <div style={{ display: 'flex' }}>
-                                    <div style={{ flexGrow: 1 }} />
-                                    <Button isLoading={isLoading} size='compact'>
-                                        {t('login')}
-                                    </Button>
-                                </div>
-                            </FormItem>
-                        </Form>
+                        {twoFactorRequired ? (
+                            <TwoFactorForm onFinish={handleTwoFactorFinish}>
+                                <TwoFactorFormItem name='code' label={t('two-factor code')}>
+                                    <Input autoComplete='one-time-code' />
+                                </TwoFactorFormItem>
+                                <TwoFactorFormItem>
+                                    <div style={{ display: 'flex', gap: 10 }}>
+                                        <div style={{ flexGrow: 1 }} />
+                                        <Button isLoading={isLoading} size='compact'>
+                                            {t('verify')}
+                                        </Button>
+                                    </div>
+                                </TwoFactorFormItem>
+                            </TwoFactorForm>
+                        ) : (
+                            <Form onFinish={handleFinish}>
+                                <FormItem name='name_or_email' label={t('email')}>
+                                    <Input />
+                                </FormItem>
+                                <FormItem name='password' label={t('password')}>
+                                    <Input type='password' />
+                                </FormItem>
+                                <FormItem>
+                                    <div style={{ display: 'flex', gap: 10 }}>
+                                        <div style={{ flexGrow: 1 }} />
+                                        {infoInfo.data?.is_oidc_enabled && (
+                                            <Button type='button' kind='secondary' size='compact' onClick={handleSSOLogin}>
+                                                {t('login with sso')}
+                                            </Button>
+                                        )}
+                                        <Button isLoading={isLoading} size='compact'>
+                                            {t('login')}
+                                        </Button>
+                                    </div>
+                                </FormItem>
+                            </Form>
+                        )}
                     </Card>
                 </div>
             </div>
@@ -1,9 +1,17 @@
+import { IBaseSchema } from './base'
 import { IOrganizationSchema } from './organization'
-import { IResourceSchema } from './resource'
+import { IResourceSchema, ResourceType } from './resource'
 import { IUserSchema } from './user'
 
 export type ApiTokenScope = 'api' | 'read_organization' | 'write_organization' | 'read_cluster' | 'write_cluster'
 
+export interface IApiTokenResourceSelector {
+    resource_type: ResourceType
+    names?: string[]
+    labels?: Record<string, string>
+    cluster_name?: string
+}
+
 export interface IApiTokenSchema extends IResourceSchema {
     description: string
     user?: IUserSchema
@@ -12,6 +20,10 @@ export interface IApiTokenSchema extends IResourceSchema {
     expired_at?: string
     last_used_at?: string
     is_expired: boolean
+    token_prefix?: string
+    previous_token_expired_at?: string
+    resource_selectors?: IApiTokenResourceSelector[]
+    allowed_cidrs?: string[]
 }
 
 export interface IApiTokenFullSchema extends IApiTokenSchema {
@@ -21,12 +33,28 @@ export interface IApiTokenFullSchema extends IApiTokenSchema {
 export interface IUpdateApiTokenSchema {
     description?: string
     scopes?: ApiTokenScope[]
+    resource_selectors?: IApiTokenResourceSelector[]
+    allowed_cidrs?: string[]
     expired_at?: string
 }
 
+export interface IRotateApiTokenSchema {
+    overlap_seconds?: number
+}
+
 export interface ICreateApiTokenSchema {
     name: string
     description: string
     scopes: ApiTokenScope[]
+    resource_selectors?: IApiTokenResourceSelector[]
+    allowed_cidrs?: string[]
     expired_at?: string
 }
+
+export interface IApiTokenUsageSchema extends IBaseSchema {
+    minute: string
+    client_ip: string
+    user_agent: string
+    operation_id: string
+    count: number
+}
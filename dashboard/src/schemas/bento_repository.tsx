@@ -3,9 +3,11 @@ import { IBentoSchema } from './bento'
 import { IDeploymentSchema } from './deployment'
 import { IOrganizationSchema } from './organization'
 import { IResourceSchema } from './resource'
+import { RepositoryVisibility } from './repository_member'
 import { IUserSchema } from './user'
 
 export interface IBentoRepositorySchema extends IResourceSchema {
+    visibility?: RepositoryVisibility
     latest_bento?: IBentoSchema
     creator?: IUserSchema
     organization?: IOrganizationSchema
@@ -22,8 +24,10 @@ export interface IBentoRepositoryWithLatestDeploymentsSchema extends IBentoRepos
 export interface ICreateBentoRepositorySchema {
     name: string
     description: string
+    visibility?: RepositoryVisibility
 }
 
 export interface IUpdateBentoRepositorySchema {
     description?: string
+    visibility?: RepositoryVisibility
 }
@@ -1,4 +1,5 @@
 export interface IInfoSchema {
     is_saas: boolean
     saas_domain_suffix: string
+    is_oidc_enabled: boolean
 }
@@ -2,9 +2,11 @@
 import { IModelSchema } from './model'
 import { IOrganizationSchema } from './organization'
 import { IResourceSchema } from './resource'
+import { RepositoryVisibility } from './repository_member'
 import { IUserSchema } from './user'
 
 export interface IModelRepositorySchema extends IResourceSchema {
+    visibility?: RepositoryVisibility
     latest_model?: IModelSchema
     creator?: IUserSchema
     organization?: IOrganizationSchema
@@ -14,8 +16,10 @@ export interface IModelRepositorySchema extends IResourceSchema {
 export interface ICreateModelRepositorySchema {
     name: string
     description?: string
+    visibility?: RepositoryVisibility
 }
 
 export interface IUpdateModelRepositorySchema {
     description?: string
+    visibility?: RepositoryVisibility
 }
@@ -1,3 +1,4 @@
+import { MemberRole } from './member_role'
 import { IResourceSchema } from './resource'
 import { IUserSchema } from './user'
 
@@ -57,11 +58,13 @@ export interface IOrganizationSchema extends IResourceSchema {
 
 export interface IOrganizationFullSchema extends IOrganizationSchema {
     config?: IOrganizationConfigSchema
+    two_factor_required_role?: MemberRole | ''
 }
 
 export interface IUpdateOrganizationSchema {
     description?: string
     config?: IOrganizationConfigSchema
+    two_factor_required_role?: MemberRole | ''
 }
 
 export interface ICreateOrganizationSchema {
@@ -0,0 +1,11 @@
+import { IBaseSchema } from './base'
+import { MemberRole } from './member_role'
+import { IUserSchema } from './user'
+
+export type RepositoryVisibility = 'internal' | 'private'
+
+export interface IRepositoryMemberSchema extends IBaseSchema {
+    user: IUserSchema
+    role: MemberRole
+    creator?: IUserSchema
+}
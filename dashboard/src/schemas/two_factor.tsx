@@ -0,0 +1,18 @@
+export interface ITwoFactorSchema {
+    enabled: boolean
+    required: boolean
+    recovery_codes_remaining: number
+}
+
+export interface ITwoFactorEnrollmentSchema {
+    secret: string
+    provisioning_uri: string
+}
+
+export interface ITwoFactorRecoveryCodesSchema {
+    recovery_codes: string[]
+}
+
+export interface ITwoFactorCodeSchema {
+    code: string
+}
@@ -23,6 +23,10 @@ export interface ILoginUserSchema {
     password: string
 }
 
+export interface ILoginResultSchema extends Partial<IUserSchema> {
+    two_factor_required: boolean
+}
+
 export interface IUpdateUserSchema {
     first_name: string
     last_name: string
@@ -0,0 +1,45 @@
+import { IBaseSchema } from './base'
+import { MemberRole } from './member_role'
+import { IOrganizationSchema } from './organization'
+import { ResourceType } from './resource'
+import { IUserSchema } from './user'
+
+export interface IUserGroupSchema extends IBaseSchema {
+    name: string
+    description: string
+    creator?: IUserSchema
+    organization?: IOrganizationSchema
+}
+
+export interface ICreateUserGroupSchema {
+    name: string
+    description: string
+}
+
+export interface IUpdateUserGroupSchema {
+    name?: string
+    description?: string
+}
+
+export interface IAddUserGroupUsersSchema {
+    usernames: string[]
+}
+
+export type UserGroupGrantResourceType = Extract<
+    ResourceType,
+    'organization' | 'cluster' | 'bento_repository' | 'model_repository'
+>
+
+export interface IUserGroupGrantSchema extends IBaseSchema {
+    creator?: IUserSchema
+    resource_type: UserGroupGrantResourceType
+    resource_uid: string
+    resource_name: string
+    role: MemberRole
+}
+
+export interface ICreateUserGroupGrantSchema {
+    resource_type: UserGroupGrantResourceType
+    resource_name: string
+    role: MemberRole
+}
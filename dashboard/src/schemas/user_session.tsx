@@ -0,0 +1,8 @@
+import { IBaseSchema } from './base'
+
+export interface IUserSessionSchema extends IBaseSchema {
+    client_ip: string
+    user_agent: string
+    last_seen_at: string
+    is_current: boolean
+}
@@ -1,5 +1,12 @@
 import axios from 'axios'
-import { ICreateApiTokenSchema, IApiTokenSchema, IUpdateApiTokenSchema, IApiTokenFullSchema } from '@/schemas/api_token'
+import {
+    ICreateApiTokenSchema,
+    IApiTokenSchema,
+    IUpdateApiTokenSchema,
+    IApiTokenFullSchema,
+    IRotateApiTokenSchema,
+    IApiTokenUsageSchema,
+} from '@/schemas/api_token'
 import { IListQuerySchema, IListSchema } from '@/schemas/list'
 
 export async function listApiTokens(query: IListQuerySchema): Promise<IListSchema<IApiTokenSchema>> {
@@ -26,3 +33,18 @@ export async function deleteApiToken(apiTokenUid: string): Promise<IApiTokenSche
     const resp = await axios.delete<IApiTokenSchema>(`/api/v1/api_tokens/${apiTokenUid}`)
     return resp.data
 }
+
+export async function rotateApiToken(apiTokenUid: string, data: IRotateApiTokenSchema): Promise<IApiTokenFullSchema> {
+    const resp = await axios.post<IApiTokenFullSchema>(`/api/v1/api_tokens/${apiTokenUid}/rotate`, data)
+    return resp.data
+}
+
+export async function listApiTokenUsages(
+    apiTokenUid: string,
+    query: IListQuerySchema
+): Promise<IListSchema<IApiTokenUsageSchema>> {
+    const resp = await axios.get<IListSchema<IApiTokenUsageSchema>>(`/api/v1/api_tokens/${apiTokenUid}/usages`, {
+        params: query,
+    })
+    return resp.data
+}
@@ -0,0 +1,33 @@
+import { ICreateMembersSchema, IDeleteMemberSchema } from '@/schemas/member'
+import { IRepositoryMemberSchema } from '@/schemas/repository_member'
+import axios from 'axios'
+
+export type RepositoryKind = 'bento_repositories' | 'model_repositories'
+
+export async function listRepositoryMembers(
+    kind: RepositoryKind,
+    repositoryName: string
+): Promise<IRepositoryMemberSchema[]> {
+    const resp = await axios.get<IRepositoryMemberSchema[]>(`/api/v1/${kind}/${repositoryName}/members`)
+    return resp.data
+}
+
+export async function createRepositoryMembers(
+    kind: RepositoryKind,
+    repositoryName: string,
+    data: ICreateMembersSchema
+): Promise<IRepositoryMemberSchema[]> {
+    const resp = await axios.post<IRepositoryMemberSchema[]>(`/api/v1/${kind}/${repositoryName}/members`, data)
+    return resp.data
+}
+
+export async function deleteRepositoryMember(
+    kind: RepositoryKind,
+    repositoryName: string,
+    data: IDeleteMemberSchema
+): Promise<IRepositoryMemberSchema> {
+    const resp = await axios.delete<IRepositoryMemberSchema>(`/api/v1/${kind}/${repositoryName}/members`, {
+        data,
+    })
+    return resp.data
+}
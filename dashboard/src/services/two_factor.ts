@@ -0,0 +1,38 @@
+import axios from 'axios'
+import { IUserSchema } from '@/schemas/user'
+import {
+    ITwoFactorCodeSchema,
+    ITwoFactorEnrollmentSchema,
+    ITwoFactorRecoveryCodesSchema,
+    ITwoFactorSchema,
+} from '@/schemas/two_factor'
+
+export async function loginUserTwoFactor(data: ITwoFactorCodeSchema): Promise<IUserSchema> {
+    const resp = await axios.post<IUserSchema>('/api/v1/auth/login/two_factor', data)
+    return resp.data
+}
+
+export async function fetchCurrentUserTwoFactor(): Promise<ITwoFactorSchema> {
+    const resp = await axios.get<ITwoFactorSchema>('/api/v1/auth/two_factor')
+    return resp.data
+}
+
+export async function enrollTwoFactor(): Promise<ITwoFactorEnrollmentSchema> {
+    const resp = await axios.post<ITwoFactorEnrollmentSchema>('/api/v1/auth/two_factor/enroll')
+    return resp.data
+}
+
+export async function enableTwoFactor(data: ITwoFactorCodeSchema): Promise<ITwoFactorRecoveryCodesSchema> {
+    const resp = await axios.post<ITwoFactorRecoveryCodesSchema>('/api/v1/auth/two_factor/enable', data)
+    return resp.data
+}
+
+export async function disableTwoFactor(data: ITwoFactorCodeSchema): Promise<ITwoFactorSchema> {
+    const resp = await axios.post<ITwoFactorSchema>('/api/v1/auth/two_factor/disable', data)
+    return resp.data
+}
+
+export async function regenerateTwoFactorRecoveryCodes(data: ITwoFactorCodeSchema): Promise<ITwoFactorRecoveryCodesSchema> {
+    const resp = await axios.post<ITwoFactorRecoveryCodesSchema>('/api/v1/auth/two_factor/recovery_codes', data)
+    return resp.data
+}
@@ -3,6 +3,7 @@ import {
     IUserSchema,
     IRegisterUserSchema,
     ILoginUserSchema,
+    ILoginResultSchema,
     ICreateUserSchema,
     IChangePasswordSchema,
 } from '@/schemas/user'
@@ -30,8 +31,8 @@ export async function registerUser(data: IRegisterUserSchema): Promise<IUserSche
     return resp.data
 }
 
-export async function loginUser(data: ILoginUserSchema): Promise<IUserSchema> {
-    const resp = await axios.post<IUserSchema>('/api/v1/auth/login', data)
+export async function loginUser(data: ILoginUserSchema): Promise<ILoginResultSchema> {
+    const resp = await axios.post<ILoginResultSchema>('/api/v1/auth/login', data)
     return resp.data
 }
 
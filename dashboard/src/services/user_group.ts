@@ -0,0 +1,70 @@
+import axios from 'axios'
+import { IDeleteMemberSchema } from '@/schemas/member'
+import { IListQuerySchema, IListSchema } from '@/schemas/list'
+import { IUserSchema } from '@/schemas/user'
+import {
+    IAddUserGroupUsersSchema,
+    ICreateUserGroupGrantSchema,
+    ICreateUserGroupSchema,
+    IUpdateUserGroupSchema,
+    IUserGroupGrantSchema,
+    IUserGroupSchema,
+} from '@/schemas/user_group'
+
+export async function listUserGroups(query: IListQuerySchema): Promise<IListSchema<IUserGroupSchema>> {
+    const resp = await axios.get<IListSchema<IUserGroupSchema>>('/api/v1/user_groups', { params: query })
+    return resp.data
+}
+
+export async function fetchUserGroup(userGroupName: string): Promise<IUserGroupSchema> {
+    const resp = await axios.get<IUserGroupSchema>(`/api/v1/user_groups/${userGroupName}`)
+    return resp.data
+}
+
+export async function createUserGroup(data: ICreateUserGroupSchema): Promise<IUserGroupSchema> {
+    const resp = await axios.post<IUserGroupSchema>('/api/v1/user_groups', data)
+    return resp.data
+}
+
+export async function updateUserGroup(userGroupName: string, data: IUpdateUserGroupSchema): Promise<IUserGroupSchema> {
+    const resp = await axios.patch<IUserGroupSchema>(`/api/v1/user_groups/${userGroupName}`, data)
+    return resp.data
+}
+
+export async function deleteUserGroup(userGroupName: string): Promise<IUserGroupSchema> {
+    const resp = await axios.delete<IUserGroupSchema>(`/api/v1/user_groups/${userGroupName}`)
+    return resp.data
+}
+
+export async function listUserGroupUsers(userGroupName: string): Promise<IUserSchema[]> {
+    const resp = await axios.get<IUserSchema[]>(`/api/v1/user_groups/${userGroupName}/users`)
+    return resp.data
+}
+
+export async function addUserGroupUsers(userGroupName: string, data: IAddUserGroupUsersSchema): Promise<IUserSchema[]> {
+    const resp = await axios.post<IUserSchema[]>(`/api/v1/user_groups/${userGroupName}/users`, data)
+    return resp.data
+}
+
+export async function removeUserGroupUser(userGroupName: string, data: IDeleteMemberSchema): Promise<IUserSchema> {
+    const resp = await axios.delete<IUserSchema>(`/api/v1/user_groups/${userGroupName}/users`, { data })
+    return resp.data
+}
+
+export async function listUserGroupGrants(userGroupName: string): Promise<IUserGroupGrantSchema[]> {
+    const resp = await axios.get<IUserGroupGrantSchema[]>(`/api/v1/user_groups/${userGroupName}/grants`)
+    return resp.data
+}
+
+export async function createUserGroupGrant(
+    userGroupName: string,
+    data: ICreateUserGroupGrantSchema
+): Promise<IUserGroupGrantSchema> {
+    const resp = await axios.post<IUserGroupGrantSchema>(`/api/v1/user_groups/${userGroupName}/grants`, data)
+    return resp.data
+}
+
+export async function deleteUserGroupGrant(userGroupName: string, grantUid: string): Promise<IUserGroupGrantSchema> {
+    const resp = await axios.delete<IUserGroupGrantSchema>(`/api/v1/user_groups/${userGroupName}/grants/${grantUid}`)
+    return resp.data
+}
@@ -0,0 +1,23 @@
+import axios from 'axios'
+import { IUserSchema } from '@/schemas/user'
+import { IUserSessionSchema } from '@/schemas/user_session'
+
+export async function listCurrentUserSessions(): Promise<IUserSessionSchema[]> {
+    const resp = await axios.get<IUserSessionSchema[]>('/api/v1/auth/sessions')
+    return resp.data
+}
+
+export async function revokeCurrentUserSession(sessionUid: string): Promise<IUserSessionSchema> {
+    const resp = await axios.delete<IUserSessionSchema>(`/api/v1/auth/sessions/${sessionUid}`)
+    return resp.data
+}
+
+export async function listUserSessions(userName: string): Promise<IUserSessionSchema[]> {
+    const resp = await axios.get<IUserSessionSchema[]>(`/api/v1/users/${userName}/sessions`)
+    return resp.data
+}
+
+export async function revokeUserSessions(userName: string): Promise<IUserSchema> {
+    const resp = await axios.delete<IUserSchema>(`/api/v1/users/${userName}/sessions`)
+    return resp.data
+}